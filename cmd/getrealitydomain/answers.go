@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// wizardAnswers 上次扫描问答中的回答，下次运行时作为默认值，每天重复扫描同一网段时不必重新输入
+type wizardAnswers struct {
+	IP         string `json:"ip,omitempty"`          // 输入的IP或IP区间（可带端口），为空表示使用本机IP
+	Mask       string `json:"mask,omitempty"`        // 子网掩码位数，如 /24
+	MaxResults *int   `json:"max_results,omitempty"` // 最大结果数，0表示无限制，nil表示没有记录
+	Thread     int    `json:"thread,omitempty"`      // 并发线程数
+	PingDomain bool   `json:"ping_domain"`           // 是否启用ping域名测试连通性
+}
+
+// answersPath 返回问答记录文件的路径
+func answersPath() string {
+	return userConfigPath("answers.json")
+}
+
+// loadAnswers 读取上次的回答，没有记录或读取失败时返回空记录
+func loadAnswers() *wizardAnswers {
+	answers := &wizardAnswers{}
+	data, err := os.ReadFile(answersPath())
+	if err != nil {
+		return answers
+	}
+	if err := json.Unmarshal(data, answers); err != nil {
+		logger.Warn("解析上次的回答失败，使用默认值", "error", err)
+		return &wizardAnswers{}
+	}
+	return answers
+}
+
+// save 保存本次的回答，失败时只记录日志，不影响扫描
+func (a *wizardAnswers) save() {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(answersPath()), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(answersPath(), data, 0644)
+	}
+	if err != nil {
+		logger.Debug("保存本次的回答失败", "error", err)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/mockserver"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 基准测试的参数
+const (
+	benchServers  = 8               // 回环地址上的测试服务器数
+	benchDuration = 3 * time.Second // 每种线程数的测试时长
+	benchLatency  = 200             // 估算实际扫描时假设的典型响应时间（毫秒）
+)
+
+// benchThreads 未指定时测试的线程数
+var benchThreads = []int{1, 8, 32, 128, 512}
+
+// benchStats 一种线程数的测试结果
+type benchStats struct {
+	threads   int
+	completed int
+	failed    int
+	elapsed   time.Duration
+	handshake scanner.StageSnapshot
+}
+
+// rate 返回每秒完成的握手数
+func (b benchStats) rate() float64 {
+	return float64(b.completed) / b.elapsed.Seconds()
+}
+
+// runBench 在本机回环地址上的TLS测试服务器上以不同线程数运行扫描，测量本机每秒能完成的握手数，
+// 帮助在大规模扫描前选择合适的线程数；spec为逗号分隔的线程数，为空时使用默认列表
+func runBench(spec string) error {
+	threads := benchThreads
+	if spec != "" {
+		threads = nil
+		for _, field := range strings.Split(spec, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n < 1 {
+				return fmt.Errorf("无效的线程数: %s（用法: bench [线程数,...]）", field)
+			}
+			threads = append(threads, n)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	servers, err := mockserver.StartFarm(benchServers)
+	if err != nil {
+		return err
+	}
+	defer mockserver.CloseAll(servers)
+
+	fmt.Println()
+	fmt.Printf("⚙️  基准测试：本机回环地址上的 %d 个TLS 1.3测试服务器，每种线程数测试 %s\n\n", len(servers), benchDuration)
+	fmt.Printf("%s %s %s %s %s\n",
+		console.PadRight("线程数", 8), console.PadRight("握手/秒", 10), console.PadRight("平均握手", 10),
+		console.PadRight("最大握手", 10), "失败")
+	fmt.Println(strings.Repeat("-", 50))
+
+	var results []benchStats
+	for _, n := range threads {
+		stats := benchRound(ctx, servers, n)
+		if ctx.Err() != nil {
+			logger.Warn("基准测试已取消")
+			return nil
+		}
+		results = append(results, stats)
+		fmt.Printf("%s %s %s %s %d\n",
+			console.PadRight(strconv.Itoa(n), 8),
+			console.PadRight(fmt.Sprintf("%.0f", stats.rate()), 10),
+			console.PadRight(formatDuration(stats.handshake.Avg), 10),
+			console.PadRight(formatDuration(stats.handshake.Max), 10),
+			stats.failed)
+	}
+
+	// 达到峰值90%的最少线程数视为本机CPU的饱和点
+	peak := slices.MaxFunc(results, func(a, b benchStats) int {
+		return cmp.Compare(a.rate(), b.rate())
+	})
+	saturated := peak
+	for _, stats := range results {
+		if stats.rate() >= peak.rate()*0.9 {
+			saturated = stats
+			break
+		}
+	}
+	fmt.Println()
+	fmt.Printf("✅ 本机最多约 %.0f 次握手/秒（%d 线程），%d 线程时已达到峰值的90%%\n",
+		peak.rate(), peak.threads, saturated.threads)
+	fmt.Printf("💡 实际扫描受网络延迟限制，每个线程每秒约完成 1000/响应时间(ms) 个目标；按 %dms 的典型响应时间估算，\n"+
+		"   线程数超过约 %d 后本机CPU将成为瓶颈，再增加线程只会增加超时和失败\n",
+		benchLatency, int(peak.rate()*benchLatency/1000))
+	fmt.Println()
+	return nil
+}
+
+// benchRound 以指定线程数持续扫描测试服务器benchDuration，返回完成的握手数和握手耗时
+func benchRound(ctx context.Context, servers []*mockserver.Server, threads int) benchStats {
+	cfg := scanner.DefaultConfig()
+	cfg.Thread = threads
+	s := scanner.New(cfg, nil)
+
+	roundCtx, cancel := context.WithTimeout(ctx, benchDuration)
+	defer cancel()
+	hosts := make(chan scanner.Host)
+	go func() {
+		defer close(hosts)
+		for i := 0; ; i++ {
+			addr := servers[i%len(servers)].Addr
+			host := scanner.Host{IP: addr.Addr(), Origin: addr.Addr().String(), Type: scanner.HostTypeIP, Port: int(addr.Port())}
+			select {
+			case hosts <- host:
+			case <-roundCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// 进行中的握手在测试时间结束后仍然完成，计入耗时
+	stats := benchStats{threads: threads}
+	start := time.Now()
+	for result := range s.ScanWithConcurrency(ctx, hosts) {
+		if result.Error != "" {
+			stats.failed++
+		} else {
+			stats.completed++
+		}
+	}
+	stats.elapsed = time.Since(start)
+	for _, stage := range s.Metrics().Snapshot().Stages {
+		if stage.Stage == scanner.StageHandshake {
+			stats.handshake = stage
+		}
+	}
+	return stats
+}
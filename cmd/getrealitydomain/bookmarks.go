@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// bookmarkSuffix 收藏文件相对于输出文件的后缀
+const bookmarkSuffix = ".favorites.json"
+
+// markStore 结果查看器中标记的目标（收藏、排除），按键记录标记时间并保存为JSON文件
+type markStore struct {
+	path  string
+	stars map[string]time.Time // 标记键 -> 标记时间
+}
+
+// userConfigPath 返回用户配置目录中的文件路径，无法确定配置目录时使用当前目录
+func userConfigPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, "getrealitydomain", name)
+}
+
+// rejectedPath 返回排除目标文件的路径，排除对所有结果文件和以后的扫描都有效，因此保存在用户配置目录
+func rejectedPath() string {
+	return userConfigPath("rejected.json")
+}
+
+// rejectKey 返回记录的排除键，按IP排除，以后扫描到同一IP时同样不显示
+func rejectKey(header, record []string) string {
+	return columnValue(header, record, "IP")
+}
+
+// bookmarkKey 返回记录的收藏键，按IP和证书指纹记录，重新扫描后同一目标（证书未变）仍保持收藏，
+// 旧版本结果文件没有证书指纹时退化为IP和端口
+func bookmarkKey(header, record []string) string {
+	ip := columnValue(header, record, "IP")
+	if fingerprint := columnValue(header, record, "CERT_SHA256"); fingerprint != "" {
+		return ip + "|" + fingerprint
+	}
+	return ip + "|" + columnValue(header, record, "PORT")
+}
+
+// loadMarks 读取标记文件，文件不存在时返回空记录，读取失败时返回空记录和错误
+func loadMarks(path string) (*markStore, error) {
+	store := &markStore{path: path, stars: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("读取标记文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.stars); err != nil {
+		return store, fmt.Errorf("解析标记文件失败: %v", err)
+	}
+	return store, nil
+}
+
+// starred 返回目标是否已标记
+func (b *markStore) starred(key string) bool {
+	_, ok := b.stars[key]
+	return ok
+}
+
+// toggle 切换目标的标记状态，返回切换后是否已标记
+func (b *markStore) toggle(key string) bool {
+	if b.starred(key) {
+		delete(b.stars, key)
+		return false
+	}
+	b.stars[key] = time.Now()
+	return true
+}
+
+// save 写入标记文件，先写临时文件再重命名，避免中断时损坏已有标记
+func (b *markStore) save() error {
+	data, err := json.MarshalIndent(b.stars, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("保存标记文件失败: %v", err)
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存标记文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("保存标记文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+)
+
+// command 子命令：全局参数写在子命令之前，子命令的位置参数（和少数专用参数）写在之后
+type command struct {
+	name  string
+	usage string // 子命令之后的参数
+	brief string // 一行说明
+	run   func(config *Config, args []string) error
+}
+
+// commands 全部子命令；不指定子命令时交互使用进入主菜单，指定了扫描目标或无交互模式时与 scan 相同
+var commands = []command{
+	{"scan", "[目标]", "扫描目标，目标也可由 --target、--targets 等参数指定", runScanCommand},
+	{"verify", "[--write] <结果文件>", "重新测试结果文件中的全部目标，--write 时用本次结果更新结果文件", runVerifyCommand},
+	{"export", "<结果文件> <reality|clash> [输出文件]", "将结果文件中符合条件的目标导出为配置，输出文件为 - 时写入标准输出", runExportCommand},
+	{"serve", "[监听地址]", "以HTTP API提供单目标检测、验证、结果查询和配置导出，默认监听 " + defaultServeAddr, runServeCommand},
+	{"view", "[结果文件]", "查看已有的结果文件，省略时从最近扫描的结果文件中选择", func(config *Config, args []string) error {
+		runViewer(config, argAt(args, 0))
+		return nil
+	}},
+	{"watch", "<结果文件>", "守护模式，定期重新验证并移除失效的目标", func(config *Config, args []string) error {
+		return runWatch(config, argAt(args, 0))
+	}},
+	{"debug", "<IP[:端口]> [SNI]", "对单个目标握手并输出全部参数和每条规则的判断原因", func(config *Config, args []string) error {
+		return runDebug(config, argAt(args, 0), argAt(args, 1))
+	}},
+	{"bench", "[线程数,...]", "在本机的TLS测试服务器上测量不同线程数的握手吞吐量", func(config *Config, args []string) error {
+		return runBench(argAt(args, 0))
+	}},
+	{"history", "<IP>", "查看目标在历次扫描中的表现", func(config *Config, args []string) error {
+		return runHistory(config, argAt(args, 0))
+	}},
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printCommands 列出子命令的用法和说明
+func printCommands(out io.Writer) {
+	fmt.Fprintf(out, "子命令:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %s %s\n    \t%s\n", cmd.name, cmd.usage, cmd.brief)
+	}
+}
+
+// argAt 返回第i个位置参数，不存在时返回空字符串
+func argAt(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+// runScanCommand scan [目标]：不进入主菜单，直接扫描；交互使用时询问未通过参数指定的设置
+func runScanCommand(config *Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("用法: scan [目标]")
+	}
+	if len(args) == 1 {
+		config.Target = args[0]
+	}
+
+	if config.Headless {
+		// 无交互模式下缺少必需的参数时报错退出，不等待输入
+		if err := config.checkHeadless(); err != nil {
+			return err
+		}
+	} else {
+		// 上次输入的线程数作为默认值，可在设置中修改
+		if answers := loadAnswers(); answers.Thread > 0 && !flagPassed("threads") {
+			config.Thread = answers.Thread
+		}
+		if !config.NoBanner {
+			showTitle()
+		}
+	}
+	if err := runNewScan(config); err != nil {
+		return fmt.Errorf("扫描失败: %v", err)
+	}
+	return nil
+}
+
+// runVerifyCommand verify [--write] <结果文件>：重新测试全部目标并显示结果，不询问是否写回
+func runVerifyCommand(config *Config, args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	write := flags.Bool("write", false, "用本次结果更新结果文件")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("用法: verify [--write] <结果文件>")
+	}
+	filename := flags.Arg(0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	geoDB := loadGeoDB(ctx, config)
+	if geoDB != nil {
+		defer geoDB.Close()
+	}
+	results, err := retestFile(ctx, newScanner(config, geoDB), filename)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+	printVerifyResults(results)
+	if *write {
+		return saveVerifyResults(filename, results)
+	}
+	return nil
+}
+
+// runExportCommand export <结果文件> <reality|clash> [输出文件]：导出配置，省略输出文件时使用默认文件名
+func runExportCommand(config *Config, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return fmt.Errorf("用法: export <结果文件> <reality|clash> [输出文件]")
+	}
+	format, ok := findExportFormat(args[1])
+	if !ok {
+		names := make([]string, len(exportFormats))
+		for i, format := range exportFormats {
+			names[i] = format.name
+		}
+		return fmt.Errorf("不支持的导出格式: %s（可选 %s）", args[1], strings.Join(names, "、"))
+	}
+	path := format.file
+	if len(args) == 3 {
+		path = args[2]
+	}
+	return exportConfig(args[0], format, path)
+}
+
+// exportConfig 将结果文件中符合条件的目标按format导出到path，path为 - 时写入标准输出
+func exportConfig(filename string, format exportFormat, path string) error {
+	header, records, err := output.LoadFeasibleTable(filename)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("没有找到符合条件的目标")
+	}
+
+	if path == "-" {
+		format.write(os.Stdout, header, records)
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建配置文件失败: %v", err)
+	}
+	format.write(file, header, records)
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	logging.Success(logger, format.label+"已导出", "path", path, "targets", len(records))
+	return nil
+}
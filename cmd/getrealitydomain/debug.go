@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/feasibility"
+)
+
+// runDebug 对单个目标握手一次，输出提取的全部参数、证书链、各阶段耗时，
+// 以及每条可行性规则和后置检查通过或未通过的原因，用于诊断目标被判断为某种结果的原因
+func runDebug(config *Config, target, sni string) error {
+	ip, port, err := parseDebugTarget(target, config.Ports)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	geoDB := loadGeoDB(ctx, config)
+	if geoDB != nil {
+		defer geoDB.Close()
+	}
+	s := newScanner(config, geoDB)
+
+	logger.Info("正在握手...", "ip", ip, "port", port, "sni", sni)
+	detail := s.Inspect(ctx, ip, port, sni)
+	result := detail.Result
+
+	fmt.Println()
+	fmt.Println("🔌 连接")
+	debugField("目标", netip.AddrPortFrom(ip, uint16(port)).String())
+	debugField("SNI", result.SNI)
+	debugField("TCP连接", formatDuration(detail.DialTime))
+	debugField("TLS握手", formatDuration(detail.HandshakeTime))
+	debugField("响应时间", fmt.Sprintf("%dms", result.ResponseTime))
+
+	fmt.Println()
+	fmt.Println("🌍 地理位置")
+	debugField("国家/地区", strings.TrimSpace(result.GeoCode+" "+result.City))
+	if result.ASN != 0 {
+		debugField("ASN", fmt.Sprintf("AS%d %s", result.ASN, result.ASOrg))
+	}
+
+	if result.Error != "" && result.TLSVersion == "" {
+		fmt.Println()
+		fmt.Printf("❌ 握手失败 [%s]: %s\n\n", result.ErrorKind, result.Error)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("🔐 TLS")
+	debugField("TLS版本", result.TLSVersion)
+	debugField("ALPN", result.ALPN)
+	debugField("椭圆曲线", result.Curve)
+	debugField("密码套件", detail.CipherSuite)
+	if config.Hello != nil {
+		debugField("ClientHello探测", config.Hello.Name+": "+s.ProbeHello(ctx, ip, port, result.SNI))
+	}
+
+	fmt.Println()
+	fmt.Printf("📜 证书链（%d 个证书）\n", len(detail.Chain))
+	for i, cert := range detail.Chain {
+		fmt.Printf("  [%d] %s\n", i, cert.Subject)
+		debugField("  颁发者", cert.Issuer)
+		if len(cert.DNSNames) > 0 {
+			debugField("  域名", strings.Join(cert.DNSNames, ", "))
+		}
+		validity := fmt.Sprintf("%s ~ %s", cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))
+		if time.Now().After(cert.NotAfter) {
+			validity += " (已过期)"
+		}
+		debugField("  有效期", validity)
+		debugField("  算法", cert.PublicKey+" / "+cert.Signature)
+		debugField("  SHA-256", cert.SHA256)
+	}
+	if result.Error != "" {
+		fmt.Println()
+		fmt.Printf("❌ [%s]: %s\n\n", result.ErrorKind, result.Error)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("📋 可行性规则")
+	feasible := true
+	for _, outcome := range newChecker(config).Explain(ctx, result) {
+		mark := "✅"
+		if !outcome.Passed {
+			mark = "❌"
+			feasible = false
+		}
+		fmt.Printf("  %s %s %s\n", mark, console.PadRight(outcome.Rule, 12), outcome.Detail)
+	}
+
+	fmt.Println()
+	fmt.Println("🔎 后置检查")
+	if config.HoneypotCheck {
+		detector := &feasibility.HoneypotDetector{Dialer: config.Egress}
+		if suspects := detector.Suspects(ctx, result); len(suspects) > 0 {
+			fmt.Printf("  ⚠️ %s %s\n", console.PadRight("疑似蜜罐", 12), strings.Join(suspects, ", "))
+		} else {
+			fmt.Printf("  ✅ %s 未发现疑点\n", console.PadRight("疑似蜜罐", 12))
+		}
+	}
+	if config.Confidence {
+		grader := &feasibility.ConfidenceGrader{Dialer: config.Egress, HTTPClient: config.HTTPClient, Cache: config.PostChecks}
+		failed := grader.FailedChecks(ctx, result)
+		for _, check := range []string{feasibility.CheckHandshake, feasibility.CheckH2, feasibility.CheckConnectivity, feasibility.CheckCDN} {
+			mark := "✅"
+			if slices.Contains(failed, check) {
+				mark = "⚠️"
+			}
+			fmt.Printf("  %s %s %s\n", mark, console.PadRight("可信度检查", 12), check)
+		}
+		grade := feasibility.GradeC
+		switch len(failed) {
+		case 0:
+			grade = feasibility.GradeA
+		case 1:
+			grade = feasibility.GradeB
+		}
+		debugField("  可信度等级", grade)
+	}
+
+	fmt.Println()
+	if feasible {
+		fmt.Println("✅ 结论: 符合Reality要求")
+	} else {
+		fmt.Println("❌ 结论: 不符合Reality要求")
+	}
+	fmt.Println()
+	return nil
+}
+
+// parseDebugTarget 解析 IP 或 IP:端口（IPv6需要加方括号），未指定端口时使用首个扫描端口
+func parseDebugTarget(target string, ports []int) (netip.Addr, int, error) {
+	if target == "" {
+		return netip.Addr{}, 0, fmt.Errorf("用法: debug <IP[:端口]> [SNI]")
+	}
+	if addrPort, err := netip.ParseAddrPort(target); err == nil {
+		return addrPort.Addr().Unmap(), int(addrPort.Port()), nil
+	}
+	ip, err := netip.ParseAddr(strings.Trim(target, "[]"))
+	if err != nil {
+		return netip.Addr{}, 0, fmt.Errorf("无效的目标: %s（用法: debug <IP[:端口]> [SNI]）", target)
+	}
+	port := 443
+	if len(ports) > 0 {
+		port = ports[0]
+	}
+	return ip.Unmap(), port, nil
+}
+
+// debugField 输出一行带名称的字段，空值显示为 -
+func debugField(label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	fmt.Printf("  %s %s\n", console.PadRight(label, 14), value)
+}
+
+// formatDuration 以毫秒显示耗时，保留一位小数
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+}
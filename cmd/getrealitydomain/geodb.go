@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+)
+
+// 自动下载和 -update-geodb 使用的数据库路径（程序目录）
+const (
+	countryDBPath = geo.CountryDBFile
+	asnDBPath     = geo.ASNDBFile
+)
+
+// loadGeoDB 加载地理位置和ASN数据库，找不到时自动下载，全部失败时返回nil
+func loadGeoDB(ctx context.Context, config *Config) *geo.Geo {
+	// City数据库包含国家信息，优先使用
+	geoPaths := []string{
+		"GeoLite2-City.mmdb",
+		"/usr/share/GeoIP/GeoLite2-City.mmdb",
+		"/var/lib/GeoIP/GeoLite2-City.mmdb",
+		"Country.mmdb",
+		countryDBPath,
+		"/usr/share/GeoIP/GeoLite2-Country.mmdb",
+		"/var/lib/GeoIP/GeoLite2-Country.mmdb",
+		config.Output + ".geo.mmdb",
+	}
+
+	var geoDB *geo.Geo
+	var geoErr error
+	for _, path := range geoPaths {
+		if geoDB, geoErr = geo.New(path); geoErr == nil {
+			logger.Info("地理位置数据库加载成功", "path", path, "built", geoDB.BuildTime().Format("2006-01-02"))
+			break
+		}
+	}
+
+	// 如果没有找到地理位置数据库，尝试自动下载
+	if geoDB == nil {
+		logger.Info("未找到地理位置数据库，正在尝试自动下载...")
+
+		// 尝试下载到程序目录
+		if config.GeoDownloader.TryDownload(ctx, geo.CountryDBFile, countryDBPath) {
+			// 下载成功，尝试加载
+			if geoDB, geoErr = geo.New(countryDBPath); geoErr == nil {
+				logger.Info("地理位置数据库下载并加载成功", "path", countryDBPath)
+			} else {
+				logger.Error("下载的数据库文件加载失败", "error", geoErr)
+				logger.Info("将跳过地理位置查询")
+				return nil
+			}
+		} else {
+			logger.Info("自动下载失败，将跳过地理位置查询")
+			logger.Info("提示: 可手动下载 GeoLite2-Country.mmdb 文件到程序目录以启用地理位置功能")
+			return nil
+		}
+	}
+	warnIfStale("地理位置数据库", geoDB.BuildTime(), config.GeoDBMaxAge)
+
+	// 加载ASN数据库，用于填充ASN和所属组织
+	asnPaths := []string{
+		asnDBPath,
+		"/usr/share/GeoIP/GeoLite2-ASN.mmdb",
+		"/var/lib/GeoIP/GeoLite2-ASN.mmdb",
+	}
+	asnLoaded := false
+	for _, path := range asnPaths {
+		if err := geoDB.OpenASN(path); err == nil {
+			logger.Info("ASN数据库加载成功", "path", path, "built", geoDB.ASNBuildTime().Format("2006-01-02"))
+			asnLoaded = true
+			break
+		}
+	}
+
+	// 与国家数据库相同，找不到时自动下载到程序目录
+	if !asnLoaded {
+		logger.Info("未找到ASN数据库，正在尝试自动下载...")
+		if config.GeoDownloader.TryDownload(ctx, geo.ASNDBFile, asnDBPath) {
+			if err := geoDB.OpenASN(asnDBPath); err == nil {
+				logger.Info("ASN数据库下载并加载成功", "path", asnDBPath)
+				asnLoaded = true
+			} else {
+				logger.Error("下载的ASN数据库加载失败", "error", err)
+			}
+		}
+	}
+	if asnLoaded {
+		warnIfStale("ASN数据库", geoDB.ASNBuildTime(), config.GeoDBMaxAge)
+	}
+
+	return geoDB
+}
+
+// warnIfStale 数据库构建时间早于maxAge时提示更新
+func warnIfStale(name string, built time.Time, maxAge time.Duration) {
+	if maxAge <= 0 || built.IsZero() {
+		return
+	}
+	if age := time.Since(built); age > maxAge {
+		logger.Warn(name+"已过期，可使用 -update-geodb 更新",
+			"built", built.Format("2006-01-02"), "age_days", int(age.Hours()/24))
+	}
+}
+
+// updateGeoDB 重新下载国家和ASN数据库，校验通过后原子替换程序目录中的文件
+func updateGeoDB(ctx context.Context, downloader *geo.Downloader) error {
+	if err := downloader.Download(ctx, geo.CountryDBFile, countryDBPath); err != nil {
+		return fmt.Errorf("更新地理位置数据库失败: %v", err)
+	}
+	if err := downloader.Download(ctx, geo.ASNDBFile, asnDBPath); err != nil {
+		return fmt.Errorf("更新ASN数据库失败: %v", err)
+	}
+	return nil
+}
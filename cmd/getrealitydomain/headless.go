@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix 参数对应的环境变量前缀，如 --max-results 对应 GRD_MAX_RESULTS
+const envPrefix = "GRD_"
+
+// envName 返回参数对应的环境变量名
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnvFlags 用环境变量设置命令行中未指定的参数，便于在容器中通过环境变量配置
+func applyEnvFlags() error {
+	var err error
+	flag.VisitAll(func(f *flag.Flag) {
+		if err != nil || flagPassed(f.Name) {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if setErr := flag.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("环境变量 %s 无效: %v", envName(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// flagPassed 返回参数是否已在命令行或环境变量中指定
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+// checkHeadless 检查无交互模式下是否指定了必需的参数，缺少时返回说明如何指定的错误，而不是等待输入
+func (c *Config) checkHeadless() error {
+	if c.Target == "" && !c.hasTargets() {
+		return fmt.Errorf("无交互模式下需要指定扫描目标: --target 1.2.3.0/24（或环境变量 %s），"+
+			"也可使用 --targets、--stdin、--domains、--from-url、--hosts-file、--zone-file", envName("target"))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+)
+
+// runHistory 输出目标在历次扫描中的可用性、延迟和证书变化，便于挑选长期稳定的目标
+func runHistory(config *Config, target string) error {
+	ip, err := netip.ParseAddr(target)
+	if err != nil {
+		return fmt.Errorf("用法: history <IP>")
+	}
+	if config.HistoryDB == "" {
+		return fmt.Errorf("未启用历史数据库（--history-db）")
+	}
+	history, err := output.OpenHistory(config.HistoryDB)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+
+	entries, err := history.Target(ip.String())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		logger.Info("历史数据库中没有该目标的记录", "ip", ip)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%s %s %s %s %s %s\n",
+		console.PadRight("扫描时间", 19), console.PadRight("端口", 6), console.PadRight("结果", 14),
+		console.PadRight("响应时间", 10), console.PadRight("证书域名", 25), "证书指纹")
+	fmt.Println(strings.Repeat("-", 100))
+
+	var previous string
+	for _, entry := range entries {
+		status := "✅ 符合"
+		switch {
+		case entry.ErrorKind != "":
+			status = "❌ " + entry.ErrorKind
+		case !entry.Feasible:
+			status = "⚠️ 不符合"
+		}
+		latency := ""
+		if entry.ErrorKind == "" {
+			latency = strconv.FormatInt(entry.ResponseTime, 10) + "ms"
+		}
+		fingerprint := entry.CertSHA256
+		if len(fingerprint) > 16 {
+			fingerprint = fingerprint[:16]
+		}
+		if previous != "" && entry.CertSHA256 != "" && entry.CertSHA256 != previous {
+			fingerprint += " (已变化)"
+		}
+		if entry.CertSHA256 != "" {
+			previous = entry.CertSHA256
+		}
+		fmt.Printf("%s %s %s %s %s %s\n",
+			console.PadRight(entry.Time.Format("2006-01-02 15:04:05"), 19),
+			console.PadRight(strconv.Itoa(entry.Port), 6),
+			console.PadRight(status, 14),
+			console.PadRight(latency, 10),
+			console.PadRight(console.Truncate(entry.CertDomain, 25), 25),
+			fingerprint,
+		)
+	}
+
+	summary := output.SummarizeHistory(entries)
+	fmt.Println()
+	fmt.Printf("📅 记录时间: %s ~ %s（%d 次扫描）\n",
+		summary.First.Format("2006-01-02"), summary.Last.Format("2006-01-02"), summary.Scans)
+	fmt.Printf("✅ 符合条件: %d/%d 次（%.0f%%），最近连续 %d 次\n",
+		summary.Feasible, summary.Scans, float64(summary.Feasible)*100/float64(summary.Scans), summary.Streak)
+	if summary.Feasible > 0 {
+		fmt.Printf("⏱️  响应时间: 最低 %dms / 平均 %dms / 最高 %dms\n", summary.MinLatency, summary.AvgLatency, summary.MaxLatency)
+	}
+	fmt.Printf("🔐 出现过 %d 个不同的证书\n", summary.Certs)
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,1470 @@
+// getrealitydomain 交互式Reality协议目标域名扫描器
+//
+// 扫描逻辑位于 pkg/scanner、pkg/feasibility、pkg/geo 和 pkg/output，本命令只负责交互和参数解析。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/httpclient"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/internal/mockserver"
+	"github.com/MengMengCode/GetRealityDomain/internal/tui"
+	"github.com/MengMengCode/GetRealityDomain/pkg/feasibility"
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/reverseip"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	"github.com/mattn/go-isatty"
+)
+
+// Config 命令行配置，由parseFlags和交互问答填充后传递给scanAddress
+type Config struct {
+	Ports         []int // 每个目标扫描的端口
+	DiscoverPorts []int // 扫描端口握手失败时尝试的备用端口
+	Thread        int
+	Timeout       int
+	Output        string
+	Verbose       bool
+	ShowVersion   bool // 输出版本信息后退出
+	Headless      bool // 无交互模式，所有问题由参数或环境变量给出，不读取标准输入
+	CheckUpdate   bool // 输出版本时检查是否有新版本
+	TUI           bool // 扫描时使用全屏界面
+	StatusLine    bool // 不使用全屏界面时在终端底部固定状态行
+	NoBanner      bool // 不显示标题、暂停和装饰性边框，只输出数据和日志
+	Color         bool // 按响应时间和首选国家为结果着色
+	PageSize      int  // 结果查看器每页显示的目标数
+	IPv6          bool
+	Target        string // 扫描目标（IP、CIDR或IP区间），设置后不再询问IP和网段
+	TargetsFile   string // 目标列表文件，每行一个IP、CIDR、IP区间或域名
+	Stdin         bool   // 从标准输入读取目标列表，格式与TargetsFile相同，启用时不进行任何交互
+	DomainsFile   string // 候选域名列表文件，设置后扫描列表中的域名而不是IP段
+	FromURL       string // 从该页面抓取域名作为扫描目标
+	HostsFile     string // hosts格式的目标清单
+	ZoneFile      string // BIND区域文件格式的目标清单
+	ZoneOrigin    string // 区域文件的区域名，文件中没有$ORIGIN时使用
+	Sample        int    // 从CIDR或IP区间中随机抽样的地址数，0表示扫描全部地址
+	Resume        bool   // 无限扫描模式是否从上次保存的进度继续
+	Neighborhood  int    // 命中后优先扫描的周边网段前缀长度，0表示不启用
+	SkipDead      int    // 同一/24中连续无响应达到该次数后跳过剩余地址，0表示不跳过
+	ASNBlock      int    // ASN产生该数量的结果仍没有符合条件目标后跳过其剩余地址，0表示不启用
+	ASNDefer      bool   // 低产ASN的地址延后扫描而不是跳过
+
+	Expand scanner.ExpandOptions // 无限扫描模式的步长、最大半径和地址块大小
+
+	CollectorURL     string   // 远程收集端地址
+	CollectorHeaders []string // 推送时附带的请求头
+	CollectorNode    string   // 扫描节点名称
+	CollectorBatch   int      // 每批推送的结果数
+
+	PublishURL string   // Redis/NATS发布地址
+	OnFeasible string   // 发现符合条件的目标时执行的命令模板
+	Progress   string   // 机器可读的进度事件输出（stderr 或 unix:/path），为空时不输出
+	Stdout     string   // 管道模式：结果流式写入标准输出的格式（csv 或 jsonl），为空时不写入
+	StdoutFile *os.File // 管道模式下原来的标准输出，os.Stdout改为标准错误
+	Notify     bool     // 扫描结束时发送桌面通知
+	NotifyCmd  string   // 自定义的通知命令模板，设置后代替系统通知
+	Alert      string   // 发现目标时的提示音（bell 或 sound），为空时不提示
+
+	WatchInterval time.Duration // 守护模式重新验证的间隔
+	PruneAfter    int           // 守护模式中目标连续验证失败多少次后移除
+	ExportReality string        // 守护模式维护的Reality配置文件
+	ExportClash   string        // 守护模式维护的Clash配置文件
+	XrayAPI       string        // 守护模式中替换dest使用的xray API地址，为空时不替换
+	XrayInbound   string        // 需要替换dest的xray入站配置文件
+
+	Profile      string              // 扫描配置，stealth（低调）时启用下列限速规则的默认值
+	ProbeDelay   time.Duration       // 每个扫描线程两次连接之间的随机间隔上限
+	Rate         float64             // 全局每秒最多发起的连接数
+	SubnetRate   float64             // 同一/24每秒最多发起的连接数
+	StartJitter  time.Duration       // 扫描线程启动前随机等待的上限
+	ExcludeFiles []string            // 排除名单文件
+	Exclude      *scanner.Exclusions // 合并后的排除名单，为nil时不排除
+
+	CaptureTargets []string         // 需要保存握手抓包的IP
+	CaptureDir     string           // 握手抓包的保存目录
+	Capture        *scanner.Capture // 由CaptureTargets生成，为nil时不抓包
+
+	MockServers []string             // 本地TLS测试服务器（隐藏参数），格式为 地址[,选项...]
+	Mocks       []mockserver.Options // 由MockServers解析
+
+	WireGuard   string             // wg-quick格式的WireGuard配置文件，设置后探测流量经由进程内的用户态隧道发出
+	NAT64       string             // NAT64前缀，auto表示通过DNS64发现，为空时不使用NAT64
+	NAT64Prefix netip.Prefix       // 由NAT64解析的前缀，auto时在启动后发现
+	Egress      scanner.Dialer     // 经由隧道或NAT64的拨号器，为nil时直接连接
+	HTTPClient  *httpclient.Client // 辅助请求共用的HTTP客户端，经由隧道或NAT64时通过Egress连接
+
+	Vantages      []string // SSH观测点，格式为 [名称=]user@host[:port]
+	VantageMin    int      // 至少有多少个观测点符合条件，0表示不要求
+	VantageSpread int64    // 本机和各观测点响应时间的最大差值(毫秒)，0表示不限制
+
+	LogFormat     string // 终端日志格式
+	LogLevel      string // 日志级别，支持按组件设置
+	LogFile       string // 日志文件路径
+	LogFileFormat string // 日志文件格式
+	LogFileLevel  string // 日志文件级别，与终端日志级别无关
+	LogFileSize   int    // 日志文件轮转大小（MB），为0时不轮转
+	LogBackups    int    // 保留的已轮转日志文件数
+
+	HTTPTimeout time.Duration // 辅助HTTP请求（CDN检测、抓取域名、下载数据库等）的超时
+	HTTPProxy   string        // 辅助HTTP请求使用的代理
+	HTTPRetries int           // 辅助HTTP请求遇到临时错误时的重试次数
+	UserAgent   string        // 辅助HTTP请求的User-Agent
+
+	GeoDBMaxAge time.Duration // 地理位置数据库的最长使用时间，超过时提示更新
+	UpdateGeoDB bool          // 仅更新地理位置数据库后退出
+	GeoMirrors  []string      // 数据库下载镜像
+	GeoProxy    string        // 下载数据库使用的代理
+
+	ResolveWorkers int      // 从文件或页面读取目标时预解析域名的并发数
+	DoHRegions     []string // 按地区解析域名的DoH解析器，格式为 地区=DoH地址[,ECS子网]
+	CertPins       string   // 跨扫描记录证书指纹的文件，为空时不记录
+	HistoryDB      string   // 历史结果数据库，为空时不记录
+	HoneypotCheck  bool     // 检查符合条件的目标是否疑似蜜罐或sinkhole
+	Confidence     bool     // 对符合条件的目标进行独立检查并评定可信度等级
+	MinConfidence  string   // 最低可信度等级，低于该等级的目标不写入结果
+
+	ReverseIP        []reverseip.Provider // 反查IP服务，为空时不反查
+	ReverseIPLookups int                  // 最多反查的IP数
+
+	PostCheckTTL     time.Duration            // CDN检测和ping连通性的结果按证书域名缓存的时间，0表示不缓存
+	PostChecks       *feasibility.DomainCache // 由PostCheckTTL创建，可行性检查和可信度评级共用
+	PostCheckWorkers int                      // 扫描线程之外进行CDN检测和连通性检测的并发数，0表示线程数的1/4
+
+	Stop       scanner.StopConditions // 停止条件
+	PingDomain bool                   // 是否ping域名测试连通性
+	Filter     *feasibility.Filter    // 自定义可行性表达式，为nil时使用内置规则
+	Countries  []string               // 允许的国家代码，握手前跳过其他国家的IP，为空时不限制
+	Preferred  []string               // 首选国家代码，结果表中突出显示
+	PTRFilter  bool                   // 握手前反向解析IP，跳过CDN边缘节点和云负载均衡
+	VerifySNI  bool                   // 以证书域名和PTR记录作为候选SNI重新握手验证
+	ProbeMTU   bool                   // 用大尺寸ClientHello检测路径MTU问题
+	Hello      *scanner.HelloProfile  // 由--client-hello解析的ClientHello配置，为nil时不探测
+	TCPPings   int                    // 握手成功后追加的TCP连接次数，用于测量TCP延迟中位数
+	ProbeTTL   bool                   // 用ICMP回显记录TTL并估算跳数
+	Middlebox  bool                   // 握手失败时重新探测，识别中间设备干扰
+	Traceroute int                    // 扫描结束后跟踪路由的符合条件目标数，0表示不跟踪
+
+	GeoDownloader *geo.Downloader // 由GeoMirrors和GeoProxy构建的数据库下载器
+}
+
+// defaultConfig 返回默认命令行配置
+func defaultConfig() *Config {
+	return &Config{
+		Ports:   []int{443},
+		Thread:  20,
+		Timeout: 10,
+		Output:  "out.csv",
+		Verbose: false,
+		IPv6:    false,
+
+		StatusLine: true,
+		Color:      true,
+		PageSize:   defaultPageSize,
+
+		CollectorBatch: 50,
+
+		WatchInterval: time.Hour,
+		PruneAfter:    3,
+
+		LogFormat:     "pretty",
+		LogLevel:      "info",
+		LogFileFormat: "text",
+		LogFileLevel:  "debug",
+		LogFileSize:   100,
+		LogBackups:    3,
+
+		HTTPTimeout: 15 * time.Second,
+		HTTPRetries: 2,
+		UserAgent:   "GetRealityDomain/" + version,
+
+		GeoDBMaxAge: 30 * 24 * time.Hour,
+
+		ResolveWorkers:   32,
+		ReverseIPLookups: 20,
+		CertPins:         userConfigPath("certs.json"),
+		HistoryDB:        userConfigPath("history.db"),
+		CaptureDir:       "captures",
+		PostCheckTTL:     feasibility.DefaultDomainCacheTTL,
+	}
+}
+
+// hasTargets 是否已通过命令行参数指定扫描目标
+func (c *Config) hasTargets() bool {
+	return c.TargetsFile != "" || c.Stdin || c.DomainsFile != "" || c.FromURL != "" || c.HostsFile != "" || c.ZoneFile != ""
+}
+
+var logger = logging.For("cli")
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// streamFormat --stdout 的格式，单独指定 --stdout 时为csv
+type streamFormat string
+
+func (f *streamFormat) String() string {
+	return string(*f)
+}
+
+func (f *streamFormat) Set(value string) error {
+	switch value {
+	case "true":
+		*f = output.StreamCSV
+	case "false":
+		*f = ""
+	case output.StreamCSV, output.StreamJSONL:
+		*f = streamFormat(value)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s（可选 csv、jsonl）", value)
+	}
+	return nil
+}
+
+// IsBoolFlag 允许不带值使用 --stdout
+func (f *streamFormat) IsBoolFlag() bool {
+	return true
+}
+
+// 解析命令行参数并初始化日志，返回的io.Closer用于关闭日志文件
+func parseFlags() (*Config, io.Closer) {
+	config := defaultConfig()
+
+	portsSpec := flag.String("ports", "443", "每个目标扫描的端口列表，支持逗号分隔和区间，如 443,8443,2053,2083 或 2050-2060")
+	discoverSpec := flag.String("discover-ports", "", "端口发现：扫描端口未完成TLS 1.3握手时依次尝试的备用端口，记录第一个成功的端口，如 8443,2053,2083,2087,2096")
+	flag.StringVar(&config.TargetsFile, "targets", config.TargetsFile, "从文件读取扫描目标：每行一个IP、CIDR、IP区间或域名（行尾可附加 port=、sni=、country= 选项），边读边扫描，重复的行只扫描一次")
+	flag.BoolVar(&config.Stdin, "stdin", config.Stdin, "从标准输入读取扫描目标，格式与 --targets 相同，便于接在masscan、zmap等程序之后；启用时不进行任何交互（同 --headless）")
+	flag.StringVar(&config.DomainsFile, "domains", config.DomainsFile, "域名列表模式：扫描文件中的候选域名（每行一个，行尾可附加 port=、sni=、country= 选项），解析后以域名作为SNI握手并进行可行性判断")
+	flag.StringVar(&config.FromURL, "from-url", config.FromURL, "从指定页面抓取域名作为扫描目标，去重后解析并以域名作为SNI握手，结果的ORIGIN记录该页面地址")
+	flag.StringVar(&config.HostsFile, "hosts-file", config.HostsFile, "从/etc/hosts格式的文件读取目标，以名称作为SNI直接连接对应IP")
+	flag.StringVar(&config.ZoneFile, "zone-file", config.ZoneFile, "从BIND区域文件读取目标，A/AAAA记录直接连接记录地址，CNAME等记录的名称解析后扫描")
+	flag.IntVar(&config.ResolveWorkers, "resolve-workers", config.ResolveWorkers, "从文件或页面读取目标时，在握手前并发预解析域名的协程数（结果会缓存），0表示由扫描线程各自解析")
+	flag.StringVar(&config.CertPins, "cert-pins", config.CertPins, "跨扫描记录每个目标证书指纹的文件，证书自上次扫描后发生变化时在PREV_CERT_SHA256列记录上次的指纹，为空时不记录")
+	flag.StringVar(&config.HistoryDB, "history-db", config.HistoryDB, "历史结果数据库（SQLite），记录每次扫描完成握手的结果，可用 history <IP> 查看目标的长期表现，为空时不记录")
+	flag.BoolVar(&config.HoneypotCheck, "honeypot-check", config.HoneypotCheck, "检查符合条件的目标是否疑似蜜罐或sinkhole（随机高位端口全部开放、同一证书出现在多个不相关的网络、握手极快且页面为空、证书属于已知sinkhole），疑似的目标仍写入结果，SUSPICIOUS列记录疑点；需要额外连接几个随机高位端口")
+	flag.BoolVar(&config.Confidence, "confidence", config.Confidence, "对符合条件的目标进行独立检查（重复握手、HTTP/2请求、ping连通性、CDN检测），按通过的检查数在CONFIDENCE列记录可信度等级：A全部通过，B一项未通过，C两项及以上未通过")
+	flag.StringVar(&config.MinConfidence, "min-confidence", config.MinConfidence, "最低可信度等级（A/B/C），低于该等级的目标不写入结果，为空时只评级不排除")
+	flag.Var((*stringList)(&config.DoHRegions), "doh-region", "按地区解析域名目标，格式为 地区=DoH地址[,ECS子网]，如 jp=https://dns.google/dns-query,203.0.113.0/24，可重复指定；扫描各地区返回的所有IP，REGION列记录返回该IP的地区")
+	flag.StringVar(&config.ZoneOrigin, "zone-origin", config.ZoneOrigin, "区域文件的区域名，文件中没有$ORIGIN时用于补全相对名称")
+	flag.IntVar(&config.Sample, "sample", config.Sample, "从CIDR或IP区间中均匀随机抽取指定数量的地址扫描，用于快速了解大网段（如/8）的情况，0表示扫描全部地址")
+	flag.BoolVar(&config.Resume, "resume", config.Resume, "无限扫描模式从上次保存的进度继续向外扩展（进度总是保存在 <输出文件>.expansion.json），不指定时从指定IP重新开始")
+	flag.IntVar(&config.Expand.Stride, "expand-stride", config.Expand.Stride, "无限扫描模式中相邻两个扫描地址的间隔，如 4 表示每4个地址扫描一个，用于快速粗扫大范围")
+	flag.Uint64Var(&config.Expand.MaxRadius, "expand-radius", config.Expand.MaxRadius, "无限扫描模式中每个方向距种子IP的最大地址数，到达后该方向停止扩展，两个方向都停止后扫描结束；0表示不限制")
+	flag.IntVar(&config.Expand.Chunk, "expand-chunk", config.Expand.Chunk, "无限扫描模式每次在一个方向上连续扫描的地址块大小，块按大小对齐，如 16 表示每次扫描完一个/28再换方向；默认逐个地址上下交替")
+	flag.IntVar(&config.Neighborhood, "neighborhood", config.Neighborhood, "扫描IP段时发现符合条件的目标后优先扫描其所在的周边网段，值为IPv4前缀长度（24-31），如 28 表示/28，0表示不启用")
+	flag.IntVar(&config.SkipDead, "skip-dead", config.SkipDead, "扫描IP段时同一/24中连续指定次数无响应（连接超时、不可达）后跳过该网段剩余地址，0表示不跳过")
+	flag.IntVar(&config.ASNBlock, "asn-block", config.ASNBlock, "扫描IP段时，同一ASN产生指定数量的结果仍没有符合条件的目标后跳过该ASN的剩余地址（需要ASN数据库），0表示不启用")
+	flag.BoolVar(&config.ASNDefer, "asn-defer", config.ASNDefer, "与--asn-block配合，将低产ASN的地址延后到其他地址之后扫描，而不是跳过")
+	countriesSpec := flag.String("countries", "", "只扫描和接受指定国家的目标，逗号分隔，如 JP,KR,US；IP目标在握手前按地理位置过滤")
+	flag.BoolVar(&config.PTRFilter, "ptr-filter", config.PTRFilter, "握手前反向解析IP目标，跳过PTR名称属于CDN边缘节点或云负载均衡的地址（如 *.cloudfront.net、*.akamaitechnologies.com）")
+	flag.BoolVar(&config.VerifySNI, "verify-sni", config.VerifySNI, "IP目标握手成功后，以证书中的域名和PTR记录作为候选SNI重新握手，在SNI_MATCH列记录服务器出示了匹配证书的SNI")
+	flag.BoolVar(&config.ProbeMTU, "probe-mtu", config.ProbeMTU, "握手成功后再用约4KB的ClientHello握手一次，在MTU列记录路径上是否存在PMTU黑洞或破坏大报文的中间设备")
+	helloSpec := flag.String("client-hello", "", "握手成功后以模仿指定客户端的ClientHello再连接一次，在HELLO_PROFILE列记录服务器的响应（ok、hrr、tls1.2、alert:<原因>等）；"+
+		"可选预设 "+strings.Join(scanner.HelloPresets(), "、")+"，并可用分号追加 curves=、sigalgs=、ext=、alpn= 调整椭圆曲线、签名算法、扩展顺序和ALPN，如 'firefox;curves=p256,x25519'")
+	flag.BoolVar(&config.Middlebox, "detect-interference", config.Middlebox, "握手在连接建立后被重置或超时时重新探测，符合干扰特征（ClientHello后立即重置、只有带SNI时失败、只有TLS超时）的失败记为interference，这些失败也写入结果文件（FEASIBLE为false），MIDDLEBOX列记录干扰类型")
+	flag.BoolVar(&config.ProbeTTL, "probe-ttl", config.ProbeTTL, "握手成功后向目标发送ICMP回显请求，在TTL和HOPS列记录应答的TTL和估算的跳数（需要允许无特权ICMP或以root运行）")
+	flag.IntVar(&config.Traceroute, "traceroute", config.Traceroute, "扫描结束后对前N个符合条件的目标进行路由跟踪，路径和共同节点写入 <输出文件>"+output.TracerouteSuffix+"（需要root权限或CAP_NET_RAW）")
+	flag.IntVar(&config.TCPPings, "tcp-pings", config.TCPPings, "握手成功后追加指定次数的TCP连接，TCP_RTT_MS列记录连接延迟的中位数（默认只记录首次连接延迟）")
+	reverseSpec := flag.String("reverse-ip", "", "对证书为空或通用证书的IP目标反查同IP上的域名并以其为SNI验证，可选 hackertarget、rapiddns 或包含{ip}的接口地址，逗号分隔")
+	flag.IntVar(&config.ReverseIPLookups, "reverse-ip-max", config.ReverseIPLookups, "最多反查的IP数，0表示不限制（免费接口通常有每日次数限制）")
+	flag.StringVar(&config.CollectorURL, "collector-url", config.CollectorURL, "远程收集端地址，符合条件的结果将以NDJSON格式批量推送")
+	flag.Var((*stringList)(&config.CollectorHeaders), "collector-header", "推送时附带的请求头，格式为\"Key: Value\"，可重复指定")
+	flag.StringVar(&config.CollectorNode, "collector-node", config.CollectorNode, "扫描节点名称，默认为主机名")
+	flag.IntVar(&config.CollectorBatch, "collector-batch", config.CollectorBatch, "每批推送的结果数")
+	flag.StringVar(&config.PublishURL, "publish", config.PublishURL, "实时发布符合条件的结果，如 redis://:密码@host:6379/频道 或 nats://host:4222/主题")
+	flag.StringVar(&config.OnFeasible, "on-feasible", config.OnFeasible, "发现符合条件的目标时执行的命令，支持模板字段，如 './notify.sh {{.IP}} {{.CertDomain}}'")
+	flag.Var((*streamFormat)(&config.Stdout), "stdout", "管道模式：符合条件的结果在发现时逐条写入标准输出（--stdout 或 --stdout=csv 为CSV，--stdout=jsonl 为每行一个JSON对象），标题、提示、进度和日志改为写入标准错误，便于交给grep、jq等程序处理；结果文件照常保存")
+	flag.StringVar(&config.Progress, "progress-events", config.Progress, "定期输出JSON行格式的进度事件（已扫描、合规、错误数和速率），可选 stderr 或 unix:/path/to.sock（向连接的客户端广播）")
+	flag.BoolVar(&config.Notify, "notify", config.Notify, "扫描完成或达到结果数上限时发送桌面通知（Linux需要notify-send）")
+	flag.StringVar(&config.NotifyCmd, "notify-command", config.NotifyCmd, "自定义通知命令，代替系统通知，支持 {{.Title}}、{{.Message}}、{{.Scanned}}、{{.Feasible}}，如 'curl -d {{.Message}} ntfy.sh/mytopic'")
+	flag.StringVar(&config.Alert, "alert", config.Alert, "发现符合条件的目标时发出提示音：bell 终端响铃，sound 播放系统提示音（无播放程序时退回响铃）")
+	flag.DurationVar(&config.WatchInterval, "watch-interval", config.WatchInterval, "watch 守护模式重新验证结果文件中目标的间隔")
+	flag.IntVar(&config.PruneAfter, "prune-after", config.PruneAfter, "watch 守护模式中目标连续验证失败指定次数后从结果文件中移除，并重新生成导出的配置")
+	flag.StringVar(&config.ExportReality, "export-reality", config.ExportReality, "watch 守护模式维护的Reality配置文件，启动时和移除目标后重新生成")
+	flag.StringVar(&config.ExportClash, "export-clash", config.ExportClash, "watch 守护模式维护的Clash.Meta代理集合文件，启动时和移除目标后重新生成")
+	flag.StringVar(&config.Profile, "profile", config.Profile, "扫描配置：stealth（低调）在每次连接前随机等待、限制全局和每个/24的连接速率并错开线程启动，"+
+		"并使用用户配置目录中的 exclude.txt 排除名单，适合在收到扫描投诉就会封号的服务商上运行；单独指定的限速参数优先")
+	flag.DurationVar(&config.ProbeDelay, "probe-delay", config.ProbeDelay, "每个扫描线程两次连接之间的随机间隔上限，实际间隔在该值的一半到该值之间，0表示不等待")
+	flag.Float64Var(&config.Rate, "rate", config.Rate, "全局每秒最多发起的连接数，0表示不限制")
+	flag.Float64Var(&config.SubnetRate, "subnet-rate", config.SubnetRate, "同一/24（IPv6为/48）每秒最多发起的连接数，如 0.2 表示每5秒一次，0表示不限制")
+	flag.DurationVar(&config.StartJitter, "start-jitter", config.StartJitter, "扫描线程启动前随机等待的上限，避免扫描开始时的连接突发，0表示不等待")
+	flag.Var((*stringList)(&config.ExcludeFiles), "exclude", "排除名单文件，每行一个IP或CIDR，其中的地址不会被扫描，可重复指定")
+	flag.Var((*stringList)(&config.CaptureTargets), "capture", "调试：保存与该IP每次握手的抓包，可重复指定；每次握手写入一个pcap文件（可用Wireshark打开）和一个包含ClientHello/ServerHello十六进制转储及握手结果的文本文件")
+	flag.StringVar(&config.CaptureDir, "capture-dir", config.CaptureDir, "握手抓包的保存目录")
+	flag.Var((*stringList)(&config.MockServers), "mock-server", "测试：启动本地TLS测试服务器，格式为 地址[,tls=1.2|1.3][,alpn=h2;http/1.1|none][,san=域名;...][,issuer=名称][,cdn]，可重复指定；"+
+		"没有扫描目标时保持运行直到中断，否则与扫描在同一进程中运行，用于不访问互联网的端到端测试")
+	flag.StringVar(&config.XrayAPI, "xray-api", config.XrayAPI, "watch 守护模式中当前使用的dest验证失败时，通过该xray API地址（如 127.0.0.1:10085）将入站的dest替换为响应最快的可用目标")
+	flag.StringVar(&config.XrayInbound, "xray-inbound", config.XrayInbound, "与 --xray-api 配合，只包含Reality入站的xray配置文件（{\"inbounds\":[...]}），替换时更新其中的dest和serverNames")
+	flag.StringVar(&config.WireGuard, "wireguard", config.WireGuard, "WireGuard配置文件（wg-quick格式，如 wgcf generate 生成的WARP配置），设置后扫描和各项检查的连接都经由用户态WireGuard隧道发出，"+
+		"以该网络的视角扫描，不需要root权限和系统VPN；ping、TTL和路由跟踪等ICMP探测不经过隧道")
+	flag.StringVar(&config.NAT64, "nat64", config.NAT64, "在只有IPv6的主机上经由NAT64扫描IPv4目标：指定NAT64前缀（如 64:ff9b::/96），或 auto 通过DNS64解析 ipv4only.arpa 发现前缀；"+
+		"结果仍记录IPv4地址，ping等ICMP探测不经过NAT64")
+	flag.Var((*stringList)(&config.Vantages), "vantage", "SSH观测点，格式为 [名称=]user@host[:port]，可重复指定；符合条件的目标会经由各观测点的ssh动态转发重新握手，记录各观测点的可行性和延迟（需要系统ssh命令和免密登录）")
+	flag.IntVar(&config.VantageMin, "vantage-min", config.VantageMin, "共识规则：至少有K个观测点符合条件才记为符合条件（不含本机），0表示不要求")
+	flag.Int64Var(&config.VantageSpread, "vantage-spread", config.VantageSpread, "共识规则：本机和符合条件的观测点之间响应时间相差超过该值(毫秒)时记为不符合条件，0表示不限制")
+	flag.StringVar(&config.LogFormat, "log-format", config.LogFormat, "终端日志格式: pretty、text、json")
+	flag.StringVar(&config.LogLevel, "log-level", config.LogLevel, "日志级别，可按组件设置，如 'info,scanner=debug,geo=warn'")
+	flag.StringVar(&config.LogFile, "log-file", config.LogFile, "同时将日志写入指定文件")
+	flag.StringVar(&config.LogFileFormat, "log-file-format", config.LogFileFormat, "日志文件格式: text、json")
+	flag.StringVar(&config.LogFileLevel, "log-file-level", config.LogFileLevel, "日志文件级别，与终端日志级别无关，默认记录每个目标的扫描结果和错误")
+	flag.IntVar(&config.LogFileSize, "log-file-max-size", config.LogFileSize, "日志文件超过该大小（MB）时轮转，0表示不轮转")
+	flag.IntVar(&config.LogBackups, "log-file-backups", config.LogBackups, "保留的已轮转日志文件数")
+	flag.DurationVar(&config.GeoDBMaxAge, "geodb-max-age", config.GeoDBMaxAge, "地理位置数据库超过该时长未更新时给出提示，0表示不检查")
+	flag.BoolVar(&config.UpdateGeoDB, "update-geodb", config.UpdateGeoDB, "重新下载地理位置和ASN数据库后退出")
+	flag.Var((*stringList)(&config.GeoMirrors), "geodb-mirror", "数据库下载镜像地址，可重复指定并按顺序尝试，地址中的{file}会替换为文件名，否则追加到末尾")
+	flag.StringVar(&config.GeoProxy, "geodb-proxy", config.GeoProxy, "下载数据库和检查更新使用的代理，如 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080，未设置时使用 --http-proxy")
+	flag.DurationVar(&config.HTTPTimeout, "http-timeout", config.HTTPTimeout, "辅助HTTP请求（CDN检测、抓取域名、下载数据库、查询公网IP、反查域名）单次请求的超时")
+	flag.StringVar(&config.HTTPProxy, "http-proxy", config.HTTPProxy, "辅助HTTP请求使用的代理，如 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080，未设置时使用 HTTP_PROXY 等环境变量；TLS握手不经过该代理")
+	flag.IntVar(&config.HTTPRetries, "http-retries", config.HTTPRetries, "辅助HTTP请求遇到超时、连接重置或429/502/503/504时的重试次数")
+	flag.StringVar(&config.UserAgent, "user-agent", config.UserAgent, "辅助HTTP请求的User-Agent")
+	flag.IntVar(&config.Stop.MaxFeasible, "max-results", config.Stop.MaxFeasible, "找到指定数量的符合条件目标后停止，设置后不再询问")
+	flag.IntVar(&config.Stop.MaxScanned, "max-scanned", config.Stop.MaxScanned, "扫描指定数量的目标后停止，0表示不限制")
+	flag.DurationVar(&config.Stop.MaxDuration, "max-duration", config.Stop.MaxDuration, "扫描达到指定时长后停止并保存结果、输出统计，如 30m、2h，0表示不限制")
+	flag.Float64Var(&config.Stop.Coverage, "coverage", config.Stop.Coverage, "扫描目标达到总数的指定百分比后停止，如 50 表示扫描一半，0表示不限制")
+	flag.BoolVar(&config.TUI, "tui", config.TUI, "扫描时使用全屏界面：实时目标表、进度和预计剩余时间、日志窗格，可按键停止、暂停或查看目标详情")
+	flag.BoolVar(&config.StatusLine, "status-line", config.StatusLine, "在终端底部固定显示状态行，日志和发现的目标在其上方滚动；设为false时定期清屏刷新整屏状态")
+	timeZone := flag.String("time-zone", "UTC", "结果中SCAN_TIME列的时区，记录为RFC3339格式，如 UTC、Local、Asia/Shanghai")
+	flag.BoolVar(&config.Color, "color", config.Color, "按响应时间为结果着色（<150ms绿色、<300ms黄色、其余红色）并突出显示首选国家；输出不是终端或设置了NO_COLOR时自动关闭")
+	flag.IntVar(&config.PageSize, "page-size", config.PageSize, "扫描结束后结果查看器每页显示的目标数，查看时可按 S 修改")
+	preferredSpec := flag.String("prefer-countries", "", "在实时输出、全屏界面和结果查看器中突出显示的国家，逗号分隔，如 JP,KR,US")
+	flag.StringVar(&config.Target, "target", config.Target, "扫描目标：IP、CIDR或IP区间，可带端口，如 1.2.3.0/24、1.2.3.4:2053，指定后不再询问IP和网段；"+
+		"单个IPv4地址从该地址向上下无限扩展，单个IPv6地址扫描所在/64中常见的地址形式（::1等小数字、嵌入的IPv4、相邻的EUI-64）")
+	flag.IntVar(&config.Thread, "threads", config.Thread, "并发线程数，指定后不再询问")
+	flag.BoolVar(&config.PingDomain, "ping", config.PingDomain, "启用ping域名测试连通性，指定后不再询问；默认关闭，交互模式下首次询问的默认回答同样为否")
+	flag.IntVar(&config.PostCheckWorkers, "post-check-workers", config.PostCheckWorkers, "CDN检测和ping连通性检测在扫描线程之外由独立的协程池进行，指定其并发数，0表示线程数的1/4")
+	flag.DurationVar(&config.PostCheckTTL, "post-check-ttl", config.PostCheckTTL, "CDN检测和ping连通性的结果按证书域名缓存的时间，大量IP出示同一证书域名时只检测一次，0表示每个IP都重新检测")
+	flag.BoolVar(&config.Headless, "headless", config.Headless, "无交互模式：不读取标准输入，缺少扫描目标时报错退出，其余问题使用默认值，适合在容器中一次性运行；标准输入不是终端时自动启用。所有参数都可用 GRD_ 开头的环境变量指定，如 GRD_TARGET、GRD_MAX_RESULTS")
+	flag.BoolVar(&config.NoBanner, "no-banner", config.NoBanner, "不显示标题、暂停提示和装饰性边框，扫描结束后不进入结果查看器，只输出发现的目标和日志，便于脚本调用；标准输出不是终端时自动启用")
+	flag.BoolVar(&config.ShowVersion, "version", config.ShowVersion, "输出版本、提交和构建时间后退出")
+	flag.BoolVar(&config.CheckUpdate, "check-update", config.CheckUpdate, "与 --version 一起使用时查询是否有新的发布版本（使用 --geodb-proxy 指定的代理）")
+	flag.BoolVar(&config.Verbose, "verbose", config.Verbose, "详细输出，等同于 -log-level debug")
+	filterExpr := flag.String("filter", "", "自定义可行性表达式，替代内置规则，如 'TLSVersion == \"TLS 1.3\" && ResponseTime < 120 && GeoCode in [\"JP\",\"KR\"] && !CDN'")
+	flag.Usage = usage
+	flag.Parse()
+	envErr := applyEnvFlags()
+
+	if config.Verbose {
+		config.LogLevel += ",debug"
+	}
+
+	logCloser, err := logging.Setup(logging.Options{
+		Format:      config.LogFormat,
+		Level:       config.LogLevel,
+		File:        config.LogFile,
+		FileFormat:  config.LogFileFormat,
+		FileLevel:   config.LogFileLevel,
+		FileMaxSize: int64(config.LogFileSize) << 20,
+		FileBackups: config.LogBackups,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 初始化日志失败: %v\n", err)
+		os.Exit(2)
+	}
+	if envErr != nil {
+		logger.Error(envErr.Error())
+		os.Exit(2)
+	}
+
+	// 标准输入用于读取目标列表时同样不能询问
+	if config.Stdin || !isatty.IsTerminal(os.Stdin.Fd()) {
+		config.Headless = true
+	}
+	if config.Headless {
+		if config.TUI {
+			logger.Warn("无交互模式下不使用全屏界面")
+			config.TUI = false
+		}
+		config.NoBanner = true
+	}
+
+	if !isatty.IsTerminal(os.Stdout.Fd()) {
+		if config.TUI {
+			logger.Warn("标准输出不是终端，不使用全屏界面")
+			config.TUI = false
+		}
+		config.NoBanner = true
+	}
+	console.SetPlain(config.NoBanner)
+
+	// 管道模式：标准输出只写入结果，其余输出（包括交互提示和日志）改为写入标准错误
+	if config.Stdout != "" {
+		config.StdoutFile = os.Stdout
+		os.Stdout = os.Stderr
+		logging.SetTerminal(os.Stderr)
+		console.SetColor(isatty.IsTerminal(os.Stderr.Fd()) && os.Getenv("NO_COLOR") == "")
+	}
+
+	if config.Neighborhood != 0 {
+		if _, err := scanner.NewNeighborhood(config.Neighborhood); err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+	}
+
+	var vantageNames []string
+	for _, spec := range config.Vantages {
+		tunnel, err := parseVantage(spec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		vantageNames = append(vantageNames, tunnel.name)
+	}
+	output.SetVantages(vantageNames)
+	for _, spec := range config.DoHRegions {
+		if _, err := scanner.ParseRegionResolver(spec, nil); err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+	}
+	if err := config.applyProfile(); err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	if len(config.CaptureTargets) > 0 {
+		config.Capture = &scanner.Capture{Dir: config.CaptureDir}
+		for _, target := range config.CaptureTargets {
+			ip, err := netip.ParseAddr(target)
+			if err != nil {
+				logger.Error("无效的抓包目标，需要指定IP", "capture", target)
+				os.Exit(2)
+			}
+			config.Capture.Targets = append(config.Capture.Targets, ip.Unmap())
+		}
+	}
+	if config.PostCheckTTL > 0 {
+		config.PostChecks = feasibility.NewDomainCache(config.PostCheckTTL)
+	}
+	if *helloSpec != "" {
+		if config.Hello, err = scanner.ParseHelloProfile(*helloSpec); err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+	}
+	if config.NAT64 != "" && config.NAT64 != nat64Auto {
+		if config.NAT64Prefix, err = scanner.ParseNAT64Prefix(config.NAT64); err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+	}
+	if config.NAT64 != "" && config.WireGuard != "" {
+		logger.Error("--nat64 和 --wireguard 不能同时使用")
+		os.Exit(2)
+	}
+	if config.WireGuard != "" && (config.ProbeTTL || config.Traceroute > 0) {
+		logger.Warn("TTL探测和路由跟踪使用ICMP，不经过WireGuard隧道，记录的是本机网络的路径")
+	}
+	for _, spec := range config.MockServers {
+		opts, err := mockserver.ParseOptions(spec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.Mocks = append(config.Mocks, opts)
+	}
+	if config.MinConfidence != "" {
+		config.MinConfidence = strings.ToUpper(config.MinConfidence)
+		if !feasibility.ValidGrade(config.MinConfidence) {
+			logger.Error("无效的可信度等级，可选 A、B、C", "min-confidence", config.MinConfidence)
+			os.Exit(2)
+		}
+		if !config.Confidence {
+			logger.Error("--min-confidence 需要启用 --confidence")
+			os.Exit(2)
+		}
+	}
+
+	if config.VantageMin > len(config.Vantages) {
+		logger.Error("要求符合条件的观测点数超过了观测点总数", "vantage_min", config.VantageMin, "vantages", len(config.Vantages))
+		os.Exit(2)
+	}
+
+	if config.Expand.Stride < 0 || config.Expand.Chunk < 0 {
+		logger.Error("无限扫描模式的步长和地址块大小不能为负数", "expand_stride", config.Expand.Stride, "expand_chunk", config.Expand.Chunk)
+		os.Exit(2)
+	}
+
+	if config.Stop.MaxDuration < 0 {
+		logger.Error("扫描时长上限不能为负数", "max_duration", config.Stop.MaxDuration)
+		os.Exit(2)
+	}
+
+	ports, err := scanner.ParsePorts(*portsSpec)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	config.Ports = ports
+
+	if *discoverSpec != "" {
+		discoverPorts, err := scanner.ParsePorts(*discoverSpec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.DiscoverPorts = discoverPorts
+	}
+
+	if *countriesSpec != "" {
+		countries, err := scanner.ParseCountries(*countriesSpec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.Countries = countries
+	}
+
+	loc, err := time.LoadLocation(*timeZone)
+	if err != nil {
+		logger.Error("无效的时区", "time_zone", *timeZone, "error", err)
+		os.Exit(2)
+	}
+	output.SetTimeZone(loc)
+
+	if *preferredSpec != "" {
+		preferred, err := scanner.ParseCountries(*preferredSpec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.Preferred = preferred
+	}
+	if !config.Color {
+		console.SetColor(false)
+	}
+	console.SetPreferredCountries(config.Preferred)
+
+	if *reverseSpec != "" {
+		providers, err := reverseip.ParseProviders(*reverseSpec)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.ReverseIP = providers
+	}
+
+	if *filterExpr != "" {
+		filter, err := feasibility.NewFilter(*filterExpr)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.Filter = filter
+	}
+
+	if config.HTTPClient, err = newHTTPClient(config, config.HTTPProxy, nil); err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	config.GeoDownloader = &geo.Downloader{Client: config.HTTPClient, Mirrors: config.GeoMirrors}
+	if config.GeoProxy != "" {
+		client, err := newHTTPClient(config, config.GeoProxy, nil)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(2)
+		}
+		config.GeoDownloader.Client = client
+	}
+
+	return config, logCloser
+}
+
+func main() {
+	config, logCloser := parseFlags()
+	defer logCloser.Close()
+
+	if config.ShowVersion {
+		printVersion(config)
+		return
+	}
+
+	if config.UpdateGeoDB {
+		if err := updateGeoDB(context.Background(), config.GeoDownloader); err != nil {
+			logger.Error("更新数据库失败", "error", err)
+			logCloser.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// --mock-server：启动测试服务器，没有扫描目标时一直运行，否则随扫描结束关闭
+	if len(config.Mocks) > 0 {
+		servers, err := startMockServers(config)
+		if err != nil {
+			logger.Error(err.Error())
+			logCloser.Close()
+			os.Exit(1)
+		}
+		defer mockserver.CloseAll(servers)
+		if flag.NArg() == 0 && config.Target == "" && !config.hasTargets() {
+			waitMockServers()
+			return
+		}
+	}
+
+	// --wireguard：之后的扫描、重新测试、调试和守护模式都经由隧道连接目标
+	closeWireGuard, err := startWireGuard(context.Background(), config)
+	if err != nil {
+		logger.Error(err.Error())
+		logCloser.Close()
+		os.Exit(1)
+	}
+	defer closeWireGuard()
+	if err := startNAT64(context.Background(), config); err != nil {
+		logger.Error(err.Error())
+		logCloser.Close()
+		os.Exit(1)
+	}
+
+	// 子命令：scan、verify、export、serve、view、watch 等，见commands
+	if flag.NArg() > 0 {
+		cmd, ok := findCommand(flag.Arg(0))
+		if !ok {
+			logger.Error("未知的子命令", "command", flag.Arg(0))
+			usage()
+			closeWireGuard()
+			logCloser.Close()
+			os.Exit(2)
+		}
+		if err := cmd.run(config, flag.Args()[1:]); err != nil {
+			logger.Error(err.Error())
+			closeWireGuard()
+			logCloser.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 交互使用且未通过参数指定扫描目标时进入主菜单，否则与 scan 子命令相同
+	if !config.Headless && !config.NoBanner && !config.hasTargets() && config.Target == "" {
+		// 上次输入的线程数作为默认值，可在设置中修改
+		if answers := loadAnswers(); answers.Thread > 0 && !flagPassed("threads") {
+			config.Thread = answers.Thread
+		}
+		runMainMenu(config)
+		return
+	}
+	if err := runScanCommand(config, nil); err != nil {
+		logger.Error(err.Error())
+		if !config.Headless {
+			pause()
+		}
+		closeWireGuard()
+		logCloser.Close()
+		os.Exit(1)
+	}
+}
+
+// runNewScan 询问未通过参数指定的扫描设置，扫描完成后显示结果
+// 无交互模式下不询问，未指定的设置使用默认值
+func runNewScan(config *Config) error {
+	if config.Headless {
+		if config.Stop.MaxFeasible == 0 && !flagPassed("max-results") {
+			config.Stop.MaxFeasible = 10
+		}
+		return scanAndShow(config, config.Target)
+	}
+
+	// 上次的回答作为本次的默认值
+	answers := loadAnswers()
+
+	// 通过参数指定了扫描目标时直接使用，否则询问IP和网段
+	scanTarget := config.Target
+	if scanTarget == "" && !config.hasTargets() {
+		scanTarget = promptScanTarget(answers, config.HTTPClient)
+	}
+
+	// 询问是否找到10个符合的就停止，已通过 -max-results 指定时跳过
+	if config.Stop.MaxFeasible == 0 && !flagPassed("max-results") {
+		lastMax := 10
+		if answers.MaxResults != nil {
+			lastMax = *answers.MaxResults
+		}
+		stopAt10 := askYesNo("是否找到10个符合的就停止？", lastMax == 10)
+		if stopAt10 {
+			config.Stop.MaxFeasible = 10
+		} else {
+			if lastMax != 10 {
+				fmt.Printf("请输入最大结果数 (0表示无限制，默认: %d): ", lastMax)
+			} else {
+				fmt.Print("请输入最大结果数 (0表示无限制): ")
+			}
+			maxStr := getStringInput()
+			if maxStr == "" && lastMax != 10 {
+				config.Stop.MaxFeasible = lastMax
+			} else if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+				config.Stop.MaxFeasible = max
+			}
+		}
+		answers.MaxResults = &config.Stop.MaxFeasible
+	}
+
+	// 询问并发线程数，已通过 -threads 指定时跳过
+	if !flagPassed("threads") {
+		fmt.Printf("请输入并发线程数 (当前: %d, 建议1-100): ", config.Thread)
+		threadStr := getStringInput()
+		if threadStr != "" {
+			if thread, err := strconv.Atoi(threadStr); err == nil && thread > 0 && thread <= 1000 {
+				config.Thread = thread
+			} else {
+				logger.Error("无效的线程数，使用默认值")
+			}
+		}
+		answers.Thread = config.Thread
+	}
+
+	// 询问是否启用ping域名测试连通性，已通过 -ping 指定时跳过
+	if !flagPassed("ping") {
+		config.PingDomain = askYesNo("是否启用ping域名测试连通性？", answers.PingDomain)
+		answers.PingDomain = config.PingDomain
+	}
+	answers.save()
+
+	// 使用系统清屏命令
+	clearScreenSystem()
+	return scanAndShow(config, scanTarget)
+}
+
+// scanAndShow 扫描指定的目标（未通过参数指定目标清单时使用scanTarget），扫描完成后显示结果
+func scanAndShow(config *Config, scanTarget string) error {
+	logger.Info("开始扫描...")
+
+	var source *targetSource
+	var err error
+	switch {
+	case config.TargetsFile != "":
+		source, err = targetsFileSource(config.TargetsFile)
+	case config.Stdin:
+		logger.Info("从标准输入读取扫描目标")
+		source = streamSource(io.NopCloser(os.Stdin))
+	case config.DomainsFile != "":
+		source, err = domainListSource(config.DomainsFile)
+	case config.FromURL != "":
+		source, err = urlSource(context.Background(), config.HTTPClient, config.FromURL)
+	case config.HostsFile != "":
+		source, err = hostsFileSource(config.HostsFile)
+	case config.ZoneFile != "":
+		source, err = zoneFileSource(config.ZoneFile, config.ZoneOrigin)
+	default:
+		source, err = addressSource(scanTarget, config)
+	}
+	if err == nil {
+		err = runScan(context.Background(), config, source)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 扫描完成后显示结果，纯文本输出时结果只保存在文件中
+	rememberOutput(config.Output)
+	if !config.NoBanner {
+		showResultsPaginated(config)
+	}
+	return nil
+}
+
+// promptScanTarget 询问扫描的IP和网段，返回CIDR格式的扫描目标
+// 回答记录在answers中，上次的回答作为本次的默认值
+func promptScanTarget(answers *wizardAnswers, client scanner.HTTPClient) string {
+	// 获取本机IP
+	localIP, err := getLocalIP(context.Background(), client)
+	if err != nil {
+		logger.Error("获取本机IP失败", "error", err)
+		localIP = "127.0.0.1" // 默认值
+	}
+
+	// 询问是否使用本机IP
+	useLocalIP := askYesNo(fmt.Sprintf("本机IP为：%s，是否使用该IP？", localIP), answers.IP == "")
+	var targetIP string
+	var port int // 输入中指定的端口（如 1.2.3.4:2053），0表示使用全局端口
+	if useLocalIP {
+		targetIP = localIP
+		answers.IP = ""
+	} else {
+		fmt.Print("请输入要使用的IP地址或IP区间，可带端口 (如: 1.2.3.4:2053、1.2.3.0-1.2.5.255)")
+		if answers.IP != "" {
+			fmt.Printf("，默认: %s", answers.IP)
+		}
+		fmt.Print(": ")
+		input := getStringInput()
+		if input == "" {
+			input = answers.IP
+		}
+		answers.IP = input
+		targetIP, port, err = scanner.SplitHostPort(input)
+		if err != nil {
+			logger.Error("无效的端口，使用全局端口", "error", err)
+			targetIP, port = input, 0
+		}
+
+		// IP区间直接作为扫描目标，不再询问网段
+		if _, _, err := scanner.ParseRange(targetIP); err == nil {
+			return withPort(targetIP, port)
+		}
+		if _, err := netip.ParseAddr(targetIP); err != nil {
+			logger.Error("无效的IP地址格式，使用默认IP")
+			targetIP = localIP
+		}
+	}
+
+	// 询问是否使用/24段
+	lastMask := answers.Mask
+	if lastMask == "" {
+		lastMask = "/24"
+	}
+	use24Subnet := askYesNo("是否使用/24段？", lastMask == "/24")
+	answers.Mask = "/24"
+	var scanTarget string
+	if use24Subnet {
+		scanTarget = targetIP + "/24"
+	} else {
+		if lastMask != "/24" {
+			fmt.Printf("请输入子网掩码位数 (如: /20, /16，默认: %s): ", lastMask)
+		} else {
+			fmt.Print("请输入子网掩码位数 (如: /20, /16): ")
+		}
+		maskInput := getStringInput()
+		if maskInput == "" && lastMask != "/24" {
+			maskInput = lastMask
+		}
+		if maskInput == "" {
+			scanTarget = targetIP + "/24"
+			logger.Info("使用默认/24段")
+		} else {
+			// 处理用户输入，确保以/开头
+			if !strings.HasPrefix(maskInput, "/") {
+				maskInput = "/" + maskInput
+			}
+
+			// 验证掩码位数是否有效
+			if isValidMask(maskInput) {
+				// 计算网络地址
+				networkAddr, err := calculateNetworkAddress(targetIP, maskInput)
+				if err != nil {
+					logger.Error("计算网络地址失败，使用默认/24段")
+					scanTarget = targetIP + "/24"
+				} else {
+					scanTarget = networkAddr + maskInput
+					answers.Mask = maskInput
+					logger.Info("计算得到网段", "target", scanTarget)
+				}
+			} else {
+				logger.Error("无效的子网掩码位数，使用默认/24段")
+				scanTarget = targetIP + "/24"
+			}
+		}
+	}
+
+	return withPort(scanTarget, port)
+}
+
+// withPort 为扫描目标添加端口后缀，port为0时原样返回，IPv6目标加方括号
+func withPort(target string, port int) string {
+	if port == 0 {
+		return target
+	}
+	return net.JoinHostPort(target, strconv.Itoa(port))
+}
+
+// 显示大字标题
+func showTitle() {
+	console.ClearScreen()
+	fmt.Println()
+	fmt.Println("  ╔═══════════════════════════════════════════════════════════╗")
+	fmt.Println("  ║                                                           ║")
+	fmt.Println("  ║    ██████╗ ███████╗████████╗██████╗ ███████╗ █████╗      ║")
+	fmt.Println("  ║   ██╔════╝ ██╔════╝╚══██╔══╝██╔══██╗██╔════╝██╔══██╗     ║")
+	fmt.Println("  ║   ██║  ███╗█████╗     ██║   ██████╔╝█████╗  ███████║     ║")
+	fmt.Println("  ║   ██║   ██║██╔══╝     ██║   ██╔══██╗██╔══╝  ██╔══██║     ║")
+	fmt.Println("  ║   ╚██████╔╝███████╗   ██║   ██║  ██║███████╗██║  ██║     ║")
+	fmt.Println("  ║    ╚═════╝ ╚══════╝   ╚═╝   ╚═╝  ╚═╝╚══════╝╚═╝  ╚═╝     ║")
+	fmt.Println("  ║                                                           ║")
+	fmt.Println("  ║   ██████╗  ██████╗ ███╗   ███╗ █████╗ ██╗███╗   ██╗      ║")
+	fmt.Println("  ║   ██╔══██╗██╔═══██╗████╗ ████║██╔══██╗██║████╗  ██║      ║")
+	fmt.Println("  ║   ██║  ██║██║   ██║██╔████╔██║███████║██║██╔██╗ ██║      ║")
+	fmt.Println("  ║   ██║  ██║██║   ██║██║╚██╔╝██║██╔══██║██║██║╚██╗██║      ║")
+	fmt.Println("  ║   ██████╔╝╚██████╔╝██║ ╚═╝ ██║██║  ██║██║██║ ╚████║      ║")
+	fmt.Println("  ║   ╚═════╝  ╚═════╝ ╚═╝     ╚═╝╚═╝  ╚═╝╚═╝╚═╝  ╚═══╝      ║")
+	fmt.Println("  ║                                                           ║")
+	fmt.Println("  ║                Reality协议目标域名扫描器                    ║")
+	fmt.Printf("  ║%-59s║\n", fmt.Sprintf("%24s%s", "", version))
+	fmt.Println("  ╚═══════════════════════════════════════════════════════════╝")
+	fmt.Println()
+}
+
+// 询问是否选择（y/n），支持默认值
+func askYesNo(question string, defaultYes bool) bool {
+	defaultStr := "Y/n"
+	if !defaultYes {
+		defaultStr = "y/N"
+	}
+
+	fmt.Printf("%s [%s]: ", question, defaultStr)
+	input := strings.ToLower(strings.TrimSpace(getStringInput()))
+
+	if input == "" {
+		return defaultYes
+	}
+
+	return input == "y" || input == "yes"
+}
+
+// 获取本机IP地址
+func getLocalIP(ctx context.Context, client scanner.HTTPClient) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// 使用ipify.org API获取公网IP
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.ipify.org/", nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取公网IP失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	ip := strings.TrimSpace(string(body))
+
+	// 验证返回的是否为有效IP地址
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return "", fmt.Errorf("返回的不是有效的IP地址: %s", ip)
+	}
+
+	return ip, nil
+}
+
+// skipHost 返回跳过目标时修正进度统计的回调，每个目标按扫描的端口数计入跳过的结果
+func skipHost(processor *output.ResultProcessor, config *Config) func(scanner.Host) {
+	return func(host scanner.Host) {
+		if host.Port > 0 {
+			processor.Stats().Skip(1)
+		} else {
+			processor.Stats().Skip(len(config.Ports))
+		}
+	}
+}
+
+// maxASNReport 扫描结束时列出的低产ASN数
+const maxASNReport = 10
+
+// logASNBlocklist 输出低产ASN的统计和跳过、延后的目标数
+func logASNBlocklist(blocklist *scanner.ASNBlocklist) {
+	blocked := blocklist.Blocked()
+	if len(blocked) == 0 {
+		return
+	}
+
+	skipped, deferred := blocklist.Skipped()
+	logger.Info("以下ASN没有符合条件的目标", "asns", len(blocked), "skipped", skipped, "deferred", deferred)
+	for _, stats := range blocked[:min(len(blocked), maxASNReport)] {
+		logger.Info(fmt.Sprintf("  AS%d %s", stats.ASN, stats.Org),
+			"results", stats.Results, "failures", stats.Failures, "cdn", stats.CDN)
+	}
+}
+
+// newScanner 按命令行配置创建扫描器，扫描和结果查看器中的重新测试共用
+func newScanner(config *Config, geoDB *geo.Geo) *scanner.Scanner {
+	return scanner.New(scannerConfig(config), geoDB)
+}
+
+// newHTTPClient 按命令行配置创建辅助请求的HTTP客户端，经由proxy代理（为空时使用环境变量），dialer不为nil时经由其连接
+func newHTTPClient(config *Config, proxy string, dialer scanner.Dialer) (*httpclient.Client, error) {
+	opts := httpclient.Options{
+		Timeout:   config.HTTPTimeout,
+		Proxy:     proxy,
+		Retries:   config.HTTPRetries,
+		UserAgent: config.UserAgent,
+	}
+	if dialer != nil {
+		opts.Dial = dialer.DialContext
+	}
+	client, err := httpclient.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("无效的HTTP代理: %v", err)
+	}
+	return client, nil
+}
+
+// newChecker 按命令行配置创建可行性检查器
+func newChecker(config *Config) *feasibility.Checker {
+	return &feasibility.Checker{
+		PingDomain: config.PingDomain,
+		Filter:     config.Filter,
+		Countries:  config.Countries,
+		HTTPClient: config.HTTPClient,
+		Cache:      config.PostChecks,
+	}
+}
+
+// scannerConfig 由命令行配置生成扫描器配置
+func scannerConfig(config *Config) *scanner.Config {
+	checker := newChecker(config)
+	scanCfg := scanner.DefaultConfig()
+	scanCfg.Ports = config.Ports
+	scanCfg.DiscoverPorts = config.DiscoverPorts
+	scanCfg.Thread = config.Thread
+	scanCfg.Timeout = config.Timeout
+	scanCfg.IPv6 = config.IPv6
+	scanCfg.VerifySNI = config.VerifySNI
+	scanCfg.ProbeMTU = config.ProbeMTU
+	scanCfg.HelloProfile = config.Hello
+	scanCfg.TCPPings = config.TCPPings
+	scanCfg.ProbeTTL = config.ProbeTTL
+	scanCfg.DetectInterference = config.Middlebox
+	if config.hasTargets() {
+		// 目标清单中的域名较多，提前解析避免DNS延迟占用扫描线程
+		scanCfg.ResolveWorkers = config.ResolveWorkers
+	}
+	if config.ProbeDelay > 0 || config.Rate > 0 || config.SubnetRate > 0 || config.StartJitter > 0 {
+		scanCfg.Stealth = &scanner.Stealth{
+			Delay:       config.ProbeDelay,
+			Rate:        config.Rate,
+			SubnetRate:  config.SubnetRate,
+			StartJitter: config.StartJitter,
+		}
+	}
+	scanCfg.Exclude = config.Exclude
+	scanCfg.Capture = config.Capture
+	if config.Egress != nil {
+		scanCfg.Dialer = config.Egress
+	}
+	scanCfg.HTTPClient = config.HTTPClient
+	for _, spec := range config.DoHRegions {
+		// 参数已在parseFlags中检查过
+		region, _ := scanner.ParseRegionResolver(spec, scanCfg.HTTPClient)
+		scanCfg.Regions = append(scanCfg.Regions, region)
+	}
+	scanCfg.Judge = checker.Feasible
+	return scanCfg
+}
+
+// 实际的扫描函数
+// 扫描过程中按Ctrl+C或收到SIGTERM会取消ctx，停止所有扫描任务并输出已有结果
+func runScan(ctx context.Context, config *Config, source *targetSource) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("正在初始化扫描...")
+	// 调试日志（默认写入日志文件）记录构建信息，便于将结果和问题对应到具体版本
+	logger.Debug("构建信息", "version", versionString())
+
+	geoDB := loadGeoDB(ctx, config)
+	defer func() {
+		if geoDB != nil {
+			geoDB.Close()
+		}
+	}()
+
+	// 扫描线程只进行不需要网络请求的检查，CDN检测和连通性检测由后置检查阶段进行
+	checker := newChecker(config)
+	checker.Defer = true
+	scanCfg := scannerConfig(config)
+	scanCfg.Judge = checker.Feasible
+	s := scanner.New(scanCfg, geoDB)
+
+	// 每个端口产生一条结果，目标总数为0表示无限扫描
+	totalTargets := source.results(len(config.Ports))
+	if len(config.Ports) > 1 {
+		logger.Info("每个目标将扫描多个端口", "ports", config.Ports)
+	}
+
+	// 停止条件满足时取消ctx，目标生成随之停止；扫描线程和后续各阶段使用baseCtx，
+	// 已开始的握手和检查在停止后仍会完成并写入结果，只有用户中断时才放弃
+	baseCtx := ctx
+	ctx, stopper := scanner.NewStopper(ctx, config.Stop, totalTargets)
+	defer stopper.Stop(context.Canceled)
+
+	hostChan := source.hosts(ctx)
+	if source.save != nil {
+		// 定期保存扫描进度，结束时再保存一次
+		saveDone := make(chan struct{})
+		go func() {
+			ticker := time.NewTicker(output.SyncInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					source.save()
+				case <-saveDone:
+					return
+				}
+			}
+		}()
+		defer func() {
+			close(saveDone)
+			source.save()
+		}()
+	}
+
+	// 创建带进度条的结果处理器
+	processor, err := output.NewResultProcessorWithProgress(config.Output, totalTargets)
+	if err != nil {
+		return fmt.Errorf("创建结果处理器失败: %v", err)
+	}
+	defer func() {
+		// 关闭时将临时结果文件落盘并重命名为最终文件
+		if err := processor.Close(); err != nil {
+			logger.Error("保存结果文件失败", "error", err)
+		}
+	}()
+
+	processor.SetStopper(stopper)
+
+	// 配置远程收集端
+	if config.CollectorURL != "" {
+		collector, err := output.NewCollector(config.CollectorURL, config.CollectorHeaders, config.CollectorNode, config.CollectorBatch)
+		if err != nil {
+			return fmt.Errorf("创建收集端推送器失败: %v", err)
+		}
+		processor.AddSink(collector)
+		logger.Info("结果将推送到收集端", "url", config.CollectorURL)
+	}
+
+	// 配置消息通道发布
+	if config.PublishURL != "" {
+		publisher, err := output.NewPublisher(config.PublishURL)
+		if err != nil {
+			return fmt.Errorf("创建发布器失败: %v", err)
+		}
+		processor.AddSink(publisher)
+		logger.Info("符合条件的结果将发布到消息通道", "channel", publisher.String())
+	}
+
+	// 管道模式：结果流式写入标准输出
+	if config.Stdout != "" {
+		stream, err := output.NewStreamWriter(config.StdoutFile, config.Stdout)
+		if err != nil {
+			return err
+		}
+		processor.AddSink(stream)
+	}
+
+	// 配置钩子命令
+	if config.OnFeasible != "" {
+		hook, err := output.NewHookRunner(config.OnFeasible)
+		if err != nil {
+			return fmt.Errorf("创建钩子命令失败: %v", err)
+		}
+		processor.AddSink(hook)
+	}
+
+	// 配置提示音
+	if config.Alert != "" {
+		alert, err := output.NewAlert(config.Alert)
+		if err != nil {
+			return err
+		}
+		processor.AddSink(alert)
+	}
+
+	// 扫描IP段时命中目标的周边地址插队优先扫描
+	var neighborhood *scanner.Neighborhood
+	if config.Neighborhood > 0 && !config.hasTargets() {
+		neighborhood, _ = scanner.NewNeighborhood(config.Neighborhood)
+		hostChan = neighborhood.Prioritize(ctx, hostChan)
+		logger.Info("命中后将优先扫描周边网段", "prefix", fmt.Sprintf("/%d", config.Neighborhood))
+	}
+
+	// 握手前跳过排除名单中的IP目标，跳过的目标计入进度
+	if config.Exclude != nil {
+		exclude := *config.Exclude
+		exclude.OnSkip = skipHost(processor, config)
+		hostChan = exclude.Filter(ctx, hostChan)
+		logger.Info("已加载排除名单", "prefixes", len(exclude.Prefixes))
+	}
+
+	// 握手前跳过不在国家名单中的IP目标，跳过的目标计入进度
+	if len(config.Countries) > 0 {
+		if geoDB != nil {
+			geoFilter := scanner.NewGeoFilter(geoDB, config.Countries)
+			geoFilter.OnSkip = skipHost(processor, config)
+			hostChan = geoFilter.Filter(ctx, hostChan)
+			logger.Info("按地理位置预过滤目标", "countries", config.Countries)
+		} else {
+			logger.Warn("未加载地理位置数据库，无法在握手前按国家过滤")
+		}
+	}
+
+	// 握手前跳过反向解析为CDN边缘节点或云负载均衡的IP目标
+	if config.PTRFilter {
+		ptrFilter := &scanner.PTRFilter{
+			Resolver: net.DefaultResolver,
+			Workers:  config.Thread,
+			OnSkip:   skipHost(processor, config),
+		}
+		hostChan = ptrFilter.Filter(ctx, hostChan)
+		logger.Info("握手前将通过反向解析跳过CDN和负载均衡地址")
+	}
+
+	// 扫描IP段时跳过无响应的/24，跳过的目标计入进度
+	var deadSubnets *scanner.DeadSubnets
+	if config.SkipDead > 0 && !config.hasTargets() {
+		deadSubnets = scanner.NewDeadSubnets(config.SkipDead)
+		deadSubnets.OnSkip = skipHost(processor, config)
+		hostChan = deadSubnets.Filter(ctx, hostChan)
+	}
+
+	// 扫描IP段时跳过或延后没有产出的ASN
+	var asnBlocklist *scanner.ASNBlocklist
+	if config.ASNBlock > 0 && !config.hasTargets() {
+		if geoDB != nil && !geoDB.ASNBuildTime().IsZero() {
+			asnBlocklist = scanner.NewASNBlocklist(geoDB, config.ASNBlock)
+			asnBlocklist.Defer = config.ASNDefer
+			asnBlocklist.OnSkip = skipHost(processor, config)
+			hostChan = asnBlocklist.Filter(ctx, hostChan)
+		} else {
+			logger.Warn("未加载ASN数据库，无法按ASN调整扫描")
+		}
+	}
+
+	// 全屏界面接管状态显示和终端日志，支持暂停分发新目标
+	var ui *tui.UI
+	if config.TUI {
+		pauser := &scanner.Pauser{}
+		hostChan = pauser.Filter(ctx, hostChan)
+		ui = tui.New(tui.Options{
+			Stats:  processor.Stats(),
+			Pauser: pauser,
+			Stop:   func() { stopper.Stop(context.Canceled) },
+		})
+		processor.AddSink(ui)
+		processor.SetQuiet(true)
+		logging.SetTerminal(ui)
+		ui.Start()
+	}
+
+	// 不使用全屏界面时在终端底部固定状态行，日志在其上方滚动，不再与状态交错
+	if ui == nil && config.StatusLine && !config.NoBanner {
+		if status := console.NewStatusLine(os.Stdout); status != nil {
+			processor.SetStatusLine(status)
+			logging.SetTerminal(status)
+			defer logging.SetTerminal(os.Stdout)
+		}
+	}
+
+	// 启动并发扫描，停止条件触发后不再分发新目标，各阶段读完剩余结果后依次关闭
+	resultChan := s.ScanWithConcurrency(baseCtx, scanner.Feed(ctx, hostChan))
+	workers := config.PostCheckWorkers
+	if workers <= 0 {
+		workers = max(config.Thread/4, 1)
+	}
+	postChecker := &feasibility.PostChecker{Checker: checker, Workers: workers}
+	resultChan = postChecker.Watch(baseCtx, resultChan)
+	if neighborhood != nil {
+		resultChan = neighborhood.Watch(resultChan)
+	}
+	if deadSubnets != nil {
+		resultChan = deadSubnets.Watch(resultChan)
+	}
+	if asnBlocklist != nil {
+		resultChan = asnBlocklist.Watch(resultChan)
+	}
+
+	// 从各观测点重新测试符合条件的目标，结果写入前完成
+	var prober *scanner.VantageProber
+	if len(config.Vantages) > 0 {
+		vantages, closeVantages, err := startVantages(baseCtx, config, geoDB)
+		if err != nil {
+			return err
+		}
+		defer closeVantages()
+		prober = &scanner.VantageProber{
+			Vantages:    vantages,
+			Workers:     max(config.Thread/4, 1),
+			MinFeasible: config.VantageMin,
+			MaxSpread:   config.VantageSpread,
+		}
+		resultChan = prober.Probe(baseCtx, resultChan)
+	}
+
+	// 排除疑似蜜罐和sinkhole的目标
+	var honeypots *feasibility.HoneypotDetector
+	if config.HoneypotCheck {
+		honeypots = &feasibility.HoneypotDetector{Dialer: config.Egress, Workers: max(config.Thread/4, 1)}
+		resultChan = honeypots.Watch(baseCtx, resultChan)
+	}
+
+	// 评定符合条件的目标的可信度
+	var grader *feasibility.ConfidenceGrader
+	if config.Confidence {
+		grader = &feasibility.ConfidenceGrader{
+			Dialer:     config.Egress,
+			HTTPClient: config.HTTPClient,
+			Cache:      config.PostChecks,
+			Workers:    max(config.Thread/4, 1),
+			MinGrade:   config.MinConfidence,
+		}
+		resultChan = grader.Watch(baseCtx, resultChan)
+	}
+
+	// 与上次扫描记录的证书比较，在结果写入前标记证书发生变化的目标
+	var certPins *output.CertPins
+	if config.CertPins != "" {
+		if certPins, err = output.LoadCertPins(config.CertPins); err != nil {
+			logger.Warn("不记录本次扫描的证书", "error", err)
+		} else {
+			resultChan = certPins.Watch(baseCtx, resultChan)
+		}
+	}
+
+	// 记录到历史数据库
+	if config.HistoryDB != "" {
+		if history, err := output.OpenHistory(config.HistoryDB); err != nil {
+			logger.Warn("不记录本次扫描的历史", "error", err)
+		} else {
+			defer func() {
+				if err := history.Close(); err != nil {
+					logger.Warn(err.Error())
+				}
+			}()
+			resultChan = history.Watch(baseCtx, resultChan)
+		}
+	}
+
+	// 收集需要反查域名的IP目标
+	var enricher *reverseip.Enricher
+	if len(config.ReverseIP) > 0 {
+		enricher = &reverseip.Enricher{
+			Client:     config.HTTPClient,
+			Providers:  config.ReverseIP,
+			MaxLookups: config.ReverseIPLookups,
+			MaxDomains: reverseIPMaxDomains,
+		}
+		resultChan = enricher.Watch(resultChan)
+	}
+
+	// 收集需要跟踪路由的符合条件目标
+	var tracer *scanner.PathTracer
+	if config.Traceroute > 0 {
+		tracer = &scanner.PathTracer{MaxTargets: config.Traceroute}
+		resultChan = tracer.Watch(resultChan)
+	}
+
+	// 扫描结束时的桌面通知
+	var notifier *output.Notifier
+	if config.Notify || config.NotifyCmd != "" {
+		if notifier, err = output.NewNotifier(config.NotifyCmd); err != nil {
+			return err
+		}
+	}
+
+	// 机器可读的进度事件，结果处理结束时发送done事件
+	var progress *output.ProgressEmitter
+	if config.Progress != "" {
+		if progress, err = output.NewProgressEmitter(config.Progress, processor.Stats()); err != nil {
+			return err
+		}
+	}
+
+	// 处理结果
+	processor.ProcessResults(resultChan)
+	if progress != nil {
+		progress.Close()
+	}
+	// 用户中断时不通知
+	if notifier != nil && baseCtx.Err() == nil {
+		notifier.NotifyDone(baseCtx, processor.Stats().Snapshot(), stopper.Reason())
+	}
+	if ui != nil {
+		if err := ui.Wait(); err != nil {
+			logger.Warn("全屏界面异常退出", "error", err)
+		}
+		logging.SetTerminal(os.Stdout)
+		processor.PrintSummary()
+	}
+	if rejected := postChecker.Rejected(); rejected > 0 {
+		logger.Info("部分目标未通过CDN检测或连通性检测", "targets", rejected)
+	}
+	if prober != nil {
+		if rejected := prober.Rejected(); rejected > 0 {
+			logger.Info("部分目标未通过观测点共识，未写入结果", "targets", rejected)
+		}
+	}
+	if honeypots != nil {
+		if flagged := honeypots.Flagged(); flagged > 0 {
+			logger.Warn("部分目标疑似蜜罐或sinkhole，见SUSPICIOUS列", "targets", flagged)
+		}
+	}
+	if grader != nil {
+		if a, b, c := grader.Grades(); a+b+c > 0 {
+			logger.Info("可信度等级", "A", a, "B", b, "C", c)
+		}
+		if rejected := grader.Rejected(); rejected > 0 {
+			logger.Info("部分目标低于最低可信度等级，未写入结果", "targets", rejected, "min", config.MinConfidence)
+		}
+	}
+	if certPins != nil {
+		if changed := certPins.Changed(); changed > 0 {
+			logger.Warn("部分目标的证书自上次扫描后发生变化，见PREV_CERT_SHA256列", "targets", changed)
+		}
+		if err := certPins.Save(); err != nil {
+			logger.Warn(err.Error())
+		}
+	}
+	if metrics := s.Metrics(); metrics != nil {
+		output.PrintMetrics(metrics.Snapshot())
+	}
+	if stats := config.HTTPClient.Stats(); stats.Requests > 0 {
+		logger.Debug("辅助HTTP请求", "requests", stats.Requests, "retries", stats.Retries, "failures", stats.Failures)
+	}
+
+	if deadSubnets != nil {
+		if skipped, subnets := deadSubnets.Skipped(); skipped > 0 {
+			logger.Info("已跳过无响应网段中的地址", "subnets", subnets, "hosts", skipped)
+		}
+	}
+
+	if asnBlocklist != nil {
+		logASNBlocklist(asnBlocklist)
+	}
+
+	// 扫描正常结束（未取消、未触发停止条件）时验证反查得到的候选域名
+	if enricher != nil && ctx.Err() == nil {
+		verifyReverseIP(ctx, s, enricher, processor)
+	}
+
+	// 结束目标生成；用户中断时结果处理提前结束，读完各阶段中剩余的结果，历史数据库等在此之后才关闭
+	stopper.Stop(context.Canceled)
+	for range resultChan {
+	}
+
+	// 用户未中断时跟踪到符合条件目标的路由
+	if tracer != nil && baseCtx.Err() == nil {
+		traceFeasible(baseCtx, tracer, config.Output+output.TracerouteSuffix)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// runMainMenu 显示主菜单，查看、导出和验证已有结果时不必经过完整的扫描问答
+func runMainMenu(config *Config) {
+	for {
+		showTitle()
+		fmt.Println("  1. 新建扫描")
+		fmt.Println("  2. 查看历史结果")
+		fmt.Println("  3. 导出配置")
+		fmt.Println("  4. 验证目标")
+		fmt.Println("  5. 设置")
+		fmt.Println("  0. 退出")
+		fmt.Print("\n请选择 (默认: 1): ")
+
+		switch getStringInput() {
+		case "", "1":
+			// 每次扫描使用设置的副本，问答中的选择不影响下一次扫描
+			scanConfig := *config
+			if err := runNewScan(&scanConfig); err != nil {
+				logger.Error("扫描失败", "error", err)
+				pause()
+			}
+		case "2":
+			viewConfig := *config
+			runViewer(&viewConfig, "")
+		case "3":
+			exportConfigMenu()
+			pause()
+		case "4":
+			if filename := chooseRecentOutput(); filename != "" {
+				verifyTargets(config, filename)
+				pause()
+			}
+		case "5":
+			settingsMenu(config)
+		case "0", "q", "Q":
+			return
+		default:
+			logger.Error("无效的选项")
+			pause()
+		}
+	}
+}
+
+// exportFormat 可导出的配置格式
+type exportFormat struct {
+	name  string // 格式名，用于 export 子命令和 serve 的 /api/export
+	label string // 菜单中显示的名称
+	file  string // 默认文件名
+	write func(w io.Writer, header []string, records [][]string)
+}
+
+// exportFormats 可导出的配置格式，菜单中按顺序编号
+var exportFormats = []exportFormat{
+	{name: "reality", label: "Reality配置", file: "reality_config.txt", write: output.WriteRealityConfig},
+	{name: "clash", label: "Clash配置", file: "clash_provider.yaml", write: output.WriteClashProvider},
+}
+
+// findExportFormat 按名称查找导出格式
+func findExportFormat(name string) (exportFormat, bool) {
+	for _, format := range exportFormats {
+		if format.name == strings.ToLower(name) {
+			return format, true
+		}
+	}
+	return exportFormat{}, false
+}
+
+// exportConfigMenu 从最近的结果文件导出Reality或Clash.Meta配置
+func exportConfigMenu() {
+	filename := chooseRecentOutput()
+	if filename == "" {
+		return
+	}
+
+	var choices []string
+	for i, format := range exportFormats {
+		choices = append(choices, fmt.Sprintf("[%d] %s", i+1, format.label))
+	}
+	fmt.Printf("导出格式 %s: ", strings.Join(choices, "  "))
+	choice, err := strconv.Atoi(getStringInput())
+	if err != nil || choice < 1 || choice > len(exportFormats) {
+		logger.Error("无效的格式")
+		return
+	}
+	format := exportFormats[choice-1]
+	path := format.file
+	fmt.Printf("请输入文件名 (默认: %s): ", path)
+	if input := getStringInput(); input != "" {
+		path = input
+	}
+
+	if err := exportConfig(filename, format, path); err != nil {
+		logger.Error("导出失败", "error", err)
+	}
+}
+
+// settingsMenu 修改本次运行中扫描使用的常用设置
+func settingsMenu(config *Config) {
+	for {
+		console.ClearScreen()
+		fmt.Println("设置（仅对本次运行有效）:")
+		fmt.Printf("  1. 并发线程数: %d\n", config.Thread)
+		fmt.Printf("  2. 连接超时(秒): %d\n", config.Timeout)
+		fmt.Printf("  3. 扫描端口: %s\n", formatPorts(config.Ports))
+		fmt.Printf("  4. 结果文件: %s\n", config.Output)
+		fmt.Printf("  5. 每页显示的目标数: %d\n", config.PageSize)
+		fmt.Println("  0. 返回")
+		fmt.Print("\n请选择: ")
+
+		choice := getStringInput()
+		if choice == "" || choice == "0" {
+			return
+		}
+		fmt.Print("请输入新的值: ")
+		value := getStringInput()
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch choice {
+		case "1":
+			err = setPositiveInt(&config.Thread, value, 1000)
+		case "2":
+			err = setPositiveInt(&config.Timeout, value, 300)
+		case "3":
+			var ports []int
+			if ports, err = scanner.ParsePorts(value); err == nil {
+				config.Ports = ports
+			}
+		case "4":
+			config.Output = value
+		case "5":
+			err = setPositiveInt(&config.PageSize, value, 1000)
+		default:
+			err = fmt.Errorf("无效的选项")
+		}
+		if err != nil {
+			logger.Error("设置失败", "error", err)
+			pause()
+		}
+	}
+}
+
+// setPositiveInt 解析1到limit之间的整数并写入target
+func setPositiveInt(target *int, value string, limit int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 || n > limit {
+		return fmt.Errorf("请输入1-%d之间的整数", limit)
+	}
+	*target = n
+	return nil
+}
+
+// formatPorts 返回逗号分隔的端口列表
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/mockserver"
+)
+
+// hiddenFlags 不在帮助中显示的参数，仅用于测试
+var hiddenFlags = map[string]bool{"mock-server": true}
+
+// usage 输出帮助，省略hiddenFlags中的参数
+func usage() {
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+			visible.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "用法: %s [参数] [子命令 [子命令参数]]\n\n", os.Args[0])
+	printCommands(out)
+	fmt.Fprintf(out, "\n参数:\n")
+	visible.PrintDefaults()
+}
+
+// startMockServers 按--mock-server启动本地TLS测试服务器，任一服务器启动失败时关闭已启动的服务器
+func startMockServers(config *Config) ([]*mockserver.Server, error) {
+	var servers []*mockserver.Server
+	for _, opts := range config.Mocks {
+		server, err := mockserver.Start(opts)
+		if err != nil {
+			mockserver.CloseAll(servers)
+			return nil, err
+		}
+		domains := opts.Domains
+		if len(domains) == 0 {
+			domains = []string{mockserver.DefaultDomain}
+		}
+		logger.Info("🧪 测试服务器已启动", "addr", server.Addr, "domains", strings.Join(domains, ","), "cdn", opts.CDN)
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// waitMockServers 只指定了测试服务器、没有扫描目标时保持服务器运行，直到收到中断信号
+func waitMockServers() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logger.Info("测试服务器运行中，按 Ctrl+C 退出")
+	<-ctx.Done()
+}
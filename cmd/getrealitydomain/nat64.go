@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// nat64Auto --nat64 的取值，表示通过DNS64发现NAT64前缀
+const nat64Auto = "auto"
+
+// startNAT64 设置了--nat64时确定NAT64前缀，之后创建的扫描器、可行性检查和后置检查经由NAT64连接IPv4目标
+func startNAT64(ctx context.Context, config *Config) error {
+	if config.NAT64 == "" {
+		return nil
+	}
+
+	prefix := config.NAT64Prefix
+	if config.NAT64 == nat64Auto {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		detected, err := scanner.DetectNAT64(ctx, net.DefaultResolver)
+		if err != nil {
+			return err
+		}
+		prefix = detected
+	}
+
+	dialer := &scanner.NAT64Dialer{Prefix: prefix}
+	client, err := newHTTPClient(config, config.HTTPProxy, dialer)
+	if err != nil {
+		return err
+	}
+	config.Egress, config.HTTPClient = dialer, client
+	logger.Info("🌐 IPv4目标将经由NAT64连接", "prefix", prefix)
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// excludeFile 低调配置自动使用的排除名单，位于用户配置目录
+const excludeFile = "exclude.txt"
+
+// applyProfile 按扫描配置设置命令行中未指定的限速参数，并读取排除名单
+func (c *Config) applyProfile() error {
+	switch c.Profile {
+	case "":
+	case "stealth", "低调":
+		defaults := scanner.DefaultStealth()
+		if !flagPassed("probe-delay") {
+			c.ProbeDelay = defaults.Delay
+		}
+		if !flagPassed("rate") {
+			c.Rate = defaults.Rate
+		}
+		if !flagPassed("subnet-rate") {
+			c.SubnetRate = defaults.SubnetRate
+		}
+		if !flagPassed("start-jitter") {
+			c.StartJitter = defaults.StartJitter
+		}
+		if path := userConfigPath(excludeFile); !slices.Contains(c.ExcludeFiles, path) {
+			if _, err := os.Stat(path); err == nil {
+				c.ExcludeFiles = append(c.ExcludeFiles, path)
+			}
+		}
+	default:
+		return fmt.Errorf("未知的扫描配置: %s（可选 stealth）", c.Profile)
+	}
+
+	if c.ProbeDelay < 0 || c.Rate < 0 || c.SubnetRate < 0 || c.StartJitter < 0 {
+		return fmt.Errorf("限速参数不能为负数")
+	}
+	return c.loadExclusions()
+}
+
+// loadExclusions 读取并合并所有排除名单
+func (c *Config) loadExclusions() error {
+	for _, path := range c.ExcludeFiles {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("读取排除名单失败: %v", err)
+		}
+		exclusions, err := scanner.ParseExclusions(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if c.Exclude == nil {
+			c.Exclude = &scanner.Exclusions{}
+		}
+		c.Exclude.Prefixes = append(c.Exclude.Prefixes, exclusions.Prefixes...)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+)
+
+// maxRecentOutputs 记录的最近结果文件数
+const maxRecentOutputs = 10
+
+// recentPath 返回最近结果文件列表的路径
+func recentPath() string {
+	return userConfigPath("recent.json")
+}
+
+// loadRecentOutputs 读取最近的结果文件列表（最新的在前），跳过已不存在的文件
+func loadRecentOutputs() []string {
+	data, err := os.ReadFile(recentPath())
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		logger.Warn("解析最近结果文件列表失败", "error", err)
+		return nil
+	}
+
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+// rememberOutput 将结果文件加入最近列表，供 view 命令选择
+func rememberOutput(filename string) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return
+	}
+
+	paths := []string{path}
+	for _, recent := range loadRecentOutputs() {
+		if recent != path && len(paths) < maxRecentOutputs {
+			paths = append(paths, recent)
+		}
+	}
+
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(recentPath()), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(recentPath(), data, 0644)
+	}
+	if err != nil {
+		logger.Debug("保存最近结果文件列表失败", "error", err)
+	}
+}
+
+// chooseRecentOutput 列出最近的结果文件供用户选择，没有记录或用户放弃时返回空字符串
+func chooseRecentOutput() string {
+	paths := loadRecentOutputs()
+	if len(paths) == 0 {
+		logger.Error("没有最近的结果文件，请指定要查看的文件，如: view out.csv")
+		return ""
+	}
+
+	fmt.Println("最近的结果文件:")
+	for i, path := range paths {
+		modified := ""
+		if info, err := os.Stat(path); err == nil {
+			modified = info.ModTime().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("  %s %s  %s\n", console.PadRight(strconv.Itoa(i+1), 4), console.Dim(modified), path)
+	}
+	fmt.Print("请选择结果文件 (留空返回): ")
+
+	input := getStringInput()
+	if input == "" {
+		return ""
+	}
+	index, err := strconv.Atoi(input)
+	if err != nil || index < 1 || index > len(paths) {
+		logger.Error("无效的序号")
+		return ""
+	}
+	return paths[index-1]
+}
+
+// runViewer 打开指定的结果文件，未指定时从最近的结果文件中选择
+func runViewer(config *Config, filename string) {
+	if filename == "" {
+		if filename = chooseRecentOutput(); filename == "" {
+			return
+		}
+	}
+	if _, err := os.Stat(filename); err != nil {
+		logger.Error("无法打开结果文件", "error", err)
+		return
+	}
+
+	config.Output = filename
+	rememberOutput(filename)
+	showResultsPaginated(config)
+}
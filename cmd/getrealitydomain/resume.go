@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// expansionSuffix 无限扫描进度文件相对于输出文件的后缀
+const expansionSuffix = ".expansion.json"
+
+// expansionRecord 单个种子IP已保存的扩展进度
+type expansionRecord struct {
+	Low     uint64    `json:"low"`  // 已向下扩展的地址数
+	High    uint64    `json:"high"` // 已向上扩展的地址数
+	Updated time.Time `json:"updated"`
+}
+
+// expansionStore 无限扫描进度文件，按种子IP（含端口后缀）记录扩展进度
+type expansionStore struct {
+	path    string
+	records map[string]expansionRecord
+}
+
+// loadExpansionStore 读取进度文件，文件不存在时返回空记录
+func loadExpansionStore(path string) (*expansionStore, error) {
+	store := &expansionStore{path: path, records: make(map[string]expansionRecord)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取扫描进度失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.records); err != nil {
+		return nil, fmt.Errorf("解析扫描进度失败: %v", err)
+	}
+	return store, nil
+}
+
+// save 记录种子IP的当前进度并写入文件，先写临时文件再重命名，避免中断时损坏进度文件
+func (st *expansionStore) save(seed string, progress *scanner.Expansion) error {
+	low, high := progress.Offsets()
+	st.records[seed] = expansionRecord{Low: low, High: high, Updated: time.Now()}
+
+	data, err := json.MarshalIndent(st.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存扫描进度失败: %v", err)
+	}
+	if err := os.Rename(tmp, st.path); err != nil {
+		return fmt.Errorf("保存扫描进度失败: %v", err)
+	}
+	return nil
+}
+
+// resumeExpansion 读取种子IP上次的扩展进度，overlap为回退的地址数：
+// 进度按已交给扫描协程的目标记录，中断时仍在排队或扫描中的目标需要重新扫描
+func resumeExpansion(store *expansionStore, seed string, overlap uint64) *scanner.Expansion {
+	record, ok := store.records[seed]
+	if !ok {
+		return scanner.NewExpansion(0, 0)
+	}
+	rewind := func(n uint64) uint64 {
+		if n <= overlap {
+			// 该方向回退到种子IP时从种子IP开始
+			return 0
+		}
+		return n - overlap
+	}
+	return scanner.NewExpansion(rewind(record.Low), rewind(record.High))
+}
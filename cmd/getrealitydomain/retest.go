@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// retester 在结果查看器中对单个目标立即重新进行完整的握手和可行性判断
+type retester struct {
+	config  *Config
+	scanner *scanner.Scanner
+	geoDB   *geo.Geo
+}
+
+// run 重新测试记录对应的目标并与文件中的记录对比显示，用户确认后写回结果文件，返回文件是否已更新
+func (r *retester) run(header, record []string) bool {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	host, err := retestHost(header, record)
+	if err != nil {
+		logger.Error("无法重新测试该目标", "error", err)
+		return false
+	}
+	if r.scanner == nil {
+		r.geoDB = loadGeoDB(ctx, r.config)
+		r.scanner = newScanner(r.config, r.geoDB)
+	}
+
+	logger.Info("正在重新测试...", "ip", host.IP, "port", host.Port)
+	results := make(chan scanner.Result, 1)
+	go func() {
+		defer close(results)
+		r.scanner.ScanTLS(ctx, host, results)
+	}()
+	result, ok := <-results
+	if !ok {
+		logger.Warn("重新测试已取消")
+		return false
+	}
+
+	showRetestResult(header, record, result)
+	fmt.Print("\n[W] 用本次结果更新结果文件  回车返回: ")
+	if !strings.EqualFold(getStringInput(), "W") {
+		return false
+	}
+	found, err := output.ReplaceResult(r.config.Output, result)
+	if err != nil {
+		logger.Error("更新结果文件失败", "error", err)
+		return false
+	}
+	if !found {
+		logger.Warn("结果文件中没有找到该目标的记录")
+		return false
+	}
+	logging.Success(logger, "结果文件已更新", "file", r.config.Output)
+	return true
+}
+
+// close 关闭重新测试时加载的地理位置数据库
+func (r *retester) close() {
+	if r.geoDB != nil {
+		r.geoDB.Close()
+	}
+}
+
+// retestHost 由结果记录构造扫描目标，只扫描记录中的端口，域名目标沿用原来的SNI
+func retestHost(header, record []string) (scanner.Host, error) {
+	ip, err := netip.ParseAddr(columnValue(header, record, "IP"))
+	if err != nil {
+		return scanner.Host{}, fmt.Errorf("无效的IP地址: %v", err)
+	}
+	port, err := strconv.Atoi(columnValue(header, record, "PORT"))
+	if err != nil {
+		return scanner.Host{}, fmt.Errorf("无效的端口: %v", err)
+	}
+	return scanner.Host{
+		IP:     ip,
+		Origin: columnValue(header, record, "ORIGIN"),
+		Type:   scanner.HostTypeIP,
+		Port:   port,
+		SNI:    columnValue(header, record, "SNI"),
+	}, nil
+}
+
+// showRetestResult 对比显示文件中的记录和本次测试的结果
+func showRetestResult(header, record []string, result scanner.Result) {
+	responseTime := strconv.FormatInt(result.ResponseTime, 10)
+	rows := [][3]string{
+		{"符合条件", columnValue(header, record, "FEASIBLE"), strconv.FormatBool(result.Feasible)},
+		{"响应时间(ms)", columnValue(header, record, "RESPONSE_TIME_MS"), responseTime},
+		{"TLS版本", columnValue(header, record, "TLS_VERSION"), result.TLSVersion},
+		{"证书域名", columnValue(header, record, "CERT_DOMAIN"), result.CertDomain},
+		{"证书颁发者", columnValue(header, record, "CERT_ISSUER"), result.CertIssuer},
+		{"国家/地区", columnValue(header, record, "GEO_CODE"), result.GeoCode},
+		{"错误", columnValue(header, record, "ERROR"), result.Error},
+	}
+
+	fmt.Printf("\n%s %s %s\n", console.PadRight("", 14), console.PadRight("文件中的记录", 32), "本次测试")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, row := range rows {
+		current := row[2]
+		if current != row[1] {
+			current = console.Highlight(current)
+		}
+		fmt.Printf("%s %s %s\n", console.PadRight(row[0], 14),
+			console.PadRight(console.Truncate(row[1], 32), 32), current)
+	}
+}
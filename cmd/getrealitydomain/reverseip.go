@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/reverseip"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// reverseIPMaxDomains 每个IP最多验证的反查域名数
+const reverseIPMaxDomains = 20
+
+// verifyReverseIP 反查证书为空或通用证书的IP目标，以发现的域名为SNI重新握手并进行可行性判断
+// 验证结果与主扫描写入同一个结果文件，ORIGIN列记录反查服务
+func verifyReverseIP(ctx context.Context, s *scanner.Scanner, enricher *reverseip.Enricher, processor *output.ResultProcessor) {
+	if enricher.Candidates() == 0 {
+		return
+	}
+
+	logger.Info("正在反查IP上托管的域名...", "ips", enricher.Candidates())
+	hosts := enricher.Targets(ctx)
+	if len(hosts) == 0 {
+		logger.Info("反查没有发现候选域名")
+		return
+	}
+
+	logger.Info("正在验证反查得到的候选域名...", "targets", len(hosts))
+	before := processor.Stats().Snapshot().Feasible
+	for result := range s.ScanWithConcurrency(ctx, scanner.IterateHosts(ctx, hosts)) {
+		if processor.Process(result) {
+			break
+		}
+		if result.Error == "" && result.Feasible {
+			fmt.Printf("✅ %s (%s) - %s [%dms]\n", result.IP, result.SNI, result.Origin, result.ResponseTime)
+		}
+	}
+
+	found := processor.Stats().Snapshot().Feasible - before
+	logging.Success(logger, "反查域名验证完成", "candidates", len(hosts), "feasible", found)
+}
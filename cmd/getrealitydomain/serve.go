@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// defaultServeAddr serve 的默认监听地址，只接受本机的请求
+const defaultServeAddr = "127.0.0.1:8080"
+
+// serveShutdownTimeout 收到中断信号后等待进行中的请求完成的时间
+const serveShutdownTimeout = 10 * time.Second
+
+// apiServer serve 提供的HTTP API，各接口共用一个扫描器；
+// 只能读写 --output 指定的结果文件和最近扫描的结果文件
+type apiServer struct {
+	config  *Config
+	scanner *scanner.Scanner
+}
+
+// runServeCommand serve [监听地址]：启动HTTP API，直到收到中断信号
+func runServeCommand(config *Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("用法: serve [监听地址]")
+	}
+	addr := defaultServeAddr
+	if len(args) == 1 {
+		addr = args[0]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	geoDB := loadGeoDB(ctx, config)
+	if geoDB != nil {
+		defer geoDB.Close()
+	}
+	api := &apiServer{config: config, scanner: newScanner(config, geoDB)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/check", api.check)
+	mux.HandleFunc("POST /api/verify", api.verify)
+	mux.HandleFunc("GET /api/results", api.results)
+	mux.HandleFunc("GET /api/export", api.export)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听失败: %v", err)
+	}
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logging.Success(logger, "HTTP API已启动，按Ctrl+C停止", "addr", "http://"+listener.Addr().String())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP服务异常退出: %v", err)
+	}
+	return nil
+}
+
+// check GET /api/check?target=IP[:端口]|域名[&sni=]：握手并进行完整的可行性判断，返回每个地址和端口的结果
+func (a *apiServer) check(w http.ResponseWriter, r *http.Request) {
+	host, err := scanner.ParseHost(r.URL.Query().Get("target"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if host.Type != scanner.HostTypeIP && host.Type != scanner.HostTypeDomain {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("只支持单个IP或域名: %s", host.Origin))
+		return
+	}
+	if sni := r.URL.Query().Get("sni"); sni != "" {
+		host.SNI = sni
+	}
+
+	results := make(chan scanner.Result, len(a.config.Ports))
+	go func() {
+		defer close(results)
+		a.scanner.ScanTLS(r.Context(), host, results)
+	}()
+	list := []scanner.Result{}
+	for result := range results {
+		list = append(list, result)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// verify POST /api/verify[?file=&write=true]：重新测试结果文件中的全部目标，write为true时用本次结果更新结果文件；
+// 写入只接受同源请求，避免其他网页借浏览器向本机的API提交请求改写结果文件
+func (a *apiServer) verify(w http.ResponseWriter, r *http.Request) {
+	write := r.URL.Query().Get("write") == "true"
+	if write {
+		if err := checkSameOrigin(r); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	}
+	filename, err := a.resultFile(r)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	results, err := retestFile(r.Context(), a.scanner, filename)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if write && len(results) > 0 {
+		if err := saveVerifyResults(filename, results); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if results == nil {
+		results = []scanner.Result{}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// results GET /api/results[?file=]：返回结果文件中符合条件的目标，键为小写的列名
+func (a *apiServer) results(w http.ResponseWriter, r *http.Request) {
+	filename, err := a.resultFile(r)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	header, records, err := output.LoadFeasibleTable(filename)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	objects := make([]map[string]string, len(records))
+	for i, record := range records {
+		objects[i] = output.RecordObject(header, record)
+	}
+	writeJSON(w, http.StatusOK, objects)
+}
+
+// export GET /api/export?format=reality|clash[&file=]：以纯文本返回导出的配置
+func (a *apiServer) export(w http.ResponseWriter, r *http.Request) {
+	format, ok := findExportFormat(r.URL.Query().Get("format"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("不支持的导出格式: %s", r.URL.Query().Get("format")))
+		return
+	}
+	filename, err := a.resultFile(r)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	header, records, err := output.LoadFeasibleTable(filename)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	format.write(w, header, records)
+}
+
+// resultFile 返回请求中的结果文件，未指定时为 --output；API只能访问该文件和最近扫描的结果文件
+func (a *apiServer) resultFile(r *http.Request) (string, error) {
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		return a.config.Output, nil
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	if defaultPath, _ := filepath.Abs(a.config.Output); path == defaultPath || slices.Contains(loadRecentOutputs(), path) {
+		return path, nil
+	}
+	return "", fmt.Errorf("只能访问 --output 指定的结果文件和最近扫描的结果文件: %s", filename)
+}
+
+// checkSameOrigin 检查请求是否来自API自身的页面或非浏览器客户端：Origin和Sec-Fetch-Site存在时须为同源，
+// Host须为IP地址或localhost，防止其他网站通过DNS重绑定把自己的域名指向本机后发出“同源”请求
+func checkSameOrigin(r *http.Request) error {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if _, err := netip.ParseAddr(strings.Trim(host, "[]")); err != nil && !strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("拒绝写入请求，Host须为IP地址或localhost: %s", r.Host)
+	}
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+		return fmt.Errorf("拒绝来自其他网站的写入请求: %s", site)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			return fmt.Errorf("拒绝来自其他网站的写入请求: %s", origin)
+		}
+	}
+	return nil
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("写入响应失败", "error", err)
+	}
+}
+
+// writeError 以 {"error": "..."} 写入错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	"golang.org/x/net/proxy"
+)
+
+// socksStartTimeout 等待外部进程的SOCKS端口可用的超时
+const socksStartTimeout = 20 * time.Second
+
+// socksProcess 在本地回环地址提供SOCKS5代理的外部进程，如ssh动态转发
+type socksProcess struct {
+	socks  string // 本地SOCKS5监听地址
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+// startProcess 分配本地端口，以该端口为SOCKS5监听地址启动command创建的进程，等待端口可用
+func (p *socksProcess) startProcess(ctx context.Context, name string, command func(socks string) (*exec.Cmd, error)) error {
+	// 先占用一个空闲端口再释放，交给外部进程监听
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("分配本地端口失败: %v", err)
+	}
+	p.socks = listener.Addr().String()
+	listener.Close()
+
+	if p.cmd, err = command(p.socks); err != nil {
+		return err
+	}
+	p.cmd.Stderr = &p.stderr
+	if err := p.cmd.Start(); err != nil {
+		return fmt.Errorf("启动%s失败: %v", name, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- p.cmd.Wait() }()
+
+	deadline := time.NewTimer(socksStartTimeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			p.cmd = nil
+			return fmt.Errorf("%s已退出: %v %s", name, err, strings.TrimSpace(p.stderr.String()))
+		case <-deadline.C:
+			p.close()
+			return fmt.Errorf("等待%s的SOCKS端口超时", name)
+		case <-ctx.Done():
+			p.close()
+			return ctx.Err()
+		case <-ticker.C:
+			if conn, err := net.DialTimeout("tcp", p.socks, time.Second); err == nil {
+				conn.Close()
+				go func() { <-exited }()
+				return nil
+			}
+		}
+	}
+}
+
+// dialer 返回通过SOCKS5代理拨号的Dialer
+func (p *socksProcess) dialer() (scanner.Dialer, error) {
+	d, err := proxy.SOCKS5("tcp", p.socks, nil, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	contextDialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("SOCKS5拨号器不支持context")
+	}
+	return contextDialer, nil
+}
+
+// close 结束外部进程
+func (p *socksProcess) close() {
+	if p.cmd != nil && p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+		p.cmd = nil
+	}
+}
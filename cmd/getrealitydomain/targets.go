@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// targetSource 扫描目标来源
+type targetSource struct {
+	total int                                           // 扫描全局端口列表的目标数，0表示未知
+	fixed int                                           // 自带端口（如 example.com:8443）的目标数，每个只扫描一个端口
+	hosts func(ctx context.Context) <-chan scanner.Host // 创建目标迭代器，ctx取消时停止
+	save  func()                                        // 保存扫描进度，为nil时不需要保存；扫描中定期调用，结束时再调用一次
+}
+
+// results 返回预计产生的结果数，0表示未知
+func (src *targetSource) results(ports int) int {
+	return src.total*ports + src.fixed
+}
+
+// counted 根据目标是否自带端口设置目标数
+func (src *targetSource) counted(host scanner.Host, n int) *targetSource {
+	if host.Port > 0 {
+		src.fixed = n
+	} else {
+		src.total = n
+	}
+	return src
+}
+
+// addressSource 根据IP、CIDR、IP区间或单个域名创建目标来源
+// 设置了抽样数时从CIDR或IP区间中随机抽取该数量的地址，而不是扫描全部地址
+func addressSource(addr string, config *Config) (*targetSource, error) {
+	sample := config.Sample
+	host, err := scanner.ParseHost(addr)
+	if err != nil {
+		return nil, fmt.Errorf("解析地址失败: %v", err)
+	}
+	// 以规范化的形式展开，URL、方括号和末尾的点等输入形式不再需要重复解析
+	addr = withPort(host.Origin, host.Port)
+
+	if sample > 0 {
+		if host.Type == scanner.HostTypeCIDR || host.Type == scanner.HostTypeRange {
+			return sampleSource(addr, host, sample)
+		}
+		logger.Warn("抽样只适用于CIDR和IP区间，已忽略", "target", host.Origin)
+	}
+
+	switch host.Type {
+	case scanner.HostTypeIP:
+		// 单个IP的无限扫描模式
+		src, err := expansionSource(addr, config)
+		if err != nil {
+			return nil, err
+		}
+		// IPv6地址只扫描所在/64中的候选地址，目标数已知
+		if host.IP.Is6() {
+			candidates := len(scanner.IPv6Candidates(host.IP))
+			logger.Info("启动IPv6扩展扫描模式（扫描所在/64中常见的地址形式）", "candidates", candidates)
+			return src.counted(host, candidates), nil
+		}
+		logger.Info("启动无限扫描模式（从指定IP向上下扩展）")
+		return src, nil
+	case scanner.HostTypeCIDR:
+		// 目标数按掩码精确计算，地址边扫描边产生，大网段也不会预先展开
+		targets, size, err := scanner.CountCIDRTargets(addr)
+		if err != nil {
+			return nil, fmt.Errorf("解析CIDR失败: %v", err)
+		}
+		logger.Info("扫描CIDR网段", "cidr", host.Origin, "hosts", targets, "addresses", formatAddressCount(size))
+		return (&targetSource{
+			hosts: func(ctx context.Context) <-chan scanner.Host {
+				return scanner.IterateCIDR(ctx, addr)
+			},
+		}).counted(host, targets), nil
+	case scanner.HostTypeRange:
+		targets, size, err := scanner.CountRangeTargets(addr)
+		if err != nil {
+			return nil, fmt.Errorf("解析IP区间失败: %v", err)
+		}
+		logger.Info("扫描IP区间", "range", host.Origin, "hosts", targets, "addresses", formatAddressCount(size))
+		return (&targetSource{
+			hosts: func(ctx context.Context) <-chan scanner.Host {
+				return scanner.IterateRange(ctx, addr)
+			},
+		}).counted(host, targets), nil
+	default:
+		// 单个域名
+		return hostsSource([]scanner.Host{host}), nil
+	}
+}
+
+// expansionSource 无限扫描模式，进度按种子IP保存在输出文件旁的进度文件中；
+// 指定了 --resume 时从保存的进度继续，否则从种子IP重新开始
+func expansionSource(addr string, config *Config) (*targetSource, error) {
+	store, err := loadExpansionStore(config.Output + expansionSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	// 中断时排队中（通道缓冲）和扫描中（每个线程一个）的目标需要重新扫描，每个目标相隔步长个地址
+	seed := strings.TrimSpace(addr)
+	progress := scanner.NewExpansion(0, 0)
+	if config.Resume {
+		progress = resumeExpansion(store, seed, uint64(100+config.Thread)*uint64(max(config.Expand.Stride, 1)))
+	}
+	return &targetSource{
+		hosts: func(ctx context.Context) <-chan scanner.Host {
+			return scanner.IterateAddrFrom(ctx, addr, progress, config.Expand)
+		},
+		save: func() {
+			if err := store.save(seed, progress); err != nil {
+				logger.Warn(err.Error())
+			}
+		},
+	}, nil
+}
+
+// sampleSource 从CIDR或IP区间中随机抽样的目标来源
+func sampleSource(addr string, host scanner.Host, sample int) (*targetSource, error) {
+	targets, size, err := scanner.CountSampleTargets(addr, sample)
+	if err != nil {
+		return nil, fmt.Errorf("解析抽样目标失败: %v", err)
+	}
+	logger.Info("随机抽样扫描", "target", host.Origin, "hosts", targets, "addresses", formatAddressCount(size))
+	return (&targetSource{
+		hosts: func(ctx context.Context) <-chan scanner.Host {
+			return scanner.IterateSample(ctx, addr, sample)
+		},
+	}).counted(host, targets), nil
+}
+
+// hostsSource 根据已确定的目标列表创建目标来源
+func hostsSource(hosts []scanner.Host) *targetSource {
+	src := &targetSource{
+		hosts: func(ctx context.Context) <-chan scanner.Host {
+			return scanner.IterateHosts(ctx, hosts)
+		},
+	}
+	for _, host := range hosts {
+		if host.Port > 0 {
+			src.fixed++
+		} else {
+			src.total++
+		}
+	}
+	return src
+}
+
+// domainListSource 从文件读取候选域名列表（每行一个，#开头为注释），去重后作为扫描目标
+// 每个域名解析后以域名作为SNI握手，带端口后缀（如 example.com:8443）的域名只扫描该端口；
+// 行尾可附加 port=、sni=、country= 选项，指定SNI时也可以直接使用IP作为目标
+func domainListSource(path string) (*targetSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开域名列表失败: %v", err)
+	}
+	defer file.Close()
+
+	var hosts []scanner.Host
+	seen := make(map[scanner.Host]bool)
+	invalid := 0
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		host, err := scanner.ParseTarget(line)
+		if err == nil && host.Type == scanner.HostTypeIP && host.SNI == "" {
+			err = fmt.Errorf("IP目标需要指定sni选项")
+		} else if err == nil && host.Type != scanner.HostTypeDomain && host.Type != scanner.HostTypeIP {
+			err = fmt.Errorf("不支持的目标类型: %s", host.Type)
+		}
+		if err != nil {
+			invalid++
+			logger.Debug("跳过无效的域名", "line", line, "error", err)
+			continue
+		}
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("读取域名列表失败: %v", err)
+	}
+
+	if invalid > 0 {
+		logger.Warn("域名列表中有无效的行已跳过", "count", invalid)
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("域名列表为空: %s", path)
+	}
+
+	logger.Info("已加载域名列表", "path", path, "domains", len(hosts))
+	return hostsSource(hosts), nil
+}
+
+// targetsFileSource 从目标列表文件流式读取目标，见streamSource
+func targetsFileSource(path string) (*targetSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开目标列表失败: %v", err)
+	}
+	logger.Info("从目标列表读取扫描目标", "path", path)
+	return streamSource(file), nil
+}
+
+// streamSource 逐行读取目标（IP、CIDR、IP区间或域名，行尾可附加 port=、sni=、country= 选项），
+// 边读边展开CIDR和IP区间，重复的行只扫描一次，读取结束后关闭reader；不预先读完整个列表，目标数未知
+func streamSource(reader io.ReadCloser) *targetSource {
+	return &targetSource{
+		hosts: func(ctx context.Context) <-chan scanner.Host {
+			hosts := scanner.IterateUnique(ctx, reader)
+			out := make(chan scanner.Host)
+			// 通道关闭时已停止读取，可以关闭reader
+			go func() {
+				defer close(out)
+				defer reader.Close()
+				for host := range hosts {
+					select {
+					case out <- host:
+					case <-ctx.Done():
+					}
+				}
+			}()
+			return out
+		},
+	}
+}
+
+// urlSource 从页面中抓取域名作为扫描目标，结果的Origin记录页面地址
+func urlSource(ctx context.Context, client scanner.HTTPClient, pageURL string) (*targetSource, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	logger.Info("正在从页面抓取域名...", "url", pageURL)
+	cfg := scanner.DefaultConfig()
+	cfg.HTTPClient = client
+	domains, err := scanner.New(cfg, nil).FetchDomainsFromURL(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("页面中没有找到域名: %s", pageURL)
+	}
+
+	// 抓取结果已去重，排序使扫描顺序稳定
+	sort.Strings(domains)
+	hosts := make([]scanner.Host, len(domains))
+	for i, domain := range domains {
+		hosts[i] = scanner.Host{Origin: domain, Type: scanner.HostTypeDomain, Source: pageURL}
+	}
+
+	logger.Info("已抓取域名", "url", pageURL, "domains", len(hosts))
+	return hostsSource(hosts), nil
+}
+
+// hostsFileSource 从hosts格式的文件读取目标
+func hostsFileSource(path string) (*targetSource, error) {
+	return inventorySource(path, "hosts文件", scanner.ParseHostsFile)
+}
+
+// zoneFileSource 从BIND区域文件读取目标
+func zoneFileSource(path, origin string) (*targetSource, error) {
+	return inventorySource(path, "区域文件", func(r io.Reader) ([]scanner.Host, error) {
+		return scanner.ParseZoneFile(r, origin)
+	})
+}
+
+// inventorySource 使用给定的解析函数读取目标清单文件
+func inventorySource(path, kind string, parse func(io.Reader) ([]scanner.Host, error)) (*targetSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开%s失败: %v", kind, err)
+	}
+	defer file.Close()
+
+	hosts, err := parse(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("%s中没有可扫描的目标: %s", kind, path)
+	}
+
+	logger.Info("已加载目标清单", "type", kind, "path", path, "targets", len(hosts))
+	return hostsSource(hosts), nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// traceFeasible 跟踪到扫描中发现的符合条件目标的路由，将路径和共同节点写入filename
+func traceFeasible(ctx context.Context, tracer *scanner.PathTracer, filename string) {
+	if tracer.Targets() == 0 {
+		return
+	}
+
+	logger.Info("正在跟踪到符合条件目标的路由...", "targets", tracer.Targets())
+	paths, err := tracer.Trace(ctx)
+	if err != nil {
+		if errors.Is(err, scanner.ErrTracePermission) {
+			logger.Warn("无法进行路由跟踪，请以root运行或授予CAP_NET_RAW", "error", err)
+		} else {
+			logger.Warn("路由跟踪失败", "error", err)
+		}
+		return
+	}
+
+	if err := output.WriteTraceroutes(filename, paths); err != nil {
+		logger.Error("保存路由跟踪结果失败", "error", err)
+		return
+	}
+	logging.Success(logger, "路由跟踪完成", "targets", len(paths), "path", filename)
+}
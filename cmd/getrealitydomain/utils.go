@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+)
+
+// 使用系统清屏命令，纯文本输出时不清屏
+func clearScreenSystem() {
+	if console.Plain() {
+		return
+	}
+	// 尝试使用系统的clear命令
+	cmd := exec.Command("clear")
+	cmd.Stdout = os.Stdout
+	err := cmd.Run()
+	if err != nil {
+		// 如果clear命令失败，使用ANSI转义序列
+		fmt.Print("\033[2J\033[H")
+	}
+}
+
+// 获取整数输入
+func getIntInput() int {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if num, err := strconv.Atoi(input); err == nil {
+			return num
+		}
+		fmt.Print("请输入有效的数字: ")
+	}
+}
+
+// 获取字符串输入
+func getStringInput() string {
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	return strings.TrimSpace(input)
+}
+
+// 暂停等待用户按键，纯文本输出时不暂停
+func pause() {
+	if console.Plain() {
+		return
+	}
+	fmt.Print("\n按回车键继续...")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// isValidMask 验证子网掩码位数是否有效
+func isValidMask(mask string) bool {
+	if !strings.HasPrefix(mask, "/") {
+		return false
+	}
+
+	maskStr := mask[1:] // 去掉/前缀
+	maskBits, err := strconv.Atoi(maskStr)
+	if err != nil {
+		return false
+	}
+
+	// IPv4的有效掩码位数范围是0-32
+	return maskBits >= 0 && maskBits <= 32
+}
+
+// calculateNetworkAddress 根据IP地址和子网掩码计算网络地址
+func calculateNetworkAddress(ipStr, mask string) (string, error) {
+	ip, err := netip.ParseAddr(ipStr)
+	if err != nil {
+		return "", fmt.Errorf("无效的IP地址")
+	}
+
+	// 转换为IPv4
+	ip = ip.Unmap()
+	if !ip.Is4() {
+		return "", fmt.Errorf("不是有效的IPv4地址")
+	}
+
+	// 解析掩码位数
+	maskStr := mask[1:] // 去掉/前缀
+	maskBits, err := strconv.Atoi(maskStr)
+	if err != nil {
+		return "", fmt.Errorf("无效的掩码位数")
+	}
+
+	// 计算网络地址
+	network, err := ip.Prefix(maskBits)
+	if err != nil {
+		return "", fmt.Errorf("无效的掩码位数")
+	}
+
+	return network.Addr().String(), nil
+}
+
+// formatAddressCount 格式化网段地址数，超大网段（如IPv6）显示为2的幂
+func formatAddressCount(size *big.Int) string {
+	if size.BitLen() > 32 {
+		return fmt.Sprintf("2^%d", size.BitLen()-1)
+	}
+	return size.String()
+}
+
+// ExistOnlyOne 检查字符串数组中是否只有一个非空元素
+func ExistOnlyOne(strs []string) bool {
+	count := 0
+	for _, s := range strs {
+		if s != "" {
+			count++
+		}
+	}
+	return count == 1
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// tunnelRTTSamples 测量隧道延迟的次数
+const tunnelRTTSamples = 3
+
+// sshTunnel 通过系统ssh命令建立的动态转发（SOCKS5），作为扫描的观测点
+// 使用系统ssh可以直接沿用 ~/.ssh/config 中的主机别名、密钥和跳板机配置
+type sshTunnel struct {
+	socksProcess
+	name string
+	dest string // ssh目标，如 user@host
+	port string // ssh端口，为空时使用ssh的默认配置
+}
+
+// parseVantage 解析观测点参数，格式为 [名称=]user@host[:port]，未指定名称时使用主机名
+func parseVantage(spec string) (*sshTunnel, error) {
+	t := &sshTunnel{}
+	name, dest, hasName := strings.Cut(spec, "=")
+	if !hasName {
+		name, dest = "", spec
+	}
+	if host, port, err := net.SplitHostPort(dest); err == nil {
+		if _, err := strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("无效的观测点端口: %s", spec)
+		}
+		dest, t.port = host, port
+	}
+	if dest == "" {
+		return nil, fmt.Errorf("无效的观测点: %s（格式为 [名称=]user@host[:port]）", spec)
+	}
+	if name == "" {
+		name = dest[strings.LastIndex(dest, "@")+1:]
+	}
+	t.name, t.dest = name, dest
+	return t, nil
+}
+
+// start 启动ssh动态转发，等待本地SOCKS端口可用
+func (t *sshTunnel) start(ctx context.Context) error {
+	return t.startProcess(ctx, "ssh", func(socks string) (*exec.Cmd, error) {
+		args := []string{"-N", "-D", socks,
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "BatchMode=yes",
+			"-o", "ServerAliveInterval=30",
+		}
+		if t.port != "" {
+			args = append(args, "-p", t.port)
+		}
+		return exec.Command("ssh", append(args, t.dest)...), nil
+	})
+}
+
+// overhead 测量隧道本身的往返延迟：经隧道连接观测点自身的ssh端口，取多次中的最小值，无法测量时返回0
+func (t *sshTunnel) overhead(ctx context.Context, dialer scanner.Dialer) time.Duration {
+	port := t.port
+	if port == "" {
+		port = "22"
+	}
+	var best time.Duration
+	for i := 0; i < tunnelRTTSamples; i++ {
+		dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		start := time.Now()
+		conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort("127.0.0.1", port))
+		elapsed := time.Since(start)
+		cancel()
+		if err != nil {
+			return 0
+		}
+		conn.Close()
+		if best == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	return best
+}
+
+// startVantages 为每个观测点建立SSH隧道并创建通过隧道拨号的扫描器，任一观测点失败时关闭已建立的隧道
+// 观测点只重新握手，不进行MTU、TTL等依赖本地网络的探测
+func startVantages(ctx context.Context, config *Config, geoDB *geo.Geo) ([]scanner.Vantage, func(), error) {
+	var tunnels []*sshTunnel
+	closeAll := func() {
+		for _, tunnel := range tunnels {
+			tunnel.close()
+		}
+	}
+
+	var vantages []scanner.Vantage
+	for _, spec := range config.Vantages {
+		tunnel, err := parseVantage(spec)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		logger.Info("正在连接观测点...", "name", tunnel.name, "ssh", tunnel.dest)
+		if err := tunnel.start(ctx); err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("连接观测点 %s 失败: %v", tunnel.name, err)
+		}
+		tunnels = append(tunnels, tunnel)
+
+		dialer, err := tunnel.dialer()
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("连接观测点 %s 失败: %v", tunnel.name, err)
+		}
+		overhead := tunnel.overhead(ctx, dialer)
+
+		cfg := scannerConfig(config)
+		cfg.Dialer = dialer
+		cfg.DiscoverPorts = nil
+		cfg.VerifySNI = false
+		cfg.ProbeMTU = false
+		cfg.HelloProfile = nil
+		cfg.ProbeTTL = false
+		cfg.TCPPings = 0
+		cfg.DetectInterference = false
+		vantages = append(vantages, scanner.Vantage{
+			Name:     tunnel.name,
+			Scanner:  scanner.New(cfg, geoDB),
+			Overhead: overhead,
+		})
+		logger.Info("观测点已连接", "name", tunnel.name, "tunnel_rtt", overhead.Round(time.Millisecond))
+	}
+	return vantages, closeAll, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// verifyTargets 重新测试结果文件中的全部目标，标出已不再符合条件的目标，用户确认后写回结果文件
+func verifyTargets(config *Config, filename string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	geoDB := loadGeoDB(ctx, config)
+	defer func() {
+		if geoDB != nil {
+			geoDB.Close()
+		}
+	}()
+
+	results, err := retestFile(ctx, newScanner(config, geoDB), filename)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	if len(results) == 0 {
+		return
+	}
+	printVerifyResults(results)
+
+	fmt.Print("\n[W] 用本次结果更新结果文件  回车返回: ")
+	if !strings.EqualFold(getStringInput(), "W") {
+		return
+	}
+	if err := saveVerifyResults(filename, results); err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// retestFile 重新测试结果文件中的全部目标，返回本次测试的结果；文件中没有符合条件的目标时返回空结果
+func retestFile(ctx context.Context, s *scanner.Scanner, filename string) ([]scanner.Result, error) {
+	header, records, err := output.LoadFeasibleTable(filename)
+	if err != nil {
+		return nil, fmt.Errorf("加载结果失败: %v", err)
+	}
+	if len(records) == 0 {
+		logger.Info("没有找到符合条件的目标", "file", filename)
+		return nil, nil
+	}
+
+	hosts := make(chan scanner.Host, len(records))
+	for _, record := range records {
+		host, err := retestHost(header, record)
+		if err != nil {
+			logger.Warn("跳过无效的记录", "ip", columnValue(header, record, "IP"), "error", err)
+			continue
+		}
+		hosts <- host
+	}
+	close(hosts)
+
+	logger.Info("正在验证目标...", "targets", len(hosts), "file", filename)
+	var results []scanner.Result
+	for result := range s.ScanWithConcurrency(ctx, hosts) {
+		results = append(results, result)
+	}
+	if ctx.Err() != nil {
+		return nil, errors.New("验证已取消")
+	}
+	return results, nil
+}
+
+// printVerifyResults 逐个显示验证结果和汇总，返回已不符合条件的目标数
+func printVerifyResults(results []scanner.Result) int {
+	fmt.Printf("\n%s %s %s %s\n", console.PadRight("IP", 40), console.PadRight("端口", 6),
+		console.PadRight("响应时间", 10), "状态")
+	fmt.Println(strings.Repeat("-", 80))
+	failed := 0
+	for _, result := range results {
+		status := "✅ 仍符合条件"
+		if !result.Feasible {
+			failed++
+			status = console.Highlight("❌ 已不符合条件")
+			if result.Error != "" {
+				status += " " + console.Dim(console.Truncate(result.Error, 40))
+			}
+		}
+		fmt.Printf("%s %s %s %s\n", console.PadRight(result.IP, 40), console.PadRight(strconv.Itoa(result.Port), 6),
+			console.Latency(result.ResponseTime, console.PadRight(fmt.Sprintf("%dms", result.ResponseTime), 10)), status)
+	}
+	fmt.Printf("\n共验证 %d 个目标，%d 个仍符合条件，%d 个已不符合条件\n", len(results), len(results)-failed, failed)
+	return failed
+}
+
+// saveVerifyResults 用验证结果更新结果文件中对应的记录
+func saveVerifyResults(filename string, results []scanner.Result) error {
+	updated := 0
+	for _, result := range results {
+		found, err := output.ReplaceResult(filename, result)
+		if err != nil {
+			return fmt.Errorf("更新结果文件失败: %v", err)
+		}
+		if found {
+			updated++
+		}
+	}
+	logging.Success(logger, "结果文件已更新", "file", filename, "targets", updated)
+	return nil
+}
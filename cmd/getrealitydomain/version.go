@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+)
+
+// 构建信息，发布时通过ldflags注入：
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/getrealitydomain
+//
+// 未注入时从Go嵌入的版本控制信息中读取提交和时间
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// releaseURL 查询最新发布版本的地址
+const releaseURL = "https://api.github.com/repos/MengMengCode/GetRealityDomain/releases/latest"
+
+// updateCheckTimeout 检查更新的超时时间
+const updateCheckTimeout = 10 * time.Second
+
+// buildInfo 返回提交和构建时间，ldflags未注入时使用go build记录的版本控制信息
+func buildInfo() (string, string) {
+	rev, date := commit, buildDate
+	dirty := false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && rev == "":
+				rev = setting.Value
+				if len(rev) > 12 {
+					rev = rev[:12]
+				}
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			case setting.Key == "vcs.modified" && commit == "":
+				dirty = setting.Value == "true"
+			}
+		}
+	}
+	if rev == "" {
+		rev = "unknown"
+	} else if dirty {
+		rev += "-dirty"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return rev, date
+}
+
+// versionString 返回完整的版本说明，用于 --version 和问题反馈
+func versionString() string {
+	rev, date := buildInfo()
+	return fmt.Sprintf("GetRealityDomain %s (commit %s, built %s, %s %s/%s)",
+		version, rev, date, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// checkUpdate 查询最新发布版本，有更新时返回新版本号和下载地址，已是最新时返回空字符串
+func checkUpdate(ctx context.Context, client geo.HTTPClient) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, updateCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("查询最新版本失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("查询最新版本失败: HTTP %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("解析最新版本失败: %v", err)
+	}
+	if !newerVersion(release.TagName, version) {
+		return "", "", nil
+	}
+	return release.TagName, release.HTMLURL, nil
+}
+
+// newerVersion 判断latest是否比current新，按点分隔的数字逐段比较，开发版本总是视为较旧
+func newerVersion(latest, current string) bool {
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := 0; i < len(latestParts) || i < len(currentParts); i++ {
+		var l, c int
+		if i < len(latestParts) {
+			l = latestParts[i]
+		}
+		if i < len(currentParts) {
+			c = currentParts[i]
+		}
+		if l != c {
+			return l > c
+		}
+	}
+	return false
+}
+
+// parseVersion 解析 v1.2.3 形式的版本号，忽略预发布后缀
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "-")
+	if s == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, field := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// printVersion 输出版本信息，指定 --check-update 时同时检查是否有新版本
+func printVersion(config *Config) {
+	fmt.Println(versionString())
+	if !config.CheckUpdate {
+		return
+	}
+
+	latest, url, err := checkUpdate(context.Background(), config.GeoDownloader.Client)
+	switch {
+	case err != nil:
+		logger.Warn("检查更新失败", "error", err)
+	case latest == "":
+		fmt.Println("✅ 已是最新版本")
+	default:
+		fmt.Printf("🆕 发现新版本 %s: %s\n", latest, url)
+	}
+}
@@ -0,0 +1,644 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+)
+
+// 分页显示结果
+func showResultsPaginated(config *Config) {
+	filename, pageSize := config.Output, config.PageSize
+
+	// 读取符合条件的结果
+	header, feasibleResults, err := output.LoadFeasibleTable(filename)
+	if err != nil {
+		logger.Error("加载结果失败", "error", err)
+		return
+	}
+
+	if len(feasibleResults) == 0 {
+		logger.Info("没有找到符合条件的目标")
+		return
+	}
+
+	bookmarks, err := loadMarks(filename + bookmarkSuffix)
+	if err != nil {
+		logger.Warn("读取收藏失败，本次不显示已收藏的目标", "error", err)
+	}
+	rejected, err := loadMarks(rejectedPath())
+	if err != nil {
+		logger.Warn("读取已排除的目标失败", "error", err)
+	}
+
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	key, desc := sortFile, false
+	search := "" // 搜索关键词，为空时显示全部目标
+	currentPage := 1
+
+	// order 为显示顺序对应的文件中的下标，序号按显示顺序编号，已排除和不匹配搜索的目标不显示
+	var order []int
+	var totalPages int
+	refresh := func() {
+		order = order[:0]
+		for _, index := range sortOrder(header, feasibleResults, key, desc) {
+			record := feasibleResults[index]
+			if !rejected.starred(rejectKey(header, record)) && matchSearch(header, record, search) {
+				order = append(order, index)
+			}
+		}
+		totalPages = max((len(order)+pageSize-1)/pageSize, 1)
+		currentPage = min(currentPage, totalPages)
+	}
+	refresh()
+
+	// 重新测试时才加载地理位置数据库
+	retest := &retester{config: config}
+	defer retest.close()
+
+	for {
+		console.ClearScreen()
+		title := []string{
+			"",
+			fmt.Sprintf("                    ═══ Reality目标列表 (第%d/%d页) ═══", currentPage, totalPages),
+			"",
+			fmt.Sprintf("    总共找到 %d 个符合条件的目标，排序: %s", len(order), sortDescription(key, desc)),
+		}
+		if search != "" {
+			title = append(title, fmt.Sprintf("    搜索: %s", search))
+		} else if hidden := len(feasibleResults) - len(order); hidden > 0 {
+			title = append(title, fmt.Sprintf("    已隐藏 %d 个已排除的目标", hidden))
+		}
+		console.Box(append(title, ""))
+
+		// 显示当前页的结果
+		start := (currentPage - 1) * pageSize
+		end := start + pageSize
+		if end > len(order) {
+			end = len(order)
+		}
+
+		fmt.Printf("%s %s %s %s %s %s %s\n",
+			console.PadRight("", 2), console.PadRight("序号", 4), console.PadRight("IP地址", 15), console.PadRight("证书域名", 40),
+			console.PadRight("地区", 4), console.PadRight("可信度", 6), "响应时间(ms)")
+		fmt.Println(strings.Repeat("-", 87))
+
+		for i := start; i < end; i++ {
+			result := feasibleResults[order[i]]
+			// GEO_CODE 和 RESPONSE_TIME_MS 按共用的配色规则着色
+			geoCode := console.Country(result[8], console.PadRight(result[8], 4))
+			responseTime, _ := strconv.ParseInt(result[10], 10, 64)
+			star := ""
+			if bookmarks.starred(bookmarkKey(header, result)) {
+				star = "★"
+			}
+			fmt.Printf("%s %s %s %s %s %s %s\n",
+				console.PadRight(star, 2),
+				console.Dim(console.PadRight(strconv.Itoa(i+1), 4)),
+				console.PadRight(result[0], 15), // IP
+				console.PadRight(result[3], 40), // CERT_DOMAIN (完整显示)
+				geoCode,
+				console.PadRight(columnValue(header, result, "CONFIDENCE"), 6),
+				console.Latency(responseTime, result[10]),
+			)
+		}
+
+		fmt.Println("\n操作选项:")
+		if currentPage > 1 {
+			fmt.Print("  [F] 首页  [P] 上一页  ")
+		}
+		if currentPage < totalPages {
+			fmt.Print("  [N] 下一页  [L] 末页  ")
+		}
+		if totalPages > 1 {
+			fmt.Print("  [G] 跳转页  ")
+		}
+		fmt.Print("  [S] 每页数量  ")
+		fmt.Print("  [D] 查看详情  ")
+		fmt.Print("  [R] 重新测试  ")
+		fmt.Print("  [B] 收藏/取消收藏  ")
+		fmt.Print("  [X] 排除目标  ")
+		if len(rejected.stars) > 0 {
+			fmt.Print("  [U] 恢复已排除  ")
+		}
+		fmt.Print("  [/] 搜索  ")
+		if search != "" {
+			fmt.Print("  [W] 导出搜索结果  ")
+		}
+		fmt.Print("  [C] 导出Clash配置  ")
+		if len(bookmarks.stars) > 0 {
+			fmt.Print("  [E] 只导出收藏  ")
+		}
+		fmt.Print("  [Q] 返回")
+		fmt.Print("\n  排序: [1] 延迟  [2] 地区  [3] 域名  [4] 扫描时间  [5] 可信度  [0] 文件顺序（再按一次切换倒序）")
+		fmt.Print("\n请选择: ")
+
+		input := getStringInput()
+		if next, ok := sortKeys[input]; ok {
+			desc = next == key && !desc && next != sortFile
+			key = next
+			refresh()
+			continue
+		}
+
+		switch strings.ToUpper(input) {
+		case "/":
+			fmt.Print("请输入搜索关键词，匹配IP、证书域名、地区、AS组织和证书颁发者（留空清除）: ")
+			search = getStringInput()
+			currentPage = 1
+			refresh()
+		case "W":
+			exportVisible(header, feasibleResults, order, filename)
+			pause()
+		case "P":
+			if currentPage > 1 {
+				currentPage--
+			}
+		case "N":
+			if currentPage < totalPages {
+				currentPage++
+			}
+		case "F":
+			currentPage = 1
+		case "L":
+			currentPage = totalPages
+		case "G":
+			fmt.Printf("请输入页码 (1-%d): ", totalPages)
+			page, err := strconv.Atoi(getStringInput())
+			if err != nil || page < 1 || page > totalPages {
+				logger.Error("无效的页码")
+				pause()
+			} else {
+				currentPage = page
+			}
+		case "S":
+			fmt.Printf("请输入每页显示的数量 (当前: %d): ", pageSize)
+			size, err := strconv.Atoi(getStringInput())
+			if err != nil || size < 1 {
+				logger.Error("无效的数量")
+				pause()
+				break
+			}
+			// 保持当前页的第一个目标可见
+			first := (currentPage - 1) * pageSize
+			pageSize = size
+			currentPage = first/pageSize + 1
+			refresh()
+		case "D":
+			fmt.Print("请输入要查看的序号: ")
+			index, err := strconv.Atoi(getStringInput())
+			if err != nil || index < 1 || index > len(order) {
+				logger.Error("无效的序号")
+			} else {
+				record := feasibleResults[order[index-1]]
+				showResultDetail(header, record, index)
+				fmt.Print("\n[Y] 复制Reality配置片段到剪贴板  回车返回: ")
+				if strings.EqualFold(getStringInput(), "Y") {
+					copySnippet(header, record)
+					pause()
+				}
+			}
+		case "R":
+			fmt.Print("请输入要重新测试的序号: ")
+			index, err := strconv.Atoi(getStringInput())
+			if err != nil || index < 1 || index > len(order) {
+				logger.Error("无效的序号")
+				pause()
+				break
+			}
+			if !retest.run(header, feasibleResults[order[index-1]]) {
+				pause()
+				break
+			}
+			// 结果文件已更新，重新读取，不再符合条件的目标从列表中移除
+			header, feasibleResults, err = output.LoadFeasibleTable(filename)
+			if err != nil {
+				logger.Error("加载结果失败", "error", err)
+				return
+			}
+			if len(feasibleResults) == 0 {
+				logger.Info("没有找到符合条件的目标")
+				return
+			}
+			refresh()
+		case "B":
+			toggleMarks(bookmarks, "请输入要收藏或取消收藏的序号 (如: 1,3,5-8): ", bookmarkKey, header, feasibleResults, order)
+		case "X":
+			toggleMarks(rejected, "请输入要排除的序号 (如: 1,3,5-8)，排除的IP以后也不再显示: ", rejectKey, header, feasibleResults, order)
+			refresh()
+		case "U":
+			restoreRejected(rejected, header, feasibleResults)
+			refresh()
+		case "C":
+			exportClashInteractive(filename, order)
+			pause()
+		case "E":
+			exportFavorites(filename, bookmarks, header, feasibleResults, order)
+			pause()
+		case "Q":
+			return
+		default:
+			logger.Error("无效的选择")
+			pause()
+		}
+	}
+}
+
+// defaultPageSize 结果查看器默认每页显示的目标数
+const defaultPageSize = 10
+
+// sortKey 结果查看器的排序方式
+type sortKey int
+
+const (
+	sortFile       sortKey = iota // 文件顺序（扫描发现的顺序）
+	sortLatency                   // 响应时间
+	sortCountry                   // 国家/地区代码
+	sortDomain                    // 证书域名
+	sortScanTime                  // 扫描时间
+	sortConfidence                // 可信度等级
+)
+
+// sortKeys 选择排序方式的按键
+var sortKeys = map[string]sortKey{
+	"0": sortFile,
+	"1": sortLatency,
+	"2": sortCountry,
+	"3": sortDomain,
+	"4": sortScanTime,
+	"5": sortConfidence,
+}
+
+// sortColumns 各排序方式比较的列
+var sortColumns = map[sortKey]string{
+	sortLatency:    "RESPONSE_TIME_MS",
+	sortCountry:    "GEO_CODE",
+	sortDomain:     "CERT_DOMAIN",
+	sortScanTime:   "SCAN_TIME",
+	sortConfidence: "CONFIDENCE",
+}
+
+// sortDescription 返回排序方式的名称
+func sortDescription(key sortKey, desc bool) string {
+	name := map[sortKey]string{
+		sortFile:       "文件顺序",
+		sortLatency:    "延迟",
+		sortCountry:    "地区",
+		sortDomain:     "域名",
+		sortScanTime:   "扫描时间",
+		sortConfidence: "可信度",
+	}[key]
+	if desc {
+		name += "（倒序）"
+	}
+	return name
+}
+
+// sortOrder 返回按key排序后的记录下标，值相同的记录保持文件顺序
+func sortOrder(header []string, records [][]string, key sortKey, desc bool) []int {
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	column, ok := sortColumns[key]
+	if !ok {
+		return order
+	}
+
+	less := func(a, b []string) bool {
+		x, y := columnValue(header, a, column), columnValue(header, b, column)
+		switch key {
+		case sortLatency:
+			xms, _ := strconv.ParseInt(x, 10, 64)
+			yms, _ := strconv.ParseInt(y, 10, 64)
+			return xms < yms
+		case sortScanTime:
+			// 不同时区记录的时间按时刻比较
+			return parseScanTime(x).Before(parseScanTime(y))
+		case sortConfidence:
+			// 未评级的记录（旧版本文件或关闭了评级）排在C之后
+			return gradeRank(x) < gradeRank(y)
+		}
+		return x < y
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := records[order[i]], records[order[j]]
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+	return order
+}
+
+// gradeRank 返回可信度等级的排序位置，A最前，未评级的最后
+func gradeRank(grade string) int {
+	if grade == "" {
+		return 'Z'
+	}
+	return int(grade[0])
+}
+
+// parseScanTime 解析SCAN_TIME列，兼容旧版本记录的本地时间格式，无法解析时返回零值
+func parseScanTime(value string) time.Time {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	t, _ := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	return t
+}
+
+// fieldLabels 结果文件各列在详情页中的名称，未列出的列直接显示列名
+var fieldLabels = map[string]string{
+	"IP":               "IP地址",
+	"ORIGIN":           "原始输入",
+	"PORT":             "端口",
+	"CERT_DOMAIN":      "证书域名",
+	"CERT_ISSUER":      "证书颁发者",
+	"TLS_VERSION":      "TLS版本",
+	"CURVE":            "椭圆曲线",
+	"GEO_CODE":         "国家/地区",
+	"FEASIBLE":         "符合条件",
+	"RESPONSE_TIME_MS": "响应时间(ms)",
+	"AS_ORG":           "AS组织",
+	"CITY":             "城市",
+	"SNI_MATCH":        "SNI匹配",
+	"CERT_SHA256":      "证书指纹",
+	"MIDDLEBOX":        "中间设备干扰",
+	"MTU":              "MTU探测",
+	"TCP_RTT_MS":       "TCP延迟(ms)",
+	"HOPS":             "跳数",
+	"VANTAGES":         "各观测点",
+	"REGION":           "解析地区",
+	"PREV_CERT_SHA256": "上次证书指纹",
+	"SUSPICIOUS":       "疑似蜜罐",
+	"CONFIDENCE":       "可信度",
+	"HELLO_PROFILE":    "ClientHello探测",
+	"ERROR_KIND":       "错误类别",
+	"ERROR":            "错误",
+	"SCAN_TIME":        "扫描时间",
+}
+
+// showResultDetail 显示单个目标的全部字段，证书域名（SAN列表）每行一个，空字段显示为 -
+func showResultDetail(header, record []string, index int) {
+	console.ClearScreen()
+	console.Box([]string{
+		"",
+		fmt.Sprintf("                    ═══ 目标详情 (第%d个) ═══", index),
+		"",
+	})
+
+	const labelWidth = 16
+	for i, column := range header {
+		label, ok := fieldLabels[column]
+		if !ok {
+			label = column
+		}
+
+		value := "-"
+		if i < len(record) && record[i] != "" {
+			value = record[i]
+		}
+
+		lines := []string{value}
+		if column == "CERT_DOMAIN" {
+			lines = strings.Split(value, ",")
+		}
+		for j, line := range lines {
+			if j > 0 {
+				label = ""
+			}
+			fmt.Printf("%s %s\n", console.PadRight(label, labelWidth), line)
+		}
+	}
+}
+
+// columnValue 按列名取记录中的值，列不存在时返回空字符串
+func columnValue(header, record []string, name string) string {
+	for i, column := range header {
+		if column == name && i < len(record) {
+			return record[i]
+		}
+	}
+	return ""
+}
+
+// copySnippet 将目标的dest和serverNames配置片段复制到剪贴板
+func copySnippet(header, record []string) {
+	snippet := output.RealitySnippet(columnValue(header, record, "IP"), columnValue(header, record, "PORT"),
+		columnValue(header, record, "CERT_DOMAIN"))
+	fmt.Printf("\n%s\n", snippet)
+	method, err := console.CopyToClipboard(snippet)
+	if err != nil {
+		logger.Error("复制到剪贴板失败", "error", err)
+		return
+	}
+	logging.Success(logger, "配置片段已复制到剪贴板", "method", method)
+}
+
+// 交互式导出Clash.Meta配置，order为显示顺序对应的文件中的下标
+func exportClashInteractive(filename string, order []int) {
+	fmt.Print("请输入要导出的序号 (如: 1,3,5-8，留空导出全部): ")
+	selected, err := parseSelection(getStringInput(), len(order))
+	if err != nil {
+		logger.Error("无效的序号", "error", err)
+		return
+	}
+	if len(order) == 0 {
+		logger.Info("没有可导出的目标")
+		return
+	}
+	// 显示的序号换算为文件中的序号，留空时导出全部显示的目标（不含已排除的目标）
+	if len(selected) == 0 {
+		for i := range order {
+			selected = append(selected, i+1)
+		}
+	}
+	for i, index := range selected {
+		selected[i] = order[index-1] + 1
+	}
+
+	if err := output.ExportClashProvider(filename, "clash_provider.yaml", selected); err != nil {
+		logger.Error("导出失败", "error", err)
+	}
+}
+
+// toggleMarks 询问序号并切换这些目标的标记状态，立即保存到标记文件
+func toggleMarks(marks *markStore, prompt string, markKey func(header, record []string) string,
+	header []string, records [][]string, order []int) {
+	fmt.Print(prompt)
+	selected, err := parseSelection(getStringInput(), len(order))
+	if err != nil {
+		logger.Error("无效的序号", "error", err)
+		pause()
+		return
+	}
+	if len(selected) == 0 {
+		return
+	}
+
+	for _, index := range selected {
+		marks.toggle(markKey(header, records[order[index-1]]))
+	}
+	if err := marks.save(); err != nil {
+		logger.Error("保存失败", "error", err)
+		pause()
+	}
+}
+
+// restoreRejected 列出当前结果文件中已排除的目标，恢复选中的目标
+func restoreRejected(rejected *markStore, header []string, records [][]string) {
+	var ips []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		ip := rejectKey(header, record)
+		if rejected.starred(ip) && !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+
+	fmt.Println("\n已排除的目标:")
+	for i, ip := range ips {
+		fmt.Printf("  %s %s\n", console.PadRight(strconv.Itoa(i+1), 4), ip)
+	}
+	fmt.Print("请输入要恢复的序号 (如: 1,3,5-8，留空返回): ")
+	selected, err := parseSelection(getStringInput(), len(ips))
+	if err != nil {
+		logger.Error("无效的序号", "error", err)
+		pause()
+		return
+	}
+	if len(selected) == 0 {
+		return
+	}
+
+	for _, index := range selected {
+		delete(rejected.stars, ips[index-1])
+	}
+	if err := rejected.save(); err != nil {
+		logger.Error("保存失败", "error", err)
+		pause()
+	}
+}
+
+// exportFavorites 只将已收藏且未排除的目标导出为Clash.Meta配置
+func exportFavorites(filename string, bookmarks *markStore, header []string, records [][]string, order []int) {
+	var selected []int
+	for _, index := range order {
+		if bookmarks.starred(bookmarkKey(header, records[index])) {
+			selected = append(selected, index+1)
+		}
+	}
+	if len(selected) == 0 {
+		logger.Info("当前结果中没有已收藏的目标")
+		return
+	}
+
+	if err := output.ExportClashProvider(filename, "clash_provider.yaml", selected); err != nil {
+		logger.Error("导出失败", "error", err)
+	}
+}
+
+// searchColumns 搜索时匹配的列
+var searchColumns = []string{"IP", "ORIGIN", "CERT_DOMAIN", "CERT_ISSUER", "GEO_CODE", "CITY", "ASN", "AS_ORG", "SNI"}
+
+// matchSearch 返回记录是否匹配搜索关键词（不区分大小写），关键词为空时匹配所有记录
+func matchSearch(header, record []string, search string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	for _, column := range searchColumns {
+		if strings.Contains(strings.ToLower(columnValue(header, record, column)), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// exportVisible 将当前显示的目标（按显示顺序）导出为CSV、JSONL或Clash.Meta配置
+func exportVisible(header []string, records [][]string, order []int, filename string) {
+	if len(order) == 0 {
+		logger.Info("没有可导出的目标")
+		return
+	}
+
+	fmt.Printf("导出当前显示的 %d 个目标，格式 [1] CSV  [2] JSONL  [3] Clash配置: ", len(order))
+	format := getStringInput()
+	defaults := map[string]string{"1": "filtered.csv", "2": "filtered.jsonl", "3": "clash_provider.yaml"}
+	path, ok := defaults[format]
+	if !ok {
+		logger.Error("无效的格式")
+		return
+	}
+	fmt.Printf("请输入文件名 (默认: %s): ", path)
+	if input := getStringInput(); input != "" {
+		path = input
+	}
+
+	if format == "3" {
+		selected := make([]int, len(order))
+		for i, index := range order {
+			selected[i] = index + 1
+		}
+		if err := output.ExportClashProvider(filename, path, selected); err != nil {
+			logger.Error("导出失败", "error", err)
+		}
+		return
+	}
+
+	visible := make([][]string, len(order))
+	for i, index := range order {
+		visible[i] = records[index]
+	}
+	if err := output.ExportRecords(path, header, visible); err != nil {
+		logger.Error("导出失败", "error", err)
+	}
+}
+
+// 解析序号选择，支持逗号分隔和区间（如 1,3,5-8）
+func parseSelection(input string, total int) ([]int, error) {
+	var selected []int
+	if strings.TrimSpace(input) == "" {
+		return selected, nil
+	}
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end := part, part
+		if idx := strings.Index(part, "-"); idx > 0 {
+			start, end = part[:idx], part[idx+1:]
+		}
+
+		from, err := strconv.Atoi(strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("%s", part)
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("%s", part)
+		}
+		if from < 1 || to > total || from > to {
+			return nil, fmt.Errorf("%s 超出范围 1-%d", part, total)
+		}
+
+		for i := from; i <= to; i++ {
+			selected = append(selected, i)
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// watcher 守护模式：定期重新验证结果文件中符合条件的目标，目标连续多次验证失败后从结果文件中移除，
+// 并重新生成导出的配置文件，使下游配置只指向仍然可用的dest目标
+type watcher struct {
+	config   *Config
+	filename string
+	scanner  *scanner.Scanner
+	notifier *output.Notifier
+	xray     *output.XrayRotator
+	failures map[string]int // 目标键 -> 连续验证失败的次数
+}
+
+// runWatch 以守护模式运行直到收到中断信号
+func runWatch(config *Config, filename string) error {
+	if filename == "" {
+		return fmt.Errorf("用法: watch <结果文件>")
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return fmt.Errorf("读取结果文件失败: %v", err)
+	}
+	if config.PruneAfter <= 0 || config.WatchInterval <= 0 {
+		return fmt.Errorf("--prune-after 和 --watch-interval 必须大于0")
+	}
+	if (config.XrayAPI == "") != (config.XrayInbound == "") {
+		return fmt.Errorf("--xray-api 和 --xray-inbound 需要同时指定")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	geoDB := loadGeoDB(ctx, config)
+	defer func() {
+		if geoDB != nil {
+			geoDB.Close()
+		}
+	}()
+	w := &watcher{
+		config:   config,
+		filename: filename,
+		scanner:  newScanner(config, geoDB),
+		failures: make(map[string]int),
+	}
+	if config.Notify || config.NotifyCmd != "" {
+		notifier, err := output.NewNotifier(config.NotifyCmd)
+		if err != nil {
+			return err
+		}
+		w.notifier = notifier
+	}
+	if config.XrayAPI != "" {
+		w.xray = &output.XrayRotator{Server: config.XrayAPI, Inbound: config.XrayInbound}
+		dest, err := w.xray.Dest()
+		if err != nil {
+			return err
+		}
+		logger.Info("当前xray入站的dest", "dest", dest)
+	}
+
+	// 启动时先按结果文件生成一次配置，之后只在移除目标时重新生成
+	w.export()
+	logger.Info("守护模式已启动，按Ctrl+C退出", "file", filename, "interval", config.WatchInterval, "prune_after", config.PruneAfter)
+	for {
+		w.verify(ctx)
+		select {
+		case <-ctx.Done():
+			logger.Info("守护模式已退出")
+			return nil
+		case <-time.After(config.WatchInterval):
+		}
+	}
+}
+
+// verify 重新验证一轮，移除连续失败次数达到上限的目标
+func (w *watcher) verify(ctx context.Context) {
+	header, records, err := output.LoadFeasibleTable(w.filename)
+	if err != nil {
+		logger.Error("加载结果失败", "error", err)
+		return
+	}
+	if len(records) == 0 {
+		logger.Warn("结果文件中没有符合条件的目标", "file", w.filename)
+		return
+	}
+
+	hosts := make(chan scanner.Host, len(records))
+	for _, record := range records {
+		host, err := retestHost(header, record)
+		if err != nil {
+			logger.Warn("跳过无效的记录", "ip", columnValue(header, record, "IP"), "error", err)
+			continue
+		}
+		hosts <- host
+	}
+	close(hosts)
+
+	logger.Info("正在重新验证目标...", "targets", len(hosts))
+	var pruned, healthy []scanner.Result
+	for result := range w.scanner.ScanWithConcurrency(ctx, hosts) {
+		if ctx.Err() != nil {
+			return
+		}
+		key := watchKey(result)
+		if result.Error == "" && result.Feasible {
+			delete(w.failures, key)
+			healthy = append(healthy, result)
+			continue
+		}
+		w.failures[key]++
+		logger.Warn("目标验证失败", "ip", result.IP, "port", result.Port, "failures", w.failures[key],
+			"limit", w.config.PruneAfter, "error", result.Error)
+		if w.failures[key] >= w.config.PruneAfter {
+			pruned = append(pruned, result)
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	logger.Info("本轮验证完成", "healthy", len(healthy), "failed", len(w.failures))
+	if w.xray != nil {
+		w.rotate(ctx, healthy)
+	}
+
+	if len(pruned) == 0 {
+		return
+	}
+	var ips []string
+	for _, result := range pruned {
+		// 写入失败的结果后该记录不再符合条件，导出时自动排除
+		if _, err := output.ReplaceResult(w.filename, result); err != nil {
+			logger.Error("更新结果文件失败", "error", err)
+			return
+		}
+		delete(w.failures, watchKey(result))
+		ips = append(ips, result.IP)
+	}
+	logger.Warn("已移除连续验证失败的目标", "targets", strings.Join(ips, ","))
+	w.export()
+	if w.notifier != nil {
+		w.notifier.Notify(ctx, "GetRealityDomain 已移除失效目标",
+			fmt.Sprintf("%d 个目标连续 %d 次验证失败，已从结果和配置中移除: %s", len(ips), w.config.PruneAfter, strings.Join(ips, ", ")))
+	}
+}
+
+// rotate 当前dest本轮验证失败时，将xray入站的dest替换为响应最快的可用目标
+// dest不在结果文件中（如使用域名）时无法判断其状态，不替换
+func (w *watcher) rotate(ctx context.Context, healthy []scanner.Result) {
+	dest, err := w.xray.Dest()
+	if err != nil {
+		logger.Error("读取xray入站配置失败", "error", err)
+		return
+	}
+	host, port, _ := net.SplitHostPort(dest)
+	failed := false
+	for key := range w.failures {
+		if strings.HasPrefix(key, host+"|"+port+"|") {
+			failed = true
+		}
+	}
+	if !failed {
+		return
+	}
+
+	var best *scanner.Result
+	for i, result := range healthy {
+		if best == nil || result.ResponseTime < best.ResponseTime {
+			best = &healthy[i]
+		}
+	}
+	if best == nil {
+		logger.Error("当前dest验证失败，但没有可替换的目标", "dest", dest)
+		return
+	}
+	if err := w.xray.Rotate(ctx, *best); err != nil {
+		logger.Error("替换xray入站的dest失败", "dest", dest, "error", err)
+		return
+	}
+	next, _ := w.xray.Dest()
+	logging.Success(logger, "已替换xray入站的dest", "from", dest, "to", next, "response_time", best.ResponseTime)
+	if w.notifier != nil {
+		w.notifier.Notify(ctx, "GetRealityDomain 已替换dest", fmt.Sprintf("%s 验证失败，已替换为 %s", dest, next))
+	}
+}
+
+// export 按结果文件重新生成 --export-reality 和 --export-clash 指定的配置文件
+func (w *watcher) export() {
+	if w.config.ExportReality != "" {
+		if err := output.ExportRealityConfig(w.filename, w.config.ExportReality); err != nil {
+			logger.Error("生成Reality配置失败", "error", err)
+		}
+	}
+	if w.config.ExportClash != "" {
+		if err := output.ExportClashProvider(w.filename, w.config.ExportClash, nil); err != nil {
+			logger.Error("生成Clash配置失败", "error", err)
+		}
+	}
+}
+
+// watchKey 返回结果的目标键，与ReplaceResult匹配记录的方式一致
+func watchKey(result scanner.Result) string {
+	return result.IP + "|" + strconv.Itoa(result.Port) + "|" + result.SNI
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// wireGuardTraceURL 经隧道请求以确认出口的地址，响应中包含出口IP和是否经由WARP
+const wireGuardTraceURL = "https://www.cloudflare.com/cdn-cgi/trace"
+
+// wireGuardDefaultMTU 配置中未指定MTU时使用的值，与wg-quick相同
+const wireGuardDefaultMTU = 1420
+
+// wireGuardDefaultDNS 配置中未指定DNS时，隧道内解析域名使用的服务器
+var wireGuardDefaultDNS = netip.MustParseAddr("1.1.1.1")
+
+// wireGuardTunnel 进程内的用户态WireGuard隧道：wireguard-go负责握手和加解密，gVisor网络栈（netstack）处理隧道中的TCP/IP，
+// 扫描器直接经由网络栈拨号，不需要root权限，也不修改系统路由和网卡
+type wireGuardTunnel struct {
+	device *device.Device
+	net    *netstack.Net
+}
+
+// wireGuardConfig wg-quick配置中建立隧道需要的字段
+type wireGuardConfig struct {
+	addresses []netip.Addr // 隧道内本机的地址
+	dns       []netip.Addr
+	mtu       int
+	ipc       string // wireguard-go的UAPI配置（密钥为十六进制）
+}
+
+// start 读取wg-quick格式的配置，创建网络栈并启动WireGuard设备
+func (t *wireGuardTunnel) start(ctx context.Context, path string) error {
+	config, err := loadWireGuardConfig(ctx, path)
+	if err != nil {
+		return err
+	}
+	tunDevice, tunNet, err := netstack.CreateNetTUN(config.addresses, config.dns, config.mtu)
+	if err != nil {
+		return fmt.Errorf("创建网络栈失败: %v", err)
+	}
+	t.net = tunNet
+	t.device = device.NewDevice(tunDevice, conn.NewDefaultBind(), &device.Logger{
+		Verbosef: device.DiscardLogf,
+		Errorf: func(format string, args ...any) {
+			logger.Debug("WireGuard: " + fmt.Sprintf(format, args...))
+		},
+	})
+	if err := t.device.IpcSet(config.ipc); err != nil {
+		return fmt.Errorf("无效的WireGuard配置: %v", err)
+	}
+	if err := t.device.Up(); err != nil {
+		return fmt.Errorf("启动WireGuard设备失败: %v", err)
+	}
+	return nil
+}
+
+// dialer 返回经由隧道连接的拨号器，域名通过配置中的DNS在隧道内解析
+func (t *wireGuardTunnel) dialer() scanner.Dialer {
+	return t.net
+}
+
+// close 关闭WireGuard设备和网络栈
+func (t *wireGuardTunnel) close() {
+	if t.device != nil {
+		t.device.Close()
+	}
+}
+
+// loadWireGuardConfig 解析wg-quick格式的配置文件（[Interface]和[Peer]段），
+// 将其转换为wireguard-go的UAPI配置；Endpoint中的域名在此时解析
+func loadWireGuardConfig(ctx context.Context, path string) (*wireGuardConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取WireGuard配置文件失败: %v", err)
+	}
+	defer file.Close()
+
+	config := &wireGuardConfig{mtu: wireGuardDefaultMTU}
+	var ipc strings.Builder
+	var section string
+	var peer []string // 当前[Peer]段的UAPI行，public_key需要排在最前
+	flushPeer := func() {
+		ipc.WriteString(strings.Join(peer, ""))
+		peer = nil
+	}
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line, _, _ := strings.Cut(lines.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			flushPeer()
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("WireGuard配置格式错误: %s", line)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch section + "." + key {
+		case "interface.privatekey":
+			k, err := wireGuardKey(value)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&ipc, "private_key=%s\n", k)
+		case "interface.address":
+			for _, item := range strings.Split(value, ",") {
+				prefix, err := netip.ParsePrefix(strings.TrimSpace(item))
+				if err != nil {
+					return nil, fmt.Errorf("无效的Address: %s", item)
+				}
+				config.addresses = append(config.addresses, prefix.Addr())
+			}
+		case "interface.dns":
+			// DNS中除IP外还可以是搜索域，隧道内只使用IP
+			for _, item := range strings.Split(value, ",") {
+				if addr, err := netip.ParseAddr(strings.TrimSpace(item)); err == nil {
+					config.dns = append(config.dns, addr)
+				}
+			}
+		case "interface.mtu":
+			mtu, err := strconv.Atoi(value)
+			if err != nil || mtu <= 0 {
+				return nil, fmt.Errorf("无效的MTU: %s", value)
+			}
+			config.mtu = mtu
+		case "peer.publickey":
+			k, err := wireGuardKey(value)
+			if err != nil {
+				return nil, err
+			}
+			peer = append([]string{"public_key=" + k + "\n"}, peer...)
+		case "peer.presharedkey":
+			k, err := wireGuardKey(value)
+			if err != nil {
+				return nil, err
+			}
+			peer = append(peer, "preshared_key="+k+"\n")
+		case "peer.endpoint":
+			endpoint, err := resolveWireGuardEndpoint(ctx, value)
+			if err != nil {
+				return nil, err
+			}
+			peer = append(peer, "endpoint="+endpoint.String()+"\n")
+		case "peer.allowedips":
+			for _, item := range strings.Split(value, ",") {
+				prefix, err := netip.ParsePrefix(strings.TrimSpace(item))
+				if err != nil {
+					return nil, fmt.Errorf("无效的AllowedIPs: %s", item)
+				}
+				peer = append(peer, "allowed_ip="+prefix.String()+"\n")
+			}
+		case "peer.persistentkeepalive":
+			peer = append(peer, "persistent_keepalive_interval="+value+"\n")
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("读取WireGuard配置文件失败: %v", err)
+	}
+	flushPeer()
+
+	if len(config.addresses) == 0 {
+		return nil, fmt.Errorf("WireGuard配置中缺少Address")
+	}
+	if !strings.Contains(ipc.String(), "public_key=") {
+		return nil, fmt.Errorf("WireGuard配置中缺少[Peer]")
+	}
+	if len(config.dns) == 0 {
+		config.dns = []netip.Addr{wireGuardDefaultDNS}
+	}
+	config.ipc = ipc.String()
+	return config, nil
+}
+
+// wireGuardKey 将配置中base64编码的密钥转换为UAPI使用的十六进制
+func wireGuardKey(value string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != device.NoisePublicKeySize {
+		return "", fmt.Errorf("无效的WireGuard密钥: %s", value)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// resolveWireGuardEndpoint 解析对端地址，域名优先使用IPv4地址
+func resolveWireGuardEndpoint(ctx context.Context, endpoint string) (netip.AddrPort, error) {
+	host, portStr, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("无效的Endpoint: %s", endpoint)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("无效的Endpoint: %s", endpoint)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return netip.AddrPort{}, fmt.Errorf("解析Endpoint失败: %v", err)
+	}
+	addr := addrs[0]
+	for _, a := range addrs {
+		if a.Unmap().Is4() {
+			addr = a
+			break
+		}
+	}
+	return netip.AddrPortFrom(addr.Unmap(), uint16(port)), nil
+}
+
+// startWireGuard 设置了--wireguard时建立隧道，之后创建的扫描器、可行性检查和后置检查都经由隧道连接目标；
+// 返回关闭隧道的函数，未设置时什么也不做
+func startWireGuard(ctx context.Context, config *Config) (func(), error) {
+	if config.WireGuard == "" {
+		return func() {}, nil
+	}
+
+	logger.Info("正在建立WireGuard隧道...", "config", config.WireGuard)
+	tunnel := &wireGuardTunnel{}
+	if err := tunnel.start(ctx, config.WireGuard); err != nil {
+		tunnel.close()
+		return nil, fmt.Errorf("建立WireGuard隧道失败: %v", err)
+	}
+	dialer := tunnel.dialer()
+	client, err := newHTTPClient(config, config.HTTPProxy, dialer)
+	if err != nil {
+		tunnel.close()
+		return nil, err
+	}
+	direct := config.HTTPClient
+	config.Egress, config.HTTPClient = dialer, client
+
+	if ip, warp, err := wireGuardExit(ctx, client); err != nil {
+		logger.Warn("无法确认WireGuard隧道的出口，隧道可能不可用", "error", err)
+	} else {
+		logger.Info("🛡️  探测流量将经由WireGuard隧道发出", "exit_ip", ip, "warp", warp)
+	}
+	return func() {
+		config.Egress, config.HTTPClient = nil, direct
+		tunnel.close()
+	}, nil
+}
+
+// wireGuardExit 经隧道请求wireGuardTraceURL，返回出口IP和WARP状态（on、plus或off）
+func wireGuardExit(ctx context.Context, client scanner.HTTPClient) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wireGuardTraceURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", "", err
+	}
+
+	var ip, warp string
+	for _, line := range strings.Split(string(body), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "ip":
+			ip = value
+		case "warp":
+			warp = value
+		}
+	}
+	if net.ParseIP(ip) == nil {
+		return "", "", fmt.Errorf("无法识别的响应: %s", resp.Status)
+	}
+	return ip, warp, nil
+}
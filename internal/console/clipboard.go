@@ -0,0 +1,50 @@
+package console
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands 各平台的系统剪贴板命令，按顺序尝试第一个存在的命令
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	default:
+		commands := [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			commands = append([][]string{{"wl-copy"}}, commands...)
+		}
+		return commands
+	}
+}
+
+// CopyToClipboard 复制文本到剪贴板，返回使用的方式
+// 本地会话优先使用系统剪贴板命令；SSH会话或没有可用命令时输出OSC 52转义序列，
+// 由用户本地的终端写入剪贴板（需要终端支持，如iTerm2、Windows Terminal、kitty）
+func CopyToClipboard(text string) (string, error) {
+	if os.Getenv("SSH_TTY") == "" && os.Getenv("SSH_CONNECTION") == "" {
+		for _, command := range clipboardCommands() {
+			if _, err := exec.LookPath(command[0]); err != nil {
+				continue
+			}
+			cmd := exec.Command(command[0], command[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			if err := cmd.Run(); err != nil {
+				return "", fmt.Errorf("执行%s失败: %v", command[0], err)
+			}
+			return command[0], nil
+		}
+	}
+
+	if _, err := fmt.Fprintf(os.Stdout, "\033]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text))); err != nil {
+		return "", err
+	}
+	return "OSC 52", nil
+}
@@ -0,0 +1,86 @@
+package console
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// 结果表的配色规则，实时输出、全屏界面和结果查看器共用：
+//
+//	响应时间  < LatencyGood 绿色，< LatencyFair 黄色，其余红色
+//	国家/地区 属于首选国家（--prefer-countries）时加粗青色
+//	序号等次要列 暗淡显示
+//
+// 标准输出不是终端、设置了NO_COLOR环境变量或指定 --color=false 时不着色
+const (
+	LatencyGood = 150 // 绿色的响应时间上限（毫秒，不含）
+	LatencyFair = 300 // 黄色的响应时间上限（毫秒，不含）
+)
+
+const (
+	ansiReset     = "\033[0m"
+	ansiGreen     = "\033[32m"
+	ansiYellow    = "\033[33m"
+	ansiRed       = "\033[31m"
+	ansiHighlight = "\033[1;36m"
+	ansiDim       = "\033[2m"
+)
+
+var (
+	colorEnabled = term.IsTerminal(os.Stdout.Fd()) && os.Getenv("NO_COLOR") == ""
+	preferred    map[string]bool
+)
+
+// SetColor 开启或关闭着色，标准输出不是终端时默认关闭
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
+// SetPreferredCountries 设置需要突出显示的国家代码
+func SetPreferredCountries(codes []string) {
+	preferred = make(map[string]bool, len(codes))
+	for _, code := range codes {
+		preferred[strings.ToUpper(code)] = true
+	}
+}
+
+// paint 用ANSI颜色包裹文本，未开启着色时原样返回
+// 应在按显示宽度补齐之后调用，转义序列不占显示宽度但会影响DisplayWidth的计算
+func paint(color, text string) string {
+	if !colorEnabled || text == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// Latency 按响应时间ms为text着色
+func Latency(ms int64, text string) string {
+	switch {
+	case ms < LatencyGood:
+		return paint(ansiGreen, text)
+	case ms < LatencyFair:
+		return paint(ansiYellow, text)
+	default:
+		return paint(ansiRed, text)
+	}
+}
+
+// Country 国家代码code属于首选国家时突出显示text
+func Country(code, text string) string {
+	if !preferred[strings.ToUpper(code)] {
+		return text
+	}
+	return Highlight(text)
+}
+
+// Highlight 突出显示text
+func Highlight(text string) string {
+	return paint(ansiHighlight, text)
+}
+
+// Dim 暗淡显示次要信息
+func Dim(text string) string {
+	return paint(ansiDim, text)
+}
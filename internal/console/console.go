@@ -0,0 +1,90 @@
+// Package console 提供终端输出的辅助函数（边框、清屏、显示宽度等）
+//
+// 带图标的信息提示由 internal/logging 的终端日志处理器负责
+package console
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// plain 是否只输出数据和日志，不清屏、不输出装饰性的边框，用于脚本调用和重定向输出
+var plain bool
+
+// SetPlain 开启或关闭纯文本输出
+func SetPlain(enabled bool) {
+	plain = enabled
+}
+
+// Plain 返回是否只输出数据和日志
+func Plain() bool {
+	return plain
+}
+
+// ClearScreen 清屏，纯文本输出时不做任何事
+func ClearScreen() {
+	if plain {
+		return
+	}
+	fmt.Print("\033[2J\033[H")
+}
+
+// Box 打印带边框的文本，纯文本输出时不打印
+func Box(lines []string) {
+	if plain {
+		return
+	}
+	maxLen := 0
+	for _, line := range lines {
+		displayWidth := DisplayWidth(line)
+		if displayWidth > maxLen {
+			maxLen = displayWidth
+		}
+	}
+
+	if maxLen < 60 {
+		maxLen = 60
+	}
+
+	// 顶部边框
+	fmt.Print("╔")
+	for i := 0; i < maxLen+2; i++ {
+		fmt.Print("═")
+	}
+	fmt.Println("╗")
+
+	// 内容
+	for _, line := range lines {
+		displayWidth := DisplayWidth(line)
+		padding := maxLen - displayWidth
+		fmt.Printf("║ %s%s ║\n", line, strings.Repeat(" ", padding))
+	}
+
+	// 底部边框
+	fmt.Print("╚")
+	for i := 0; i < maxLen+2; i++ {
+		fmt.Print("═")
+	}
+	fmt.Println("╝")
+}
+
+// DisplayWidth 计算字符串在终端中的显示宽度
+// 按东亚宽度规则计算：中日韩文字和全角字符占2个宽度，组合字符和零宽字符不占宽度
+func DisplayWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// Truncate 按显示宽度截断字符串，超出时以...结尾，不会截断多字节字符
+func Truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "...")
+}
+
+// PadRight 按显示宽度右侧补齐空格，用于对齐包含中文或国际化域名的列
+func PadRight(s string, width int) string {
+	if pad := width - DisplayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
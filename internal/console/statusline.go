@@ -0,0 +1,95 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// StatusLine 固定在终端最后一行的状态行：将滚动区域设为状态行以上的部分，
+// 日志和其他输出在其上方正常滚动，状态行原位刷新，两者不再交错
+// 所有经过StatusLine的写入都串行进行，避免控制序列被其他输出打断
+type StatusLine struct {
+	mu     sync.Mutex
+	out    *os.File
+	width  int
+	height int
+	text   string
+	active bool
+}
+
+// NewStatusLine 创建输出到out的状态行，out不是终端时返回nil
+func NewStatusLine(out *os.File) *StatusLine {
+	if !term.IsTerminal(out.Fd()) {
+		return nil
+	}
+	return &StatusLine{out: out}
+}
+
+// Start 保留终端最后一行作为状态行
+func (s *StatusLine) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = true
+	s.resize()
+}
+
+// resize 按当前终端大小重新设置滚动区域，调用方需持有锁
+func (s *StatusLine) resize() {
+	width, height, err := term.GetSize(s.out.Fd())
+	if err != nil || height < 2 || (width == s.width && height == s.height) {
+		return
+	}
+	s.width, s.height = width, height
+
+	// 先换行确保光标不在最后一行，再设置滚动区域（会把光标移到左上角），最后回到滚动区域底部
+	fmt.Fprintf(s.out, "\n\033[1;%dr\033[%d;1H", height-1, height-1)
+}
+
+// Set 更新状态行的内容，超出终端宽度的部分被截断
+func (s *StatusLine) Set(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text = text
+	s.draw()
+}
+
+// draw 在最后一行绘制状态，保存并恢复光标位置，调用方需持有锁
+func (s *StatusLine) draw() {
+	if !s.active {
+		return
+	}
+	s.resize()
+	if s.height == 0 {
+		return
+	}
+	fmt.Fprintf(s.out, "\0337\033[%d;1H\033[2K%s\0338", s.height, Truncate(s.text, s.width-1))
+}
+
+// Write 在状态行上方输出，可作为日志的终端输出目标
+func (s *StatusLine) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.out.Write(p)
+	s.draw()
+	return n, err
+}
+
+// Println 在状态行上方输出一行
+func (s *StatusLine) Println(line string) {
+	s.Write([]byte(line + "\n"))
+}
+
+// Stop 恢复整个终端为滚动区域并清除状态行
+func (s *StatusLine) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return
+	}
+	s.active = false
+	fmt.Fprintf(s.out, "\0337\033[r\033[%d;1H\033[2K\0338", s.height)
+	s.width, s.height = 0, 0
+}
@@ -0,0 +1,159 @@
+// Package httpclient 辅助请求（CDN检测、抓取域名、下载数据库、查询公网IP、反查域名等）共用的HTTP客户端：
+// 统一的超时、代理、重试和User-Agent，并统计请求、重试和失败次数
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+)
+
+var logger = logging.For("http")
+
+// retryBackoff 第一次重试前的等待时间，之后每次翻倍
+const retryBackoff = 500 * time.Millisecond
+
+// Options 客户端配置
+type Options struct {
+	Timeout   time.Duration // 单次请求（含读取响应体）的超时，0表示只受ctx控制
+	Proxy     string        // 代理地址，支持 http://、https://、socks5://，为空时使用 HTTP_PROXY 等环境变量
+	Retries   int           // 幂等请求（GET、HEAD）遇到临时错误或429/502/503/504时的重试次数
+	UserAgent string        // 请求未设置User-Agent时使用的值，为空时使用Go的默认值
+
+	// Dial 建立连接的函数，为nil时直接连接；经由隧道或NAT64时设置为对应的拨号器
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Client 辅助请求共用的HTTP客户端，满足 scanner.HTTPClient 和 geo.HTTPClient 接口，可并发使用
+type Client struct {
+	opts   Options
+	client *http.Client
+
+	requests atomic.Int64
+	retries  atomic.Int64
+	failures atomic.Int64
+}
+
+// Stats 请求统计
+type Stats struct {
+	Requests int64 // 发出的请求数，不含重试
+	Retries  int64 // 重试次数
+	Failures int64 // 最终失败（出错或状态码为5xx）的请求数
+}
+
+// New 按配置创建客户端，代理地址无效时返回错误
+func New(opts Options) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("解析代理地址失败: %v", err)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("不支持的代理协议: %s", proxy.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if opts.Dial != nil {
+		transport.DialContext = opts.Dial
+	}
+	return &Client{
+		opts:   opts,
+		client: &http.Client{Transport: transport, Timeout: opts.Timeout},
+	}, nil
+}
+
+// Do 发送请求，幂等请求遇到临时错误时按Retries重试，ctx取消时不再重试
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.opts.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", c.opts.UserAgent)
+	}
+	c.requests.Add(1)
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.Do(req)
+		retry := attempt < c.opts.Retries && retryable(req, resp, err)
+		if !retry {
+			if err != nil || resp.StatusCode >= 500 {
+				c.failures.Add(1)
+			}
+			logRequest(req, resp, err, attempt, time.Since(start))
+			return resp, err
+		}
+
+		if resp != nil {
+			// 读完并关闭响应体，使连接可以复用
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		c.retries.Add(1)
+		select {
+		case <-time.After(retryBackoff << attempt):
+		case <-req.Context().Done():
+			c.failures.Add(1)
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// Stats 返回请求统计
+func (c *Client) Stats() Stats {
+	return Stats{Requests: c.requests.Load(), Retries: c.retries.Load(), Failures: c.failures.Load()}
+}
+
+// retryable 判断请求是否应该重试：只重试没有请求体的幂等请求，ctx已取消时不重试
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil || req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if err != nil {
+		return temporary(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// temporary 判断错误是否可能在重试后消失：超时、连接被重置或意外关闭。
+// 连接被拒绝、域名不存在等错误重试也不会成功
+func temporary(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	return false
+}
+
+// logRequest 在调试日志中记录请求结果
+func logRequest(req *http.Request, resp *http.Response, err error, retries int, elapsed time.Duration) {
+	attrs := []any{"method", req.Method, "url", req.URL.Redacted(), "elapsed", elapsed.Round(time.Millisecond)}
+	if retries > 0 {
+		attrs = append(attrs, "retries", retries)
+	}
+	if err != nil {
+		logger.Debug("HTTP请求失败", append(attrs, "error", err)...)
+		return
+	}
+	logger.Debug("HTTP请求", append(attrs, "status", resp.StatusCode)...)
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ConsoleHandler 终端友好的日志处理器：按级别显示图标，属性以key=value形式附在消息后
+type ConsoleHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	mu     *sync.Mutex
+	attrs  []slog.Attr
+	prefix string // 分组前缀
+}
+
+// NewConsoleHandler 创建终端日志处理器
+func NewConsoleHandler(w io.Writer, level slog.Leveler) *ConsoleHandler {
+	return &ConsoleHandler{w: w, level: level, mu: &sync.Mutex{}}
+}
+
+// levelIcon 返回日志级别对应的图标
+func levelIcon(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "❌ "
+	case level >= slog.LevelWarn:
+		return "⚠️  "
+	case level >= LevelSuccess:
+		return "✅ "
+	case level >= slog.LevelInfo:
+		return "ℹ️  "
+	default:
+		return "🔍 "
+	}
+}
+
+func (h *ConsoleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *ConsoleHandler) Handle(ctx context.Context, record slog.Record) error {
+	var line strings.Builder
+	line.WriteString(levelIcon(record.Level))
+	line.WriteString(record.Message)
+
+	for _, attr := range h.attrs {
+		appendAttr(&line, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&line, h.prefix, attr)
+		return true
+	})
+	line.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+// appendAttr 追加属性，component属性在终端中省略以减少噪音
+func appendAttr(line *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) || attr.Key == "component" {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, child := range attr.Value.Group() {
+			appendAttr(line, prefix+attr.Key+".", child)
+		}
+		return
+	}
+
+	fmt.Fprintf(line, " %s%s=%v", prefix, attr.Key, attr.Value.Any())
+}
+
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	clone.attrs = append(clone.attrs, h.attrs...)
+	for _, attr := range attrs {
+		if h.prefix != "" {
+			attr.Key = h.prefix + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// MultiHandler 将日志同时分发给多个处理器
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler 创建分发处理器
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+func (m *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range m.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MultiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range m.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, handler := range m.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+func (m *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, handler := range m.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
@@ -0,0 +1,269 @@
+// Package logging 基于log/slog的日志设施：带图标的终端输出、文本/JSON格式、
+// 按组件设置日志级别以及可选的日志文件输出
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// LevelSuccess 成功信息的日志级别，介于Info和Warn之间
+const LevelSuccess = slog.LevelInfo + 2
+
+// Options 日志配置
+type Options struct {
+	Format     string // 终端输出格式: pretty（默认）、text、json
+	Level      string // 日志级别，支持按组件设置，如 "info,scanner=debug,geo=warn"
+	File       string // 日志文件路径，为空时不写文件
+	FileFormat string // 日志文件格式: text（默认）、json
+
+	// 日志文件的级别与终端无关，默认记录全部调试日志，便于事后分析长时间的扫描
+	FileLevel   string // 日志文件级别，为空时为debug
+	FileMaxSize int64  // 日志文件轮转大小（字节），为0时不轮转
+	FileBackups int    // 保留的已轮转日志文件数
+}
+
+var (
+	levelsMu     sync.RWMutex
+	defaultLevel = slog.LevelInfo
+	levels       = map[string]slog.Level{} // 组件 -> 日志级别
+	fileLevel    *slog.Level               // 日志文件级别，未写日志文件时为nil
+)
+
+// terminalKey 记录是否输出到终端的context键，由组件日志器按组件级别设置
+type terminalKey struct{}
+
+// terminal 终端日志的输出目标，全屏界面运行期间由SetTerminal临时替换
+var terminal = &switchWriter{w: os.Stdout}
+
+// SetTerminal 将终端日志改为写入w（默认为标准输出），日志文件不受影响
+func SetTerminal(w io.Writer) {
+	terminal.set(w)
+}
+
+// switchWriter 可在运行时替换目标的Writer
+type switchWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *switchWriter) set(w io.Writer) {
+	s.mu.Lock()
+	s.w = w
+	s.mu.Unlock()
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Setup 根据配置创建日志处理器并设置为slog默认日志器
+// 返回的io.Closer用于关闭日志文件
+func Setup(opts Options) (io.Closer, error) {
+	if err := parseLevels(opts.Level); err != nil {
+		return nil, err
+	}
+
+	// 各组件的级别过滤在组件日志器中完成，处理器本身接受所有级别
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: replaceLevel}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case "", "pretty":
+		handler = NewConsoleHandler(terminal, slog.LevelDebug)
+	case "text":
+		handler = slog.NewTextHandler(terminal, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(terminal, handlerOpts)
+	default:
+		return nil, fmt.Errorf("不支持的日志格式: %s", opts.Format)
+	}
+	handlers := []slog.Handler{terminalFilter{handler}}
+
+	var closer io.Closer = nopCloser{}
+	var level *slog.Level
+	if opts.File != "" {
+		level = new(slog.Level)
+		*level = slog.LevelDebug
+		if opts.FileLevel != "" {
+			if err := level.UnmarshalText([]byte(opts.FileLevel)); err != nil {
+				return nil, fmt.Errorf("无效的日志文件级别: %s", opts.FileLevel)
+			}
+		}
+		handlerOpts := &slog.HandlerOptions{Level: *level, ReplaceAttr: replaceLevel}
+
+		file, err := openRotatingFile(opts.File, opts.FileMaxSize, opts.FileBackups)
+		if err != nil {
+			return nil, fmt.Errorf("打开日志文件失败: %v", err)
+		}
+		closer = file
+
+		switch opts.FileFormat {
+		case "", "text":
+			handlers = append(handlers, slog.NewTextHandler(file, handlerOpts))
+		case "json":
+			handlers = append(handlers, slog.NewJSONHandler(file, handlerOpts))
+		default:
+			file.Close()
+			return nil, fmt.Errorf("不支持的日志文件格式: %s", opts.FileFormat)
+		}
+	}
+
+	levelsMu.Lock()
+	fileLevel = level
+	levelsMu.Unlock()
+
+	slog.SetDefault(slog.New(NewMultiHandler(handlers...)))
+	return closer, nil
+}
+
+// replaceLevel 将LevelSuccess显示为SUCCESS而不是INFO+2
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelSuccess {
+			attr.Value = slog.StringValue("SUCCESS")
+		}
+	}
+	return attr
+}
+
+// parseLevels 解析日志级别配置，如 "info,scanner=debug"
+func parseLevels(spec string) error {
+	parsed := map[string]slog.Level{}
+	base := slog.LevelInfo
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		component, levelStr, hasComponent := strings.Cut(part, "=")
+		if !hasComponent {
+			levelStr = component
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(levelStr)); err != nil {
+			return fmt.Errorf("无效的日志级别: %s", part)
+		}
+
+		if hasComponent {
+			parsed[strings.TrimSpace(component)] = level
+		} else {
+			base = level
+		}
+	}
+
+	levelsMu.Lock()
+	defaultLevel = base
+	levels = parsed
+	levelsMu.Unlock()
+	return nil
+}
+
+// SetLevel 设置指定组件的日志级别
+func SetLevel(component string, level slog.Level) {
+	levelsMu.Lock()
+	levels[component] = level
+	levelsMu.Unlock()
+}
+
+// levelFor 返回组件当前的日志级别
+func levelFor(component string) slog.Level {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	if level, ok := levels[component]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+// For 返回指定组件的日志器
+// 日志器在每次输出时才取slog.Default()，因此可以在Setup之前创建；
+// 作为库使用时，调用方通过slog.SetDefault即可接管所有输出
+func For(component string) *slog.Logger {
+	return slog.New(&componentHandler{component: component})
+}
+
+// Success 以LevelSuccess级别输出日志
+func Success(logger *slog.Logger, msg string, args ...any) {
+	logger.Log(context.Background(), LevelSuccess, msg, args...)
+}
+
+// componentHandler 附加component属性并按组件级别过滤，实际输出交给slog默认处理器
+type componentHandler struct {
+	component string
+	wrap      []func(slog.Handler) slog.Handler // 依次应用的WithAttrs/WithGroup
+}
+
+func (h *componentHandler) target() slog.Handler {
+	handler := slog.Default().Handler().WithAttrs([]slog.Attr{slog.String("component", h.component)})
+	for _, wrap := range h.wrap {
+		handler = wrap(handler)
+	}
+	return handler
+}
+
+// Enabled 级别达到组件级别或日志文件级别时启用
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= levelFor(h.component) {
+		return true
+	}
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	return fileLevel != nil && level >= *fileLevel
+}
+
+// Handle 输出日志，低于组件级别的日志只写入日志文件
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	ctx = context.WithValue(ctx, terminalKey{}, record.Level >= levelFor(h.component))
+	return h.target().Handle(ctx, record)
+}
+
+// terminalFilter 按组件日志器的判断过滤终端日志，未经组件日志器的日志直接输出
+type terminalFilter struct {
+	slog.Handler
+}
+
+func (f terminalFilter) Enabled(ctx context.Context, level slog.Level) bool {
+	if enabled, ok := ctx.Value(terminalKey{}).(bool); ok && !enabled {
+		return false
+	}
+	return f.Handler.Enabled(ctx, level)
+}
+
+func (f terminalFilter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return terminalFilter{f.Handler.WithAttrs(attrs)}
+}
+
+func (f terminalFilter) WithGroup(name string) slog.Handler {
+	return terminalFilter{f.Handler.WithGroup(name)}
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *componentHandler) with(wrap func(slog.Handler) slog.Handler) slog.Handler {
+	wraps := make([]func(slog.Handler) slog.Handler, 0, len(h.wrap)+1)
+	wraps = append(wraps, h.wrap...)
+	wraps = append(wraps, wrap)
+	return &componentHandler{component: h.component, wrap: wraps}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
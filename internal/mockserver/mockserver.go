@@ -0,0 +1,206 @@
+// Package mockserver 本地TLS测试服务器：使用自签名证书，TLS版本、ALPN、证书域名和颁发者可配置，
+// 还可以模仿Cloudflare CDN的响应，用于在不访问互联网的情况下测量扫描吞吐量，
+// 以及对扫描、可行性判断和导出进行可重复的端到端测试
+package mockserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// DefaultDomain 自签名证书的默认域名
+const DefaultDomain = "mock.example.com"
+
+// Options 测试服务器的配置，零值为TLS 1.3 + h2、证书域名为DefaultDomain
+type Options struct {
+	Addr    string   // 监听地址，如 127.0.0.1:0
+	Version uint16   // 唯一支持的TLS版本（tls.VersionTLS12或tls.VersionTLS13），0表示TLS 1.3
+	ALPN    []string // 服务器支持的ALPN协议，按优先顺序；nil表示h2和http/1.1，空切片表示不协商ALPN
+	Domains []string // 证书中的域名（SAN），为空时使用DefaultDomain
+	Issuer  string   // 证书颁发者名称，为空时使用默认名称
+	CDN     bool     // 模仿Cloudflare：响应头带有Server: cloudflare和CF-Ray，并提供/cdn-cgi/trace
+}
+
+// ParseOptions 解析测试服务器的配置，格式为 地址[,选项...]，选项包括：
+// tls=1.2|1.3、alpn=h2;http/1.1（none表示不协商）、san=a.example.com;b.example.com、issuer=名称、cdn
+func ParseOptions(spec string) (Options, error) {
+	fields := strings.Split(spec, ",")
+	opts := Options{Addr: strings.TrimSpace(fields[0])}
+	if _, _, err := net.SplitHostPort(opts.Addr); err != nil {
+		return opts, fmt.Errorf("无效的测试服务器地址: %s", opts.Addr)
+	}
+	for _, field := range fields[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "tls":
+			switch value {
+			case "1.2":
+				opts.Version = tls.VersionTLS12
+			case "1.3":
+				opts.Version = tls.VersionTLS13
+			default:
+				return opts, fmt.Errorf("不支持的TLS版本: %s（可选 1.2、1.3）", value)
+			}
+		case "alpn":
+			opts.ALPN = []string{}
+			if value != "none" {
+				opts.ALPN = strings.Split(value, ";")
+			}
+		case "san":
+			opts.Domains = strings.Split(value, ";")
+		case "issuer":
+			opts.Issuer = value
+		case "cdn":
+			opts.CDN = true
+		default:
+			return opts, fmt.Errorf("未知的测试服务器选项: %s", field)
+		}
+	}
+	return opts, nil
+}
+
+// Server 本地TLS测试服务器
+type Server struct {
+	Addr netip.AddrPort // 监听地址
+
+	server *http.Server
+}
+
+// Start 按配置启动测试服务器
+func Start(opts Options) (*Server, error) {
+	domains := opts.Domains
+	if len(domains) == 0 {
+		domains = []string{DefaultDomain}
+	}
+	issuer := opts.Issuer
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+	cert, err := selfSigned(domains, issuer)
+	if err != nil {
+		return nil, err
+	}
+	version := opts.Version
+	if version == 0 {
+		version = tls.VersionTLS13
+	}
+	alpn := opts.ALPN
+	if alpn == nil {
+		alpn = []string{"h2", "http/1.1"}
+	}
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("启动测试服务器失败: %v", err)
+	}
+	s := &Server{
+		Addr: listener.Addr().(*net.TCPAddr).AddrPort(),
+		server: &http.Server{
+			Handler: handler(domains[0], opts.CDN),
+			// 扫描器握手后立即关闭连接，不记录由此产生的握手错误
+			ErrorLog:          log.New(io.Discard, "", 0),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+	// 自行创建TLS监听器，使服务器只协商配置中的ALPN协议；协商到h2时由http.Server处理HTTP/2
+	tlsListener := tls.NewListener(listener, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   version,
+		MaxVersion:   version,
+		NextProtos:   alpn,
+	})
+	go s.server.Serve(tlsListener)
+	return s, nil
+}
+
+// handler 返回测试服务器的HTTP处理器，cdn为true时模仿Cloudflare的响应
+func handler(domain string, cdn bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cdn {
+			w.Header().Set("Server", "cloudflare")
+			w.Header().Set("CF-Ray", "8a1b2c3d4e5f6789-LAX")
+			w.Header().Set("CF-Cache-Status", "DYNAMIC")
+			if r.URL.Path == "/cdn-cgi/trace" {
+				ip, _, _ := net.SplitHostPort(r.RemoteAddr)
+				fmt.Fprintf(w, "fl=1f1\nh=%s\nip=%s\ncolo=LAX\nhttp=%s\ntls=%s\n", domain, ip, r.Proto, tls.VersionName(r.TLS.Version))
+				return
+			}
+		}
+		if r.URL.Path == "/cdn-cgi/trace" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "ok\n")
+	})
+}
+
+// Close 关闭测试服务器
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// StartFarm 在回环地址的n个端口上启动测试服务器，多个端口使同一本地端口可以连接不同目标，
+// 避免高并发时本地端口耗尽；任一服务器启动失败时关闭已启动的服务器
+func StartFarm(n int) ([]*Server, error) {
+	var servers []*Server
+	for range n {
+		server, err := Start(Options{Addr: "127.0.0.1:0"})
+		if err != nil {
+			CloseAll(servers)
+			return nil, err
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// CloseAll 关闭全部测试服务器
+func CloseAll(servers []*Server) {
+	for _, server := range servers {
+		server.Close()
+	}
+}
+
+// defaultIssuer 证书颁发者的默认名称
+const defaultIssuer = "GetRealityDomain Mock CA"
+
+// selfSigned 生成包含指定域名和颁发者名称的自签名ECDSA证书
+func selfSigned(domains []string, issuer string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成密钥失败: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成证书失败: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	// 以同一密钥签名，颁发者取自parent的Subject
+	parent := &x509.Certificate{Subject: pkix.Name{CommonName: issuer}}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成证书失败: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,222 @@
+// Package tui 扫描过程中的全屏终端界面：进度和预计剩余时间、实时的合规目标表、滚动的日志窗格，
+// 以及停止、暂停和查看目标详情的快捷键
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/output"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// 界面刷新和缓冲的参数
+const (
+	refreshInterval = 500 * time.Millisecond // 统计信息的刷新间隔
+	maxLogLines     = 500                    // 日志窗格保留的行数
+)
+
+// Options 界面配置
+type Options struct {
+	Stats  *output.Stats   // 扫描统计，定期读取快照
+	Pauser *scanner.Pauser // 暂停目标分发，为nil时不支持暂停
+	Stop   func()          // 用户要求停止扫描时调用
+}
+
+// UI 全屏扫描界面，同时作为结果输出目标（接收符合条件的结果）和终端日志的输出目标
+type UI struct {
+	program *tea.Program
+	exited  chan struct{}
+	err     error
+}
+
+// New 创建全屏界面，调用Start后开始显示
+func New(opts Options) *UI {
+	return &UI{
+		program: tea.NewProgram(newModel(opts), tea.WithAltScreen()),
+		exited:  make(chan struct{}),
+	}
+}
+
+// Start 在后台运行界面
+func (u *UI) Start() {
+	go func() {
+		defer close(u.exited)
+		_, u.err = u.program.Run()
+	}()
+}
+
+// Send 将符合条件的结果加入目标表，实现output.ResultSink
+func (u *UI) Send(result scanner.Result) error {
+	u.program.Send(resultMsg(result))
+	return nil
+}
+
+// Close 实现output.ResultSink，界面由Wait结束
+func (u *UI) Close() error {
+	return nil
+}
+
+// Write 将日志按行加入日志窗格，界面退出后的日志被丢弃
+func (u *UI) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		u.program.Send(logMsg(line))
+	}
+	return len(p), nil
+}
+
+// Wait 通知界面扫描已结束，等待用户退出界面；用户已在扫描中要求停止时界面立即退出
+func (u *UI) Wait() error {
+	u.program.Send(doneMsg{})
+	<-u.exited
+	return u.err
+}
+
+type (
+	tickMsg   time.Time
+	resultMsg scanner.Result
+	logMsg    string
+	doneMsg   struct{}
+)
+
+// model 界面状态
+type model struct {
+	opts   Options
+	width  int
+	height int
+
+	snap    output.StatsSnapshot
+	results []scanner.Result
+	cursor  int // 目标表中选中的行
+	offset  int // 目标表第一行显示的结果序号
+	logs    []string
+	detail  *scanner.Result // 正在查看详情的目标，为nil时显示主界面
+	notice  string          // 详情界面的操作提示，如复制结果
+
+	paused   bool
+	stopping bool // 用户已要求停止，扫描结束后自动退出
+	done     bool // 扫描已结束
+}
+
+func newModel(opts Options) *model {
+	return &model{opts: opts, snap: opts.Stats.Snapshot()}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *model) Init() tea.Cmd {
+	return tick()
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scrollToCursor()
+	case tickMsg:
+		if !m.done {
+			m.snap = m.opts.Stats.Snapshot()
+		}
+		return m, tick()
+	case resultMsg:
+		m.results = append(m.results, scanner.Result(msg))
+		// 选中最后一行时跟随新结果
+		if m.cursor == len(m.results)-2 {
+			m.cursor++
+		}
+		m.scrollToCursor()
+	case logMsg:
+		m.logs = append(m.logs, string(msg))
+		if len(m.logs) > maxLogLines {
+			m.logs = m.logs[len(m.logs)-maxLogLines:]
+		}
+	case doneMsg:
+		m.done = true
+		m.snap = m.opts.Stats.Snapshot()
+		if m.stopping {
+			return m, tea.Quit
+		}
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey 处理按键：q停止扫描（扫描结束后退出界面），p暂停，方向键选择目标，Enter查看详情，
+// 详情界面中c复制配置片段
+func (m *model) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "ctrl+c", "q":
+		if m.done {
+			return m, tea.Quit
+		}
+		if !m.stopping {
+			m.stopping = true
+			m.opts.Stop()
+		}
+	case "p", " ":
+		if m.opts.Pauser != nil && !m.done {
+			m.paused = m.opts.Pauser.Toggle()
+		}
+	case "esc", "backspace":
+		m.detail = nil
+	case "enter":
+		if len(m.results) > 0 {
+			result := m.results[m.cursor]
+			m.detail = &result
+			m.notice = ""
+		}
+	case "c", "y":
+		if m.detail != nil {
+			m.notice = copySnippet(*m.detail)
+		}
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "pgup":
+		m.moveCursor(-m.tableRows())
+	case "pgdown":
+		m.moveCursor(m.tableRows())
+	case "home", "g":
+		m.moveCursor(-len(m.results))
+	case "end", "G":
+		m.moveCursor(len(m.results))
+	}
+	return m, nil
+}
+
+// copySnippet 将目标的dest和serverNames配置片段复制到剪贴板，返回显示给用户的提示
+func copySnippet(result scanner.Result) string {
+	snippet := output.RealitySnippet(result.IP, strconv.Itoa(result.Port), result.CertDomain)
+	method, err := console.CopyToClipboard(snippet)
+	if err != nil {
+		return "复制失败: " + err.Error()
+	}
+	return "配置片段已复制到剪贴板（" + method + "）"
+}
+
+// moveCursor 移动选中行并保持其可见
+func (m *model) moveCursor(delta int) {
+	if len(m.results) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.results)-1)
+	m.scrollToCursor()
+}
+
+// scrollToCursor 调整目标表的滚动位置，使选中行可见
+func (m *model) scrollToCursor() {
+	rows := m.tableRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 界面布局的固定行数
+const (
+	headerLines     = 4  // 标题、进度、计数和分隔线
+	footerLines     = 1  // 快捷键提示
+	progressBarSize = 40 // 进度条宽度
+	defaultWidth    = 80 // 尚未收到窗口大小时使用的宽度
+	defaultRows     = 10 // 尚未收到窗口大小时目标表的行数
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	pausedStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	headStyle     = lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+)
+
+func (m *model) View() string {
+	if m.detail != nil {
+		return m.detailView()
+	}
+
+	var b strings.Builder
+	m.writeHeader(&b)
+	m.writeTable(&b)
+	m.writeLogs(&b)
+	b.WriteString(dimStyle.Render(m.help()))
+	return b.String()
+}
+
+// lineWidth 返回可用的行宽
+func (m *model) lineWidth() int {
+	if m.width <= 0 {
+		return defaultWidth
+	}
+	return m.width
+}
+
+// bodyLines 返回目标表和日志窗格可用的总行数
+func (m *model) bodyLines() int {
+	if m.height <= 0 {
+		return 2*defaultRows + 3
+	}
+	return max(m.height-headerLines-footerLines, 6)
+}
+
+// tableRows 返回目标表显示的结果行数，目标表占主体约六成，其余给日志窗格
+func (m *model) tableRows() int {
+	return max(m.bodyLines()*3/5-2, 1)
+}
+
+// writeHeader 输出标题、进度条、预计剩余时间和计数
+func (m *model) writeHeader(b *strings.Builder) {
+	snap := m.snap
+
+	title := "GetRealityDomain 扫描中"
+	switch {
+	case m.done:
+		title = "GetRealityDomain 扫描已结束"
+	case m.stopping:
+		title = "GetRealityDomain 正在停止..."
+	}
+	b.WriteString(titleStyle.Render(title))
+	if m.paused {
+		b.WriteString("  " + pausedStyle.Render("[已暂停]"))
+	}
+	b.WriteString("\n")
+
+	if snap.Total > 0 {
+		percentage := snap.Percentage()
+		filled := int(percentage / 100 * progressBarSize)
+		fmt.Fprintf(b, "[%s%s] %.1f%%  剩余: %d / %d", strings.Repeat("█", filled),
+			strings.Repeat("░", progressBarSize-filled), percentage, snap.Remaining(), snap.Total)
+		if snap.Rate > 0 && !m.done {
+			eta := time.Duration(float64(snap.Remaining()) / snap.Rate * float64(time.Second))
+			fmt.Fprintf(b, "  预计剩余: %v", eta.Round(time.Second))
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("无限扫描模式\n")
+	}
+
+	fmt.Fprintf(b, "已扫描: %d | 合规: %d | 错误: %d | 跳过: %d | 速率: %.1f/s | 用时: %v\n",
+		snap.Scanned, snap.Feasible, snap.Errors, snap.Skipped, snap.Rate, snap.Elapsed.Round(time.Second))
+	b.WriteString(strings.Repeat("═", m.lineWidth()) + "\n")
+}
+
+// 目标表各列的宽度，证书域名列占用剩余宽度
+const (
+	colIndex  = 5
+	colIP     = 20
+	colPort   = 6
+	colGeo    = 6
+	colTime   = 8
+	colMargin = 5 // 列之间的空格
+)
+
+// writeTable 输出合规目标表
+func (m *model) writeTable(b *strings.Builder) {
+	domainWidth := max(m.lineWidth()-colIndex-colIP-colPort-colGeo-colTime-colMargin, 10)
+	b.WriteString(headStyle.Render(tableRow("#", "IP地址", "端口", "证书域名", "地区", "延迟", domainWidth)) + "\n")
+
+	rows := m.tableRows()
+	for i := m.offset; i < m.offset+rows; i++ {
+		if i >= len(m.results) {
+			if i == 0 {
+				b.WriteString(dimStyle.Render("（尚未发现符合条件的目标）"))
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		result := m.results[i]
+		cells := tableCells(strconv.Itoa(i+1), result.IP, strconv.Itoa(result.Port), result.CertDomain,
+			result.GeoCode, fmt.Sprintf("%dms", result.ResponseTime), domainWidth)
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(strings.Join(cells, " ")) + "\n")
+			continue
+		}
+		// 选中行整行反色，其余行按共用的配色规则着色
+		cells[0] = console.Dim(cells[0])
+		cells[4] = console.Country(result.GeoCode, cells[4])
+		cells[5] = console.Latency(result.ResponseTime, cells[5])
+		b.WriteString(strings.Join(cells, " ") + "\n")
+	}
+	b.WriteString(strings.Repeat("─", m.lineWidth()) + "\n")
+}
+
+// tableRow 按列宽截断并对齐一行
+func tableRow(index, ip, port, domain, geo, responseTime string, domainWidth int) string {
+	return strings.Join(tableCells(index, ip, port, domain, geo, responseTime, domainWidth), " ")
+}
+
+// tableCells 按列宽截断并补齐一行的各列，着色须在补齐之后进行
+func tableCells(index, ip, port, domain, geo, responseTime string, domainWidth int) []string {
+	cell := func(s string, width int) string {
+		return console.PadRight(console.Truncate(s, width), width)
+	}
+	return []string{
+		cell(index, colIndex), cell(ip, colIP), cell(port, colPort),
+		cell(domain, domainWidth), cell(geo, colGeo), cell(responseTime, colTime),
+	}
+}
+
+// writeLogs 输出日志窗格，显示最近的日志
+func (m *model) writeLogs(b *strings.Builder) {
+	lines := m.bodyLines() - m.tableRows() - 3 // 减去表头和两条分隔线
+	start := max(len(m.logs)-lines, 0)
+	for i := 0; i < lines; i++ {
+		if start+i < len(m.logs) {
+			b.WriteString(console.Truncate(m.logs[start+i], m.lineWidth()))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// help 返回快捷键提示
+func (m *model) help() string {
+	if m.done {
+		return "↑/↓ 选择  Enter 详情  q 退出"
+	}
+	help := "↑/↓ 选择  Enter 详情  q 停止扫描"
+	if m.opts.Pauser != nil {
+		help += "  p 暂停/继续"
+	}
+	return help
+}
+
+// detailView 显示选中目标的全部字段
+func (m *model) detailView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("目标详情") + "\n")
+	b.WriteString(strings.Repeat("═", m.lineWidth()) + "\n")
+	for _, field := range detailFields(*m.detail) {
+		label := console.PadRight(field[0], 14)
+		for i, line := range strings.Split(field[1], "\n") {
+			if i > 0 {
+				label = strings.Repeat(" ", 14)
+			}
+			fmt.Fprintf(&b, "%s %s\n", label, line)
+		}
+	}
+	if m.notice != "" {
+		b.WriteString("\n" + m.notice + "\n")
+	}
+	b.WriteString("\n" + dimStyle.Render("c 复制配置片段  Esc 返回"))
+	return b.String()
+}
+
+// detailFields 返回结果的全部已记录字段（名称、值），证书域名每行一个，未记录的可选字段省略
+func detailFields(result scanner.Result) [][2]string {
+	fields := [][2]string{
+		{"IP地址", result.IP},
+		{"原始输入", result.Origin},
+		{"端口", strconv.Itoa(result.Port)},
+	}
+	optional := func(name, value string) {
+		if value != "" && value != "0" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+
+	optional("SNI", result.SNI)
+	optional("SNI匹配", result.SNIMatch)
+	optional("证书域名", strings.ReplaceAll(result.CertDomain, ",", "\n"))
+	optional("证书颁发者", result.CertIssuer)
+	optional("证书指纹", result.CertSHA256)
+	optional("上次证书指纹", result.PrevCertSHA256)
+	optional("TLS版本", result.TLSVersion)
+	optional("ALPN", result.ALPN)
+	optional("椭圆曲线", result.Curve)
+	optional("地理位置", strings.TrimSpace(result.GeoCode+" "+result.City))
+	optional("解析地区", result.Region)
+	if result.ASN != 0 {
+		fields = append(fields, [2]string{"ASN", fmt.Sprintf("AS%d %s", result.ASN, result.ASOrg)})
+	}
+	optional("响应时间", durationMS(result.ResponseTime))
+	optional("TCP延迟", durationMS(result.TCPRTT))
+	if result.TTL > 0 {
+		fields = append(fields, [2]string{"TTL/跳数", fmt.Sprintf("%d / %d", result.TTL, result.Hops)})
+	}
+	optional("MTU探测", result.MTU)
+	optional("ClientHello探测", result.HelloProfile)
+	optional("中间设备干扰", result.Middlebox)
+	optional("疑似蜜罐", result.Suspicious)
+	optional("可信度", result.Confidence)
+	optional("各观测点", strings.ReplaceAll(scanner.FormatVantages(result.Vantages), ";", "\n"))
+	fields = append(fields, [2]string{"符合条件", strconv.FormatBool(result.Feasible)})
+	optional("错误类别", string(result.ErrorKind))
+	optional("错误", result.Error)
+	return fields
+}
+
+// durationMS 格式化毫秒数，0表示未记录
+func durationMS(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dms", ms)
+}
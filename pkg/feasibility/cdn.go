@@ -0,0 +1,125 @@
+package feasibility
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// cdnCheckTimeout CDN检测单次请求的超时时间
+const cdnCheckTimeout = 3 * time.Second
+
+// DetectCloudflareCDN 检测是否使用Cloudflare CDN，client为nil时使用http.DefaultClient
+func DetectCloudflareCDN(ctx context.Context, client scanner.HTTPClient, domain string) bool {
+	if domain == "" {
+		return false
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	// 方法1: 检查Cloudflare特有的/cdn-cgi/trace端点
+	url := fmt.Sprintf("https://%s/cdn-cgi/trace", domain)
+
+	// 发送请求
+	resp, err := httpGet(ctx, client, url)
+	if err == nil {
+		defer resp.Body.Close()
+
+		// 如果状态码是200，说明存在/cdn-cgi/trace端点
+		if resp.StatusCode == 200 {
+			// 读取响应内容进行进一步验证
+			body, err := io.ReadAll(resp.Body)
+			if err == nil {
+				bodyStr := string(body)
+				// 检查响应内容是否包含Cloudflare特征
+				if strings.Contains(bodyStr, "fl=") ||
+					strings.Contains(bodyStr, "h=") ||
+					strings.Contains(bodyStr, "colo=") ||
+					strings.Contains(bodyStr, "gateway=") {
+					return true
+				}
+			}
+		}
+	}
+
+	// 方法2: 检查HTTP响应头中的Cloudflare标识
+	resp2, err := httpGet(ctx, client, fmt.Sprintf("https://%s", domain))
+	if err == nil {
+		defer resp2.Body.Close()
+
+		// 检查响应头中的Cloudflare标识
+		server := resp2.Header.Get("Server")
+		cfRay := resp2.Header.Get("CF-Ray")
+		cfCache := resp2.Header.Get("CF-Cache-Status")
+
+		if strings.Contains(strings.ToLower(server), "cloudflare") ||
+			cfRay != "" ||
+			cfCache != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// httpGet 发送可随ctx取消的GET请求，使用较短的超时时间
+// 超时在响应体读取完毕前不能取消，因此随ctx一起交给调用方在关闭响应体后释放
+func httpGet(ctx context.Context, client scanner.HTTPClient, url string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, cdnCheckTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose 关闭响应体时释放对应的超时ctx
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// DetectCDN 检测是否使用CDN（通用实现）
+func DetectCDN(ctx context.Context, client scanner.HTTPClient, domain string) bool {
+	// 首先检测Cloudflare
+	if DetectCloudflareCDN(ctx, client, domain) {
+		return true
+	}
+
+	// 常见CDN提供商的标识
+	cdnProviders := []string{
+		"cloudflare", "amazonaws", "fastly", "maxcdn", "keycdn",
+		"jsdelivr", "unpkg", "cdnjs", "bootstrapcdn", "fontawesome",
+		"akamai", "edgecast", "chinacache", "qiniu", "upyun",
+	}
+
+	// 简单的域名匹配检测
+	lowerDomain := strings.ToLower(domain)
+	for _, provider := range cdnProviders {
+		if strings.Contains(lowerDomain, provider) {
+			return true
+		}
+	}
+
+	return false
+}
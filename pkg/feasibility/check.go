@@ -0,0 +1,207 @@
+package feasibility
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// FeasibilityCheck 单项可行性检查，Run返回nil表示通过，否则返回未通过的原因。
+// 内置规则（TLS版本、ALPN、椭圆曲线、证书、CDN、连通性）都以此实现，
+// 自定义检查（如自有的CDN名单、组织策略）可用Register注册或加入Checker.Extra，无需修改内置规则
+type FeasibilityCheck interface {
+	Name() string                                          // 检查名称，用于调试输出和日志
+	Run(ctx context.Context, result *scanner.Result) error // 检查扫描结果
+}
+
+// SlowCheck 可选接口：Slow返回true的检查需要网络请求等耗时操作。Checker.Defer为true时Feasible跳过这类检查，
+// 由PostChecker在扫描线程之外进行；内置的CDN检测和连通性检测即是如此
+type SlowCheck interface {
+	Slow() bool
+}
+
+// isSlow 判断检查是否为耗时检查
+func isSlow(check FeasibilityCheck) bool {
+	slow, ok := check.(SlowCheck)
+	return ok && slow.Slow()
+}
+
+// checkDetail 可选接口：检查通过时说明实际值，供Explain输出，未实现时显示为"通过"
+type checkDetail interface {
+	Detail(result *scanner.Result) string
+}
+
+// NewCheck 以函数创建可行性检查
+func NewCheck(name string, run func(ctx context.Context, result *scanner.Result) error) FeasibilityCheck {
+	return &ruleCheck{name: name, run: run}
+}
+
+// ruleCheck 由函数实现的检查，detail不为nil时实现checkDetail
+type ruleCheck struct {
+	name   string
+	run    func(ctx context.Context, result *scanner.Result) error
+	detail func(result *scanner.Result) string
+	slow   bool // 需要网络请求
+}
+
+func (r *ruleCheck) Name() string { return r.name }
+
+func (r *ruleCheck) Slow() bool { return r.slow }
+
+func (r *ruleCheck) Run(ctx context.Context, result *scanner.Result) error {
+	return r.run(ctx, result)
+}
+
+func (r *ruleCheck) Detail(result *scanner.Result) string {
+	if r.detail == nil {
+		return "通过"
+	}
+	return r.detail(result)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []FeasibilityCheck // 已注册的检查，按注册顺序
+)
+
+// Register 注册自定义可行性检查，所有Checker都会在内置规则（或自定义表达式）之后依次运行已注册的检查，
+// 通常在init中调用以编译进程序；名称为空或重复时panic
+func Register(check FeasibilityCheck) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name := check.Name()
+	if name == "" {
+		panic("feasibility: 检查名称不能为空")
+	}
+	if slices.ContainsFunc(registry, func(c FeasibilityCheck) bool { return c.Name() == name }) {
+		panic("feasibility: 重复注册的检查 " + name)
+	}
+	registry = append(registry, check)
+}
+
+// Registered 返回已注册的检查，按注册顺序
+func Registered() []FeasibilityCheck {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(registry)
+}
+
+// fieldCheck 要求结果的某个字段等于固定值
+func fieldCheck(name, required string, field func(result *scanner.Result) string) FeasibilityCheck {
+	describe := func(result *scanner.Result) string {
+		return fmt.Sprintf("需要 %s，实际 %q", required, field(result))
+	}
+	return &ruleCheck{
+		name: name,
+		run: func(_ context.Context, result *scanner.Result) error {
+			if field(result) != required {
+				return errors.New(describe(result))
+			}
+			return nil
+		},
+		detail: describe,
+	}
+}
+
+// builtinRules 不需要网络请求的内置规则
+var builtinRules = []FeasibilityCheck{
+	fieldCheck("TLS版本", RequiredTLSVersion, func(result *scanner.Result) string { return result.TLSVersion }),
+	fieldCheck("ALPN", RequiredALPN, func(result *scanner.Result) string { return result.ALPN }),
+	fieldCheck("椭圆曲线", RequiredCurve, func(result *scanner.Result) string { return result.Curve }),
+	&ruleCheck{
+		name: "证书域名",
+		run: func(_ context.Context, result *scanner.Result) error {
+			if !isValidRealityDomain(result.CertDomain) {
+				return fmt.Errorf("需要包含\".\"的域名，实际 %q", result.CertDomain)
+			}
+			return nil
+		},
+		detail: func(result *scanner.Result) string {
+			return fmt.Sprintf("需要包含\".\"的域名，实际 %q", result.CertDomain)
+		},
+	},
+	&ruleCheck{
+		name: "证书颁发者",
+		run: func(_ context.Context, result *scanner.Result) error {
+			if result.CertIssuer == "" {
+				return errors.New("不能为空，实际 \"\"")
+			}
+			return nil
+		},
+		detail: func(result *scanner.Result) string {
+			return fmt.Sprintf("不能为空，实际 %q", result.CertIssuer)
+		},
+	},
+}
+
+// countryCheck 要求地理位置在允许的国家中
+func countryCheck(countries []string) FeasibilityCheck {
+	describe := func(result *scanner.Result) string {
+		return fmt.Sprintf("允许 %s，实际 %q", strings.Join(countries, ","), result.GeoCode)
+	}
+	return &ruleCheck{
+		name: "国家/地区",
+		run: func(_ context.Context, result *scanner.Result) error {
+			if !slices.Contains(countries, result.GeoCode) {
+				return errors.New(describe(result))
+			}
+			return nil
+		},
+		detail: describe,
+	}
+}
+
+// filterCheck 自定义表达式，设置后代替内置规则
+func filterCheck(filter *Filter, client scanner.HTTPClient, cache *DomainCache) FeasibilityCheck {
+	return &ruleCheck{
+		name: "自定义表达式",
+		run: func(ctx context.Context, result *scanner.Result) error {
+			matched, err := filter.match(ctx, client, cache, *result)
+			if err != nil {
+				logger.Debug("表达式求值失败", "ip", result.IP, "error", err)
+				return fmt.Errorf("%s（求值失败: %v）", filter, err)
+			}
+			if !matched {
+				return errors.New(filter.String())
+			}
+			return nil
+		},
+		detail: func(*scanner.Result) string { return filter.String() },
+		slow:   filter.needCDN || filter.needReachable,
+	}
+}
+
+// cdnCheck 要求证书域名未使用Cloudflare CDN
+func cdnCheck(client scanner.HTTPClient, cache *DomainCache) FeasibilityCheck {
+	return &ruleCheck{
+		name: "CDN",
+		run: func(ctx context.Context, result *scanner.Result) error {
+			if cache.CDN(ctx, client, result.CertDomain) {
+				return errors.New("检测到Cloudflare CDN（/cdn-cgi/trace 或响应头）")
+			}
+			return nil
+		},
+		detail: func(*scanner.Result) string { return "未检测到Cloudflare CDN" },
+		slow:   true,
+	}
+}
+
+// connectivityCheck 要求可以ping通证书域名
+func connectivityCheck(cache *DomainCache) FeasibilityCheck {
+	return &ruleCheck{
+		name: "连通性",
+		run: func(ctx context.Context, result *scanner.Result) error {
+			if !cache.Reachable(ctx, result.CertDomain) {
+				return fmt.Errorf("ping %s 失败", result.CertDomain)
+			}
+			return nil
+		},
+		detail: func(result *scanner.Result) string { return fmt.Sprintf("ping %s 成功", result.CertDomain) },
+		slow:   true,
+	}
+}
@@ -0,0 +1,253 @@
+package feasibility
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 可信度等级，由通过的独立检查数决定
+const (
+	GradeA = "A" // 全部检查通过
+	GradeB = "B" // 有一项检查未通过
+	GradeC = "C" // 两项及以上检查未通过
+)
+
+// 可信度的独立检查
+const (
+	CheckHandshake    = "handshake"    // 重复握手均为TLS 1.3 + h2，且证书不变
+	CheckH2           = "h2"           // 以SNI发送HTTP/2请求并收到响应
+	CheckConnectivity = "connectivity" // ping证书域名
+	CheckCDN          = "cdn"          // 未检测到Cloudflare CDN
+)
+
+// DefaultConfidenceRepeats 默认的重复握手次数
+const DefaultConfidenceRepeats = 2
+
+// confidenceTimeout 每次重复握手和HTTP/2请求的超时
+const confidenceTimeout = 5 * time.Second
+
+// ConfidenceGrader 对符合条件的结果进行多项独立检查，按通过的检查数在Confidence中记录可信度等级（A/B/C），
+// 以分级的可信度代替单一的是否符合条件；设置了MinGrade时，低于该等级的结果改为不符合条件。
+// 由多个协程并发进行，转发顺序与输入不完全一致
+type ConfidenceGrader struct {
+	Dialer     scanner.Dialer     // 重复握手和HTTP/2请求使用的拨号器，为nil时使用net.Dialer
+	HTTPClient scanner.HTTPClient // CDN检测使用的HTTP客户端，为nil时使用http.DefaultClient
+	Cache      *DomainCache       // 按域名缓存CDN检测和连通性检测的结果，为nil时不缓存
+	Repeats    int                // 重复握手次数，0表示DefaultConfidenceRepeats
+	Workers    int                // 并发检查的协程数，0表示1
+	MinGrade   string             // 最低可信度等级，为空时不排除任何结果
+
+	grades   [3]atomic.Int64 // A、B、C各等级的结果数
+	rejected atomic.Int64
+}
+
+// Watch 转发扫描结果，符合条件的结果附加可信度等级，返回的通道在results关闭后关闭；
+// ctx取消后不再转发，但继续读取results直到其关闭
+func (g *ConfidenceGrader) Watch(ctx context.Context, results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	workers := max(g.Workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for result := range results {
+				if result.Error == "" && result.Feasible && ctx.Err() == nil {
+					g.grade(ctx, &result)
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Grades 返回A、B、C各等级的结果数
+func (g *ConfidenceGrader) Grades() (int64, int64, int64) {
+	return g.grades[0].Load(), g.grades[1].Load(), g.grades[2].Load()
+}
+
+// Rejected 返回因低于最低可信度等级而改为不符合条件的结果数
+func (g *ConfidenceGrader) Rejected() int64 {
+	return g.rejected.Load()
+}
+
+// ValidGrade 检查可信度等级是否有效
+func ValidGrade(grade string) bool {
+	return grade == GradeA || grade == GradeB || grade == GradeC
+}
+
+// grade 进行各项检查并记录可信度等级
+func (g *ConfidenceGrader) grade(ctx context.Context, result *scanner.Result) {
+	failed := g.FailedChecks(ctx, *result)
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch len(failed) {
+	case 0:
+		result.Confidence = GradeA
+		g.grades[0].Add(1)
+	case 1:
+		result.Confidence = GradeB
+		g.grades[1].Add(1)
+	default:
+		result.Confidence = GradeC
+		g.grades[2].Add(1)
+	}
+	logger.Debug("可信度", "ip", result.IP, "port", result.Port, "grade", result.Confidence, "failed", strings.Join(failed, ","))
+
+	// 等级字母越靠后可信度越低
+	if g.MinGrade != "" && result.Confidence > g.MinGrade {
+		result.Feasible = false
+		g.rejected.Add(1)
+	}
+}
+
+// FailedChecks 并发进行各项独立检查，返回未通过的检查
+func (g *ConfidenceGrader) FailedChecks(ctx context.Context, result scanner.Result) []string {
+	serverName := targetServerName(result)
+	checks := map[string]func() bool{
+		CheckHandshake:    func() bool { return g.repeatHandshakes(ctx, result, serverName) },
+		CheckH2:           func() bool { return g.h2Request(ctx, result, serverName) },
+		CheckConnectivity: func() bool { return g.Cache.Reachable(ctx, serverName) },
+		CheckCDN:          func() bool { return !g.Cache.CDN(ctx, g.HTTPClient, serverName) },
+	}
+
+	var mu sync.Mutex
+	var failed []string
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !check() {
+				mu.Lock()
+				failed = append(failed, name)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	slices.Sort(failed)
+	return failed
+}
+
+// repeatHandshakes 重复握手，全部为TLS 1.3 + h2且证书与扫描时一致时返回true
+func (g *ConfidenceGrader) repeatHandshakes(ctx context.Context, result scanner.Result, serverName string) bool {
+	repeats := g.Repeats
+	if repeats <= 0 {
+		repeats = DefaultConfidenceRepeats
+	}
+	for range repeats {
+		if err := g.handshake(ctx, result, serverName); err != nil {
+			logger.Debug("重复握手未通过", "ip", result.IP, "port", result.Port, "error", err)
+			return false
+		}
+	}
+	return true
+}
+
+// handshake 完成一次TLS握手并检查协议版本、ALPN和证书
+func (g *ConfidenceGrader) handshake(ctx context.Context, result scanner.Result, serverName string) error {
+	ctx, cancel := context.WithTimeout(ctx, confidenceTimeout)
+	defer cancel()
+	conn, err := g.dial(ctx, result)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		NextProtos:         []string{RequiredALPN, "http/1.1"},
+		MinVersion:         tls.VersionTLS13,
+		CurvePreferences:   []tls.CurveID{tls.X25519},
+	})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return err
+	}
+	state := tlsConn.ConnectionState()
+	if state.NegotiatedProtocol != RequiredALPN {
+		return fmt.Errorf("ALPN为%q", state.NegotiatedProtocol)
+	}
+	if result.CertSHA256 != "" && len(state.PeerCertificates) > 0 {
+		sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+		if fingerprint := hex.EncodeToString(sum[:]); !strings.EqualFold(fingerprint, result.CertSHA256) {
+			return fmt.Errorf("证书与扫描时不同: %s", fingerprint)
+		}
+	}
+	return nil
+}
+
+// h2Request 以SNI经由HTTP/2请求首页，收到HTTP/2响应（任意状态码）时返回true
+func (g *ConfidenceGrader) h2Request(ctx context.Context, result scanner.Result, serverName string) bool {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return g.dial(ctx, result)
+		},
+		TLSClientConfig:   &tls.Config{ServerName: serverName, InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}
+	defer transport.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(ctx, confidenceTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+serverName+"/", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		logger.Debug("HTTP/2请求失败", "ip", result.IP, "port", result.Port, "error", err)
+		return false
+	}
+	resp.Body.Close()
+	return resp.ProtoMajor == 2
+}
+
+// dial 连接结果对应的地址
+func (g *ConfidenceGrader) dial(ctx context.Context, result scanner.Result) (net.Conn, error) {
+	dialer := g.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	return dialer.DialContext(ctx, "tcp", net.JoinHostPort(result.IP, strconv.Itoa(result.Port)))
+}
+
+// targetServerName 返回访问结果对应目标使用的域名：握手时的SNI，IP目标为证书中第一个非通配符域名
+func targetServerName(result scanner.Result) string {
+	if result.SNI != "" {
+		return result.SNI
+	}
+	domains := strings.Split(result.CertDomain, ",")
+	for _, domain := range domains {
+		domain = strings.TrimSpace(domain)
+		if domain != "" && !strings.HasPrefix(domain, "*.") {
+			return domain
+		}
+	}
+	return strings.TrimPrefix(strings.TrimSpace(domains[0]), "*.")
+}
@@ -0,0 +1,42 @@
+package feasibility
+
+import (
+	"context"
+	"net"
+	"os/exec"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// CheckDomainConnectivity 检查域名连通性 - 通过ping域名来测试
+func CheckDomainConnectivity(ctx context.Context, domain string) bool {
+	if !pingable(domain) {
+		return false
+	}
+
+	// 使用ping命令测试域名连通性
+	return pingDomain(ctx, domain)
+}
+
+// pingable 判断是否可以对域名进行连通性测试
+func pingable(domain string) bool {
+	// 如果传入的是空域名或者是IP地址，则跳过ping测试
+	if domain == "" || net.ParseIP(domain) != nil {
+		return false // 非域名要通过ping来排除
+	}
+
+	// 验证域名格式
+	return scanner.ValidateDomainName(domain)
+}
+
+// pingDomain 使用ping命令测试域名连通性
+func pingDomain(ctx context.Context, domain string) bool {
+	// 构造ping命令，发送3个包，超时5秒
+	cmd := exec.CommandContext(ctx, "ping", "-c", "3", "-W", "5", domain)
+
+	// 执行ping命令
+	err := cmd.Run()
+
+	// 如果ping成功（返回码为0），则认为域名连通性良好
+	return err == nil
+}
@@ -0,0 +1,122 @@
+package feasibility
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Filter 基于表达式的自定义可行性判断
+// 表达式可引用scanner.Result的字段以及CDN/Reachable两个需要额外探测的变量，例如:
+//
+//	TLSVersion == "TLS 1.3" && ResponseTime < 120 && GeoCode in ["JP","KR"] && !CDN
+type Filter struct {
+	expression    string
+	program       *vm.Program
+	needCDN       bool // 表达式是否引用了CDN
+	needReachable bool // 表达式是否引用了Reachable
+}
+
+// filterEnv 表达式可用的变量及其类型
+func filterEnv() map[string]interface{} {
+	return map[string]interface{}{
+		"IP":           "",
+		"Origin":       "",
+		"Port":         0,
+		"CertDomain":   "",
+		"CertIssuer":   "",
+		"TLSVersion":   "",
+		"ALPN":         "",
+		"Curve":        "",
+		"GeoCode":      "",
+		"City":         "",
+		"ASN":          0,
+		"ASOrg":        "",
+		"ResponseTime": int64(0),
+		"TCPRTT":       int64(0),
+		"Hops":         0,
+		"CDN":          false, // 证书域名是否使用Cloudflare CDN
+		"Reachable":    false, // 证书域名是否能ping通
+	}
+}
+
+// identifierCollector 收集表达式中引用的变量名
+type identifierCollector struct {
+	names map[string]bool
+}
+
+func (c *identifierCollector) Visit(node *ast.Node) {
+	if ident, ok := (*node).(*ast.IdentifierNode); ok {
+		c.names[ident.Value] = true
+	}
+}
+
+// NewFilter 编译可行性表达式
+func NewFilter(expression string) (*Filter, error) {
+	program, err := expr.Compile(expression, expr.Env(filterEnv()), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("编译表达式失败: %v", err)
+	}
+
+	// 只有表达式用到时才执行耗时的CDN检测和ping测试
+	collector := &identifierCollector{names: make(map[string]bool)}
+	node := program.Node()
+	ast.Walk(&node, collector)
+
+	return &Filter{
+		expression:    expression,
+		program:       program,
+		needCDN:       collector.names["CDN"],
+		needReachable: collector.names["Reachable"],
+	}, nil
+}
+
+// Match 判断扫描结果是否满足表达式
+// client用于CDN检测，为nil时使用http.DefaultClient
+func (f *Filter) Match(ctx context.Context, client scanner.HTTPClient, result scanner.Result) (bool, error) {
+	return f.match(ctx, client, nil, result)
+}
+
+// match 判断扫描结果是否满足表达式，CDN和Reachable的检测结果按域名缓存在cache中，cache为nil时不缓存
+func (f *Filter) match(ctx context.Context, client scanner.HTTPClient, cache *DomainCache, result scanner.Result) (bool, error) {
+	env := filterEnv()
+	env["IP"] = result.IP
+	env["Origin"] = result.Origin
+	env["Port"] = result.Port
+	env["CertDomain"] = result.CertDomain
+	env["CertIssuer"] = result.CertIssuer
+	env["TLSVersion"] = result.TLSVersion
+	env["ALPN"] = result.ALPN
+	env["Curve"] = result.Curve
+	env["GeoCode"] = result.GeoCode
+	env["City"] = result.City
+	env["ASN"] = int(result.ASN)
+	env["ASOrg"] = result.ASOrg
+	env["ResponseTime"] = result.ResponseTime
+	env["TCPRTT"] = result.TCPRTT
+	env["Hops"] = result.Hops
+
+	if f.needCDN {
+		env["CDN"] = cache.CDN(ctx, client, result.CertDomain)
+	}
+	if f.needReachable {
+		env["Reachable"] = cache.ping(ctx, result.CertDomain)
+	}
+
+	output, err := expr.Run(f.program, env)
+	if err != nil {
+		return false, err
+	}
+
+	matched, _ := output.(bool)
+	return matched, nil
+}
+
+// String 返回表达式原文
+func (f *Filter) String() string {
+	return f.expression
+}
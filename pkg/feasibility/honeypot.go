@@ -0,0 +1,214 @@
+package feasibility
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 蜜罐检测的疑点，记录在结果的Suspicious中
+const (
+	SuspectAllPorts   = "all-ports"   // 随机选取的高位端口全部可以连接，疑似接受任意端口的蜜罐
+	SuspectSharedCert = "shared-cert" // 同一证书出现在多个互不相关的网络中
+	SuspectFastEmpty  = "fast-empty"  // 握手极快但HTTP响应为空
+	SuspectSinkhole   = "sinkhole"    // 证书属于已知的sinkhole或扣押页面
+)
+
+// 蜜罐检测的参数
+const (
+	honeypotPortProbes    = 3               // 测试的随机高位端口数
+	honeypotProbeTimeout  = 2 * time.Second // 每次端口测试和HTTP请求的超时
+	honeypotFastHandshake = 3               // 握手耗时低于该值(毫秒)视为极快
+	honeypotSharedNets    = 3               // 同一证书出现在多少个不同网络中视为可疑
+)
+
+// SinkholeMarkers 已知sinkhole、研究机构蜜罐和扣押页面证书中常见的名称（不区分大小写）
+var SinkholeMarkers = []string{
+	"sinkhole",
+	"shadowserver",
+	"honeypot",
+	"abuse.ch",
+	"spamhaus",
+	"seized",
+	"snakeoil",
+}
+
+// HoneypotDetector 对符合条件的结果进行蜜罐和sinkhole的启发式检查，疑点记录在Suspicious中，
+// 结果本身仍符合条件，由使用者决定是否采用；同一证书出现在多个网络中的判断基于此前经过的所有握手结果。
+// 由多个协程并发进行，转发顺序与输入不完全一致
+type HoneypotDetector struct {
+	Dialer  scanner.Dialer // 测试随机端口使用的拨号器，为nil时使用net.Dialer
+	Workers int            // 并发检查的协程数，0表示1
+
+	mu       sync.Mutex
+	certNets map[string]map[string]bool // 证书指纹 -> 出现过的网络（ASN，未知时为/16）
+	flagged  atomic.Int64
+}
+
+// Watch 转发扫描结果，对符合条件的结果进行检查，返回的通道在results关闭后关闭；
+// ctx取消后不再转发，但继续读取results直到其关闭
+func (d *HoneypotDetector) Watch(ctx context.Context, results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	workers := max(d.Workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for result := range results {
+				shared := d.observeCert(result)
+				if result.Error == "" && result.Feasible && ctx.Err() == nil {
+					if suspects := d.inspect(ctx, result, shared); len(suspects) > 0 {
+						result.Suspicious = strings.Join(suspects, ",")
+						d.flagged.Add(1)
+						logger.Debug("疑似蜜罐", "ip", result.IP, "port", result.Port, "suspicious", result.Suspicious)
+					}
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Flagged 返回存在疑点的结果数
+func (d *HoneypotDetector) Flagged() int64 {
+	return d.flagged.Load()
+}
+
+// Suspects 检查单个结果，返回发现的疑点；不包括需要多个结果才能判断的shared-cert
+func (d *HoneypotDetector) Suspects(ctx context.Context, result scanner.Result) []string {
+	return d.inspect(ctx, result, false)
+}
+
+// observeCert 记录证书出现的网络，返回该证书是否已出现在足够多的不同网络中
+func (d *HoneypotDetector) observeCert(result scanner.Result) bool {
+	if result.CertSHA256 == "" {
+		return false
+	}
+	network := "AS" + strconv.FormatUint(uint64(result.ASN), 10)
+	if result.ASN == 0 {
+		ip, err := netip.ParseAddr(result.IP)
+		if err != nil {
+			return false
+		}
+		prefix, _ := ip.Unmap().Prefix(16)
+		network = prefix.String()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.certNets == nil {
+		d.certNets = make(map[string]map[string]bool)
+	}
+	nets := d.certNets[result.CertSHA256]
+	if nets == nil {
+		nets = make(map[string]bool)
+		d.certNets[result.CertSHA256] = nets
+	}
+	nets[network] = true
+	return len(nets) >= honeypotSharedNets
+}
+
+// inspect 检查单个结果，返回发现的疑点
+func (d *HoneypotDetector) inspect(ctx context.Context, result scanner.Result, sharedCert bool) []string {
+	var suspects []string
+	if isSinkholeCert(result) {
+		suspects = append(suspects, SuspectSinkhole)
+	}
+	if sharedCert {
+		suspects = append(suspects, SuspectSharedCert)
+	}
+	if d.acceptsAnyPort(ctx, result) {
+		suspects = append(suspects, SuspectAllPorts)
+	}
+	if result.ResponseTime-result.TCPRTT < honeypotFastHandshake && d.emptyContent(ctx, result) {
+		suspects = append(suspects, SuspectFastEmpty)
+	}
+	return suspects
+}
+
+// isSinkholeCert 检查证书域名和颁发者是否含有已知sinkhole的名称
+func isSinkholeCert(result scanner.Result) bool {
+	text := strings.ToLower(result.CertDomain + " " + result.CertIssuer)
+	for _, marker := range SinkholeMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsAnyPort 测试几个随机高位端口，全部可以连接时返回true；正常服务器的这些端口通常是关闭的
+func (d *HoneypotDetector) acceptsAnyPort(ctx context.Context, result scanner.Result) bool {
+	for range honeypotPortProbes {
+		port := 40000 + rand.IntN(20000)
+		if port == result.Port {
+			port++
+		}
+		conn, err := d.dial(ctx, net.JoinHostPort(result.IP, strconv.Itoa(port)))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+	return true
+}
+
+// emptyContent 以握手时的SNI请求首页，响应体为空或请求失败时返回true
+func (d *HoneypotDetector) emptyContent(ctx context.Context, result scanner.Result) bool {
+	serverName := targetServerName(result)
+	address := net.JoinHostPort(result.IP, strconv.Itoa(result.Port))
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return d.dial(ctx, address)
+		},
+		TLSClientConfig:   &tls.Config{ServerName: serverName, InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}
+	defer transport.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(ctx, honeypotProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+serverName+"/", nil)
+	if err != nil {
+		return true
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return true
+	}
+	defer resp.Body.Close()
+	n, _ := io.CopyN(io.Discard, resp.Body, 1)
+	return n == 0
+}
+
+// dial 以较短的超时连接地址
+func (d *HoneypotDetector) dial(ctx context.Context, address string) (net.Conn, error) {
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, honeypotProbeTimeout)
+	defer cancel()
+	return dialer.DialContext(ctx, "tcp", address)
+}
@@ -0,0 +1,105 @@
+package feasibility
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// DefaultDomainCacheTTL 后置检查结果的默认缓存时间
+const DefaultDomainCacheTTL = 10 * time.Minute
+
+// DomainCache 按域名缓存CDN检测和ping连通性检测的结果。大规模扫描中成百上千个IP常出示同一证书域名，
+// 缓存期内同一域名只请求或ping一次，并发的相同检查等待第一次的结果；nil表示不缓存，每次都重新检测
+type DomainCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[domainKey]*domainEntry
+}
+
+// domainKey 缓存键：检查类型和域名
+type domainKey struct {
+	check  string
+	domain string
+}
+
+// domainEntry 单项检查的结果，done关闭后value可读
+type domainEntry struct {
+	done    chan struct{}
+	value   bool
+	expires time.Time
+}
+
+// NewDomainCache 创建后置检查缓存，ttl不大于0时使用DefaultDomainCacheTTL
+func NewDomainCache(ttl time.Duration) *DomainCache {
+	if ttl <= 0 {
+		ttl = DefaultDomainCacheTTL
+	}
+	return &DomainCache{ttl: ttl, entries: make(map[domainKey]*domainEntry)}
+}
+
+// CDN 返回域名是否使用Cloudflare CDN，见DetectCloudflareCDN
+func (c *DomainCache) CDN(ctx context.Context, client scanner.HTTPClient, domain string) bool {
+	return c.lookup(ctx, "cdn", domain, func() bool { return DetectCloudflareCDN(ctx, client, domain) })
+}
+
+// Reachable 返回域名是否可以ping通，见CheckDomainConnectivity
+func (c *DomainCache) Reachable(ctx context.Context, domain string) bool {
+	return pingable(domain) && c.ping(ctx, domain)
+}
+
+// ping 不校验域名，直接ping，供自定义表达式的Reachable变量使用
+func (c *DomainCache) ping(ctx context.Context, domain string) bool {
+	return c.lookup(ctx, "ping", domain, func() bool { return pingDomain(ctx, domain) })
+}
+
+// lookup 返回缓存的检查结果，未命中或已过期时调用run；其他协程正在检查同一域名时等待其结果，ctx取消时放弃等待
+func (c *DomainCache) lookup(ctx context.Context, check, domain string, run func() bool) bool {
+	if c == nil {
+		return run()
+	}
+	key := domainKey{check: check, domain: domain}
+
+	c.mu.Lock()
+	entry := c.entries[key]
+	if entry != nil {
+		select {
+		case <-entry.done:
+			if time.Now().After(entry.expires) {
+				entry = nil
+			}
+		default:
+		}
+	}
+
+	if entry == nil {
+		entry = &domainEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		entry.value = run()
+		entry.expires = time.Now().Add(c.ttl)
+		close(entry.done)
+
+		// 因取消而失败的结果不代表域名的实际情况，不缓存
+		if ctx.Err() != nil {
+			c.mu.Lock()
+			if c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+		return entry.value
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.value
+	case <-ctx.Done():
+		return false
+	}
+}
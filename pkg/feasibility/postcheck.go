@@ -0,0 +1,60 @@
+package feasibility
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// PostChecker 后置检查阶段：对通过了快速检查的结果进行CDN检测、连通性检测等耗时检查，未通过时改为不符合条件。
+// 扫描器的Judge应使用设置了Defer的同一个Checker，耗时检查由独立的协程池进行，不占用扫描线程；
+// 转发顺序与输入不完全一致
+type PostChecker struct {
+	Checker *Checker // 可行性检查器
+	Workers int      // 并发检查的协程数，0表示1
+
+	rejected atomic.Int64
+}
+
+// Watch 转发扫描结果，符合条件的结果进行耗时检查，返回的通道在results关闭后关闭；
+// ctx取消后不再转发，但继续读取results直到其关闭，下游提前停止读取时上游也不会阻塞
+func (p *PostChecker) Watch(ctx context.Context, results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	workers := max(p.Workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for result := range results {
+				if result.Error == "" && result.Feasible {
+					switch {
+					case ctx.Err() != nil:
+						// 已取消时无法完成检查，不能视为符合条件
+						result.Feasible = false
+					case !p.Checker.PostFeasible(ctx, result):
+						result.Feasible = false
+						p.rejected.Add(1)
+					}
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Rejected 返回未通过耗时检查的结果数
+func (p *PostChecker) Rejected() int64 {
+	return p.rejected.Load()
+}
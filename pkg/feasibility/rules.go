@@ -0,0 +1,166 @@
+// Package feasibility 判断扫描结果是否适合作为Reality目标：
+// 内置规则（TLS 1.3、X25519、h2、非CDN、连通性）、基于表达式的自定义规则，
+// 以及通过FeasibilityCheck接口注册的自定义检查。
+package feasibility
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+var logger = logging.For("feasibility")
+
+// RealityRequirements Reality协议要求的常量
+const (
+	RequiredTLSVersion = "TLS 1.3"
+	RequiredALPN       = "h2"
+	RequiredCurve      = "X25519"
+)
+
+// Checker Reality可行性检查器
+type Checker struct {
+	PingDomain bool     // 是否ping证书域名测试连通性
+	Filter     *Filter  // 自定义可行性表达式，为nil时使用内置规则
+	Countries  []string // 允许的国家代码，设置后地理位置不在名单中的结果均不符合
+
+	// Extra 附加的检查，在内置规则（或自定义表达式）和已注册的检查之后运行
+	Extra []FeasibilityCheck
+
+	// Defer 为true时Feasible只进行不需要网络请求的检查，CDN检测、连通性检测等耗时检查（SlowCheck）
+	// 留给PostChecker在扫描线程之外进行，避免缓慢的外部请求占用扫描线程
+	Defer bool
+
+	HTTPClient scanner.HTTPClient // CDN检测使用的HTTP客户端，为nil时使用http.DefaultClient
+	Cache      *DomainCache       // 按证书域名缓存CDN检测和连通性检测的结果，为nil时不缓存
+}
+
+// Feasible 判断扫描结果是否符合Reality要求（设置了自定义表达式时以表达式代替内置规则），
+// 设置了Defer时跳过耗时检查。可直接作为 scanner.Config.Judge 使用
+func (c *Checker) Feasible(ctx context.Context, result scanner.Result) bool {
+	checks := c.Checks()
+	if c.Defer {
+		checks = slices.DeleteFunc(checks, isSlow)
+	}
+	return runChecks(ctx, checks, &result)
+}
+
+// PostFeasible 只进行耗时检查，与设置了Defer的Feasible合起来等同于全部检查
+func (c *Checker) PostFeasible(ctx context.Context, result scanner.Result) bool {
+	checks := slices.DeleteFunc(c.Checks(), func(check FeasibilityCheck) bool { return !isSlow(check) })
+	return runChecks(ctx, checks, &result)
+}
+
+// IsRealityFeasible 检查扫描结果是否符合Reality协议要求，运行Checks返回的全部检查（不受Defer影响）
+func (c *Checker) IsRealityFeasible(ctx context.Context, sr *scanner.Result) bool {
+	// Reality协议的5个要求：
+	// 1. 使用 TLS 1.3 协议
+	// 2. 使用 X25519 签名算法
+	// 3. 支持 HTTP/2 协议（H2）
+	// 4. 不使用 CDN (特别是Cloudflare)
+	// 5. 中国境内可直接访问
+	return runChecks(ctx, c.Checks(), sr)
+}
+
+// Checks 返回Feasible依次运行的全部检查：国家/地区、内置规则或自定义表达式、已注册的检查、附加的检查
+func (c *Checker) Checks() []FeasibilityCheck {
+	var checks []FeasibilityCheck
+	if len(c.Countries) > 0 {
+		checks = append(checks, countryCheck(c.Countries))
+	}
+	if c.Filter != nil {
+		checks = append(checks, filterCheck(c.Filter, c.HTTPClient, c.Cache))
+	} else {
+		checks = append(checks, c.builtinChecks()...)
+	}
+	return append(checks, c.customChecks()...)
+}
+
+// builtinChecks 返回内置规则，需要网络请求的CDN检测和连通性检测在最后
+func (c *Checker) builtinChecks() []FeasibilityCheck {
+	checks := append(slices.Clone(builtinRules), cdnCheck(c.HTTPClient, c.Cache))
+	if c.PingDomain {
+		checks = append(checks, connectivityCheck(c.Cache))
+	}
+	return checks
+}
+
+// customChecks 返回已注册的检查和附加的检查
+func (c *Checker) customChecks() []FeasibilityCheck {
+	return append(Registered(), c.Extra...)
+}
+
+// runChecks 依次运行检查，在第一项未通过的检查处停止
+func runChecks(ctx context.Context, checks []FeasibilityCheck, result *scanner.Result) bool {
+	for _, check := range checks {
+		if err := check.Run(ctx, result); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RuleOutcome 单条可行性规则的判断结果
+type RuleOutcome struct {
+	Rule   string // 规则名称
+	Passed bool   // 是否通过
+	Detail string // 实际值或未通过的原因
+}
+
+// Explain 逐条检查扫描结果并说明每条规则通过或未通过的原因，用于调试单个目标；
+// 规则与Feasible一致，但不会在第一条未通过的规则处停止
+func (c *Checker) Explain(ctx context.Context, result scanner.Result) []RuleOutcome {
+	var outcomes []RuleOutcome
+	for _, check := range c.Checks() {
+		outcome := RuleOutcome{Rule: check.Name(), Passed: true, Detail: "通过"}
+		if err := check.Run(ctx, &result); err != nil {
+			outcome.Passed, outcome.Detail = false, err.Error()
+		} else if d, ok := check.(checkDetail); ok {
+			outcome.Detail = d.Detail(&result)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// isValidRealityDomain 检查域名是否适合用于Reality
+func isValidRealityDomain(domain string) bool {
+	// 域名必须不为空且包含至少一个"."
+	return domain != "" && strings.Contains(domain, ".")
+}
+
+// ValidateRealityTarget 验证Reality目标的完整性
+func ValidateRealityTarget(result scanner.Result) (bool, []string) {
+	var issues []string
+
+	// 检查TLS版本
+	if result.TLSVersion != RequiredTLSVersion {
+		issues = append(issues, fmt.Sprintf("TLS版本不符合要求，需要%s，实际%s", RequiredTLSVersion, result.TLSVersion))
+	}
+
+	// 检查ALPN
+	if result.ALPN != RequiredALPN {
+		issues = append(issues, fmt.Sprintf("ALPN协议不符合要求，需要%s，实际%s", RequiredALPN, result.ALPN))
+	}
+
+	// 检查椭圆曲线
+	if result.Curve != RequiredCurve {
+		issues = append(issues, fmt.Sprintf("椭圆曲线不符合要求，需要%s，实际%s", RequiredCurve, result.Curve))
+	}
+
+	// 检查证书域名
+	if result.CertDomain == "" {
+		issues = append(issues, "证书域名为空")
+	}
+
+	// 检查证书颁发者
+	if result.CertIssuer == "" {
+		issues = append(issues, "证书颁发者为空")
+	}
+
+	return len(issues) == 0, issues
+}
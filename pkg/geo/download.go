@@ -0,0 +1,218 @@
+package geo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// HTTPClient 下载数据库使用的HTTP客户端，*http.Client 即满足该接口
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// 数据库文件名
+const (
+	CountryDBFile = "GeoLite2-Country.mmdb"
+	ASNDBFile     = "GeoLite2-ASN.mmdb"
+)
+
+// DefaultMirrors 默认的数据库下载镜像
+// MaxMind官方下载需要注册账户，这里使用公开的镜像链接
+var DefaultMirrors = []string{
+	"https://github.com/P3TERX/GeoLite.mmdb/raw/download/",
+	"https://cdn.jsdelivr.net/gh/P3TERX/GeoLite.mmdb@download/",
+}
+
+// Downloader 数据库下载器，依次尝试各个镜像直到成功
+type Downloader struct {
+	Client HTTPClient // HTTP客户端，为nil时使用http.DefaultClient
+	// Mirrors 镜像地址，为空时使用DefaultMirrors
+	// 包含 {file} 时替换为数据库文件名，否则将文件名追加到地址末尾
+	Mirrors []string
+}
+
+// NewProxyClient 创建通过代理下载的HTTP客户端，支持 http://、https:// 和 socks5:// 代理
+func NewProxyClient(proxyURL string) (*http.Client, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析代理地址失败: %v", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %s", proxy.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+	return &http.Client{Transport: transport}, nil
+}
+
+// Download 下载GeoLite2-Country.mmdb文件，client为nil时使用http.DefaultClient
+func Download(ctx context.Context, client HTTPClient, filePath string) error {
+	return (&Downloader{Client: client}).Download(ctx, CountryDBFile, filePath)
+}
+
+// DownloadASN 下载GeoLite2-ASN.mmdb文件，client为nil时使用http.DefaultClient
+func DownloadASN(ctx context.Context, client HTTPClient, filePath string) error {
+	return (&Downloader{Client: client}).Download(ctx, ASNDBFile, filePath)
+}
+
+// Download 从镜像下载名为file的数据库到filePath，所有镜像均失败时返回最后一个错误
+func (d *Downloader) Download(ctx context.Context, file, filePath string) error {
+	mirrors := d.Mirrors
+	if len(mirrors) == 0 {
+		mirrors = DefaultMirrors
+	}
+
+	var err error
+	for i, mirror := range mirrors {
+		err = downloadFile(ctx, d.Client, mirrorURL(mirror, file), mirrorURL(mirror, file+".sha256"), file, filePath)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if i < len(mirrors)-1 {
+			logger.Warn("镜像下载失败，尝试下一个镜像", "mirror", mirror, "error", err)
+		}
+	}
+	return err
+}
+
+// TryDownload 尝试下载数据库，失败时只记录日志
+func (d *Downloader) TryDownload(ctx context.Context, file, filePath string) bool {
+	if err := d.Download(ctx, file, filePath); err != nil {
+		logger.Error("下载数据库失败", "name", file, "error", err)
+		return false
+	}
+	return true
+}
+
+// mirrorURL 拼接镜像地址和数据库文件名
+func mirrorURL(mirror, file string) string {
+	if strings.Contains(mirror, "{file}") {
+		return strings.ReplaceAll(mirror, "{file}", file)
+	}
+	return strings.TrimSuffix(mirror, "/") + "/" + file
+}
+
+// downloadFile 下载数据库文件到filePath，checksumURL为sha256sum格式的校验文件地址，name仅用于日志
+// 文件先写入同目录的临时文件，校验通过后原子替换目标文件，下载失败时原有文件保持不变
+func downloadFile(ctx context.Context, client HTTPClient, url, checksumURL, name, filePath string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	logger.Info("正在下载数据库...", "name", name)
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("创建下载请求失败: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("下载请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("下载失败，HTTP状态码: %d", resp.StatusCode)
+	}
+
+	// 在目标目录创建临时文件，保证重命名是原子操作
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后删除不会生效
+
+	// 复制数据到临时文件，同时计算SHA256
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+
+	// 校验SHA256，镜像未提供校验文件时跳过
+	expected, err := fetchChecksum(ctx, client, checksumURL)
+	if err != nil {
+		logger.Warn("无法获取校验文件，跳过SHA256校验", "name", name, "error", err)
+	} else if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("SHA256校验失败: 期望 %s，实际 %s", expected, actual)
+	}
+
+	// 确认下载的是可用的mmdb文件
+	reader, err := geoip2.Open(tmpPath)
+	if err != nil {
+		return fmt.Errorf("下载的文件不是有效的数据库: %v", err)
+	}
+	reader.Close()
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("替换数据库文件失败: %v", err)
+	}
+
+	logging.Success(logger, "数据库下载成功", "name", name, "path", filePath)
+	return nil
+}
+
+// fetchChecksum 获取sha256sum格式的校验文件，返回其中的十六进制摘要
+func fetchChecksum(ctx context.Context, client HTTPClient, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+
+	// 格式为 "<摘要>  <文件名>" 或仅摘要
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("校验文件格式无效")
+	}
+	return fields[0], nil
+}
+
+// TryDownload 尝试下载GeoLite2数据库，失败时不报错
+func TryDownload(ctx context.Context, client HTTPClient, filePath string) bool {
+	return (&Downloader{Client: client}).TryDownload(ctx, CountryDBFile, filePath)
+}
+
+// TryDownloadASN 尝试下载GeoLite2-ASN数据库，失败时不报错
+func TryDownloadASN(ctx context.Context, client HTTPClient, filePath string) bool {
+	return (&Downloader{Client: client}).TryDownload(ctx, ASNDBFile, filePath)
+}
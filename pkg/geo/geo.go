@@ -0,0 +1,136 @@
+// Package geo 基于MaxMind GeoLite2数据库提供IP地理位置查询，并支持自动下载数据库文件
+package geo
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/oschwald/geoip2-golang"
+)
+
+var logger = logging.For("geo")
+
+// Geo 地理位置查询结构体
+type Geo struct {
+	geoReader *geoip2.Reader
+	asnReader *geoip2.Reader // 可选的ASN数据库，为nil时不查询ASN
+	mu        sync.Mutex     // 保证线程安全
+}
+
+// Location IP的地理位置和网络归属信息
+type Location struct {
+	CountryCode string // 国家代码
+	City        string // 城市名称，仅在使用City数据库时可用
+	ASN         uint   // 自治系统编号，仅在加载ASN数据库后可用
+	ASOrg       string // 自治系统所属组织
+}
+
+// New 创建新的地理位置查询实例
+func New(dbPath string) (*Geo, error) {
+	reader, err := geoip2.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Geo{
+		geoReader: reader,
+	}, nil
+}
+
+// GetGeo 获取IP的地理位置代码
+func (g *Geo) GetGeo(ip netip.Addr) string {
+	if g.geoReader == nil {
+		return "UNKNOWN"
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	country, err := g.geoReader.Country(ip.AsSlice())
+	if err != nil {
+		return "UNKNOWN"
+	}
+
+	return country.Country.IsoCode
+}
+
+// OpenASN 加载GeoLite2-ASN数据库，之后的Lookup会同时返回ASN和所属组织
+func (g *Geo) OpenASN(dbPath string) error {
+	reader, err := geoip2.Open(dbPath)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.asnReader != nil {
+		g.asnReader.Close()
+	}
+	g.asnReader = reader
+	return nil
+}
+
+// Lookup 查询IP的国家、城市和ASN信息，查询失败的字段保持为空
+func (g *Geo) Lookup(addr netip.Addr) Location {
+	loc := Location{CountryCode: "UNKNOWN"}
+	ip := net.IP(addr.AsSlice()) // geoip2仍使用net.IP
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.geoReader != nil {
+		// City数据库同时包含国家和城市信息
+		if strings.Contains(g.geoReader.Metadata().DatabaseType, "City") {
+			if city, err := g.geoReader.City(ip); err == nil {
+				loc.CountryCode = city.Country.IsoCode
+				loc.City = city.City.Names["en"]
+			}
+		} else if country, err := g.geoReader.Country(ip); err == nil {
+			loc.CountryCode = country.Country.IsoCode
+		}
+	}
+
+	if g.asnReader != nil {
+		if asn, err := g.asnReader.ASN(ip); err == nil {
+			loc.ASN = asn.AutonomousSystemNumber
+			loc.ASOrg = asn.AutonomousSystemOrganization
+		}
+	}
+
+	return loc
+}
+
+// BuildTime 返回地理位置数据库的构建时间
+func (g *Geo) BuildTime() time.Time {
+	return buildTime(g.geoReader)
+}
+
+// ASNBuildTime 返回ASN数据库的构建时间，未加载ASN数据库时返回零值
+func (g *Geo) ASNBuildTime() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return buildTime(g.asnReader)
+}
+
+// buildTime 读取mmdb元数据中的构建时间
+func buildTime(reader *geoip2.Reader) time.Time {
+	if reader == nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(reader.Metadata().BuildEpoch), 0)
+}
+
+// Close 关闭地理位置数据库
+func (g *Geo) Close() error {
+	if g.asnReader != nil {
+		g.asnReader.Close()
+	}
+	if g.geoReader != nil {
+		return g.geoReader.Close()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// alertInterval 两次提示音的最小间隔，短时间内连续命中只提示一次
+const alertInterval = 2 * time.Second
+
+// Alert 发现符合条件的目标时发出提示音，适合无人值守的无限扫描
+// bell模式向终端输出响铃字符，sound模式播放系统提示音，找不到播放程序时退回响铃
+type Alert struct {
+	sound bool
+
+	mu   sync.Mutex
+	last time.Time
+	wg   sync.WaitGroup
+}
+
+// NewAlert 创建提示音输出，mode为 bell 或 sound
+func NewAlert(mode string) (*Alert, error) {
+	switch mode {
+	case "bell":
+		return &Alert{}, nil
+	case "sound":
+		return &Alert{sound: true}, nil
+	default:
+		return nil, fmt.Errorf("不支持的提示音模式: %s（可选 bell 或 sound）", mode)
+	}
+}
+
+// Send 发出一次提示音，实现ResultSink
+func (a *Alert) Send(result scanner.Result) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.last) < alertInterval {
+		return nil
+	}
+	a.last = time.Now()
+
+	if a.sound {
+		if command := soundCommand(); command != nil {
+			// 播放在后台进行，不阻塞结果处理
+			a.wg.Add(1)
+			go func() {
+				defer a.wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), alertInterval)
+				defer cancel()
+				if err := exec.CommandContext(ctx, command[0], command[1:]...).Run(); err != nil {
+					logger.Debug("播放提示音失败", "error", err)
+				}
+			}()
+			return nil
+		}
+	}
+	// 响铃字符写到标准错误，标准输出被重定向时不会混入结果
+	_, err := os.Stderr.WriteString("\a")
+	return err
+}
+
+// Close 等待正在播放的提示音结束，实现ResultSink
+func (a *Alert) Close() error {
+	a.wg.Wait()
+	return nil
+}
+
+// soundCommand 返回播放系统提示音的命令行，当前平台没有可用的播放程序时返回nil
+func soundCommand() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"afplay", "/System/Library/Sounds/Glass.aiff"}
+	case "windows":
+		return []string{"powershell", "-NoProfile", "-Command", "[System.Media.SystemSounds]::Asterisk.Play()"}
+	}
+	const freedesktopSound = "/usr/share/sounds/freedesktop/stereo/complete.oga"
+	if _, err := exec.LookPath("canberra-gtk-play"); err == nil {
+		return []string{"canberra-gtk-play", "--id=complete"}
+	}
+	if _, err := os.Stat(freedesktopSound); err == nil {
+		if _, err := exec.LookPath("paplay"); err == nil {
+			return []string{"paplay", freedesktopSound}
+		}
+	}
+	return nil
+}
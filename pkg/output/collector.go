@@ -0,0 +1,187 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// ResultSink 结果输出目标（远程收集端、消息通道、钩子命令等）
+type ResultSink interface {
+	Send(result scanner.Result) error
+	Close() error
+}
+
+// collectorRecord 推送到收集端的单条记录
+type collectorRecord struct {
+	scanner.Result
+	Node     string `json:"node"`      // 扫描节点名称
+	ScanTime string `json:"scan_time"` // 扫描时间
+}
+
+// Collector 将扫描结果以NDJSON格式批量推送到远程HTTP收集端
+type Collector struct {
+	url       string
+	headers   http.Header
+	node      string
+	batchSize int
+	client    *http.Client
+
+	mu     sync.Mutex
+	buffer []collectorRecord
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// 收集端推送参数
+const (
+	collectorMaxRetries    = 3                // 最大重试次数
+	collectorFlushInterval = 10 * time.Second // 定时推送间隔
+)
+
+// NewCollector 创建新的远程收集端推送器
+// headers为"Key: Value"格式的请求头列表，node为空时使用主机名
+func NewCollector(url string, headers []string, node string, batchSize int) (*Collector, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("无效的收集端地址: %s", url)
+	}
+
+	header := make(http.Header)
+	for _, h := range headers {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("无效的请求头: %s", h)
+		}
+		header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	c := &Collector{
+		url:       url,
+		headers:   header,
+		node:      node,
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	// 定时推送未满一批的结果，避免结果长时间滞留在缓冲区
+	go c.flushLoop()
+
+	return c, nil
+}
+
+// Send 将结果加入缓冲区，满一批时推送
+func (c *Collector) Send(result scanner.Result) error {
+	c.mu.Lock()
+	c.buffer = append(c.buffer, collectorRecord{
+		Result:   result,
+		Node:     c.node,
+		ScanTime: ScanTime(),
+	})
+	full := len(c.buffer) >= c.batchSize
+	c.mu.Unlock()
+
+	if full {
+		return c.Flush()
+	}
+	return nil
+}
+
+// Flush 推送缓冲区中的所有结果
+func (c *Collector) Flush() error {
+	c.mu.Lock()
+	batch := c.buffer
+	c.buffer = nil
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range batch {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("编码结果失败: %v", err)
+		}
+	}
+
+	return c.post(body.Bytes())
+}
+
+// post 发送一批NDJSON数据，失败时按指数退避重试
+func (c *Collector) post(body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= collectorMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("创建请求失败: %v", err)
+		}
+		req.Header = c.headers.Clone()
+		req.Header.Set("Content-Type", "application/x-ndjson")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("推送失败: %v", err)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		lastErr = fmt.Errorf("推送失败，HTTP状态码: %d", resp.StatusCode)
+		// 4xx错误（除429外）重试无意义
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+	}
+	return lastErr
+}
+
+// flushLoop 定时推送缓冲区
+func (c *Collector) flushLoop() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(collectorFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				logger.Error("推送结果到收集端失败", "error", err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Close 停止定时推送并推送剩余结果
+func (c *Collector) Close() error {
+	close(c.stop)
+	<-c.done
+	return c.Flush()
+}
@@ -0,0 +1,399 @@
+// Package output 负责扫描结果的输出：CSV文件、实时进度显示、
+// 配置导出（Reality/Clash.Meta）以及远程收集端、消息通道和钩子命令等额外输出目标。
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// SyncInterval 结果文件定期落盘的间隔
+const SyncInterval = 5 * time.Second
+
+// scanTimeZone 记录扫描时间使用的时区，默认UTC，多台服务器的结果合并后可直接比较
+var scanTimeZone = time.UTC
+
+// SetTimeZone 设置记录扫描时间使用的时区
+func SetTimeZone(loc *time.Location) {
+	scanTimeZone = loc
+}
+
+// ScanTime 返回当前时间的RFC3339表示（带时区偏移），用于结果文件和推送记录的扫描时间
+func ScanTime() string {
+	return time.Now().In(scanTimeZone).Format(time.RFC3339)
+}
+
+// PartialSuffix 扫描进行中结果文件的后缀，扫描完成后重命名为最终文件名
+// 进程被强制结束时该文件保留已落盘的完整记录，可直接读取
+const PartialSuffix = ".partial"
+
+// CSVWriter CSV输出写入器
+// 写入临时文件并定期fsync，Close时原子重命名为最终文件，断电或OOM时不会留下半截的结果文件
+type CSVWriter struct {
+	mu       sync.Mutex
+	file     *os.File
+	writer   *csv.Writer
+	record   []string // 复用的记录缓冲区，避免每行分配
+	filename string   // 最终文件名
+	dirty    bool     // 上次落盘后是否有新记录
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// csvHeader 结果文件的列，新增的列追加在RESPONSE_TIME_MS之后，前11列的位置保持不变
+var csvHeader = []string{
+	"IP",
+	"ORIGIN",
+	"PORT",
+	"CERT_DOMAIN",
+	"CERT_ISSUER",
+	"TLS_VERSION",
+	"ALPN",
+	"CURVE",
+	"GEO_CODE",
+	"FEASIBLE",
+	"RESPONSE_TIME_MS",
+	"ASN",
+	"AS_ORG",
+	"CITY",
+	"SNI",
+	"SNI_MATCH",
+	"CERT_SHA256",
+	"MIDDLEBOX",
+	"MTU",
+	"TCP_RTT_MS",
+	"TTL",
+	"HOPS",
+	"VANTAGES",
+	"REGION",
+	"PREV_CERT_SHA256",
+	"SUSPICIOUS",
+	"CONFIDENCE",
+	"HELLO_PROFILE",
+	"ERROR_KIND",
+	"ERROR",
+	"SCAN_TIME",
+}
+
+// vantageNames 观测点名称，每个观测点在结果文件末尾占一列延迟
+var vantageNames []string
+
+// SetVantages 设置观测点名称，结果文件末尾为每个观测点增加一列 VANTAGE_<名称>_MS，
+// 记录从该观测点测得的响应时间，不符合条件时为空；应在创建结果文件之前调用
+func SetVantages(names []string) {
+	vantageNames = names
+}
+
+// resultHeader 返回结果文件的列：csvHeader之后是每个观测点的延迟列
+func resultHeader() []string {
+	header := csvHeader
+	for _, name := range vantageNames {
+		header = append(header[:len(header):len(header)], "VANTAGE_"+strings.ToUpper(name)+"_MS")
+	}
+	return header
+}
+
+// NewCSVWriter 创建新的CSV写入器
+func NewCSVWriter(filename string) (*CSVWriter, error) {
+	file, err := os.Create(filename + PartialSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("创建输出文件失败: %v", err)
+	}
+
+	writer := csv.NewWriter(file)
+
+	// 写入CSV头部
+	if err := writer.Write(resultHeader()); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("写入CSV头部失败: %v", err)
+	}
+
+	writer.Flush()
+
+	cw := &CSVWriter{
+		file:     file,
+		writer:   writer,
+		filename: filename,
+		done:     make(chan struct{}),
+	}
+	cw.wg.Add(1)
+	go cw.syncLoop()
+	return cw, nil
+}
+
+// syncLoop 定期以及收到中断信号时将结果落盘
+func (cw *CSVWriter) syncLoop() {
+	defer cw.wg.Done()
+
+	ticker := time.NewTicker(SyncInterval)
+	defer ticker.Stop()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-signals:
+		case <-cw.done:
+			return
+		}
+		if err := cw.Sync(); err != nil {
+			logger.Warn("结果文件落盘失败", "error", err)
+		}
+	}
+}
+
+// Sync 将已写入的记录刷新并fsync到磁盘
+func (cw *CSVWriter) Sync() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.syncLocked()
+}
+
+func (cw *CSVWriter) syncLocked() error {
+	if !cw.dirty || cw.file == nil {
+		return nil
+	}
+	cw.writer.Flush()
+	if err := cw.writer.Error(); err != nil {
+		return err
+	}
+	if err := cw.file.Sync(); err != nil {
+		return err
+	}
+	cw.dirty = false
+	return nil
+}
+
+// WriteResult 写入扫描结果
+func (cw *CSVWriter) WriteResult(result scanner.Result) error {
+	record := formatRecord(cw.record[:0], result)
+	cw.record = record
+
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if cw.file == nil {
+		return fmt.Errorf("写入CSV记录失败: 文件已关闭")
+	}
+	if err := cw.writer.Write(record); err != nil {
+		return fmt.Errorf("写入CSV记录失败: %v", err)
+	}
+
+	// 刷新到操作系统缓冲区，fsync由定时器统一执行
+	cw.writer.Flush()
+	cw.dirty = true
+	return nil
+}
+
+// formatRecord 将结果按resultHeader的列顺序追加到record
+func formatRecord(record []string, result scanner.Result) []string {
+	record = append(record,
+		result.IP,
+		result.Origin,
+		strconv.Itoa(result.Port),
+		result.CertDomain,
+		result.CertIssuer,
+		result.TLSVersion,
+		result.ALPN,
+		result.Curve,
+		result.GeoCode,
+		strconv.FormatBool(result.Feasible),
+		strconv.FormatInt(result.ResponseTime, 10),
+		formatASN(result.ASN),
+		result.ASOrg,
+		result.City,
+		result.SNI,
+		result.SNIMatch,
+		result.CertSHA256,
+		result.Middlebox,
+		result.MTU,
+		strconv.FormatInt(result.TCPRTT, 10),
+		strconv.Itoa(result.TTL),
+		strconv.Itoa(result.Hops),
+		scanner.FormatVantages(result.Vantages),
+		result.Region,
+		result.PrevCertSHA256,
+		result.Suspicious,
+		result.Confidence,
+		result.HelloProfile,
+		string(result.ErrorKind),
+		result.Error,
+		ScanTime(),
+	)
+	for _, name := range vantageNames {
+		record = append(record, vantageLatency(result.Vantages, name))
+	}
+	return record
+}
+
+// vantageLatency 返回指定观测点的响应时间，该观测点不符合条件或未测试时返回空字符串
+func vantageLatency(vantages []scanner.VantageResult, name string) string {
+	for _, vantage := range vantages {
+		if vantage.Name == name && vantage.Feasible {
+			return strconv.FormatInt(vantage.ResponseTime, 10)
+		}
+	}
+	return ""
+}
+
+// formatASN 格式化ASN，未知时返回空字符串
+func formatASN(asn uint) string {
+	if asn == 0 {
+		return ""
+	}
+	return "AS" + strconv.FormatUint(uint64(asn), 10)
+}
+
+// LoadFeasibleRecords 读取结果文件中符合条件的记录（跳过头部）
+// 使用encoding/csv解析，包含逗号的证书域名列表和错误信息按引号字段完整读取
+func LoadFeasibleRecords(filename string) ([][]string, error) {
+	_, records, err := LoadFeasibleTable(filename)
+	return records, err
+}
+
+// LoadFeasibleTable 读取结果文件的头部和其中符合条件的记录，头部用于按列名取值（旧版本文件的列较少）
+func LoadFeasibleTable(filename string) ([]string, [][]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // 兼容旧版本生成的列数较少的结果文件
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("读取CSV文件失败: %v", err)
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取CSV文件失败: %v", err)
+		}
+
+		if len(record) >= 11 && record[9] == "true" { // FEASIBLE字段
+			records = append(records, record)
+		}
+	}
+
+	return header, records, nil
+}
+
+// Close 落盘并关闭CSV写入器，然后将临时文件原子重命名为最终文件名
+func (cw *CSVWriter) Close() error {
+	cw.mu.Lock()
+	if cw.file == nil {
+		cw.mu.Unlock()
+		return nil
+	}
+	close(cw.done)
+	cw.mu.Unlock()
+	cw.wg.Wait()
+
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	cw.dirty = true
+	syncErr := cw.syncLocked()
+	closeErr := cw.file.Close()
+	partial := cw.file.Name()
+	cw.file = nil
+	if syncErr != nil {
+		return fmt.Errorf("写入结果文件失败: %v，未完成的结果保留在 %s", syncErr, partial)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("关闭结果文件失败: %v，未完成的结果保留在 %s", closeErr, partial)
+	}
+
+	if err := os.Rename(partial, cw.filename); err != nil {
+		return fmt.Errorf("重命名结果文件失败: %v，结果保留在 %s", err, partial)
+	}
+	return nil
+}
+
+// ReplaceResult 用重新扫描的结果替换结果文件中IP、端口和SNI相同的记录，返回是否找到该记录
+// 按文件头部的列名填写，旧版本文件中不存在的列被忽略；先写临时文件再重命名
+func ReplaceResult(filename string, result scanner.Result) (bool, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return false, fmt.Errorf("读取结果文件失败: %v", err)
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return false, fmt.Errorf("读取CSV文件失败: %v", err)
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	columns := resultHeader()
+	values := make(map[string]string, len(columns))
+	for i, value := range formatRecord(nil, result) {
+		values[columns[i]] = value
+	}
+
+	header := records[0]
+	column := func(record []string, name string) string {
+		for i, h := range header {
+			if h == name && i < len(record) {
+				return record[i]
+			}
+		}
+		return ""
+	}
+
+	found := false
+	for i, record := range records[1:] {
+		if column(record, "IP") != values["IP"] || column(record, "PORT") != values["PORT"] ||
+			column(record, "SNI") != values["SNI"] {
+			continue
+		}
+		updated := make([]string, len(header))
+		for j, name := range header {
+			updated[j] = values[name]
+		}
+		records[i+1] = updated
+		found = true
+	}
+	if !found {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return false, fmt.Errorf("写入CSV记录失败: %v", err)
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return false, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return false, fmt.Errorf("重命名结果文件失败: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+)
+
+// ExportRecords 将结果记录写入新文件，扩展名为 .jsonl 或 .ndjson 时每行一个JSON对象
+// （键为小写的列名，与扫描结果的JSON字段一致），否则写入带头部的CSV
+func ExportRecords(filename string, header []string, records [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		err = writeJSONLines(writer, header, records)
+	default:
+		err = writeCSV(writer, header, records)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("写入导出文件失败: %v", err)
+	}
+
+	logging.Success(logger, "结果已导出", "path", filename, "targets", len(records))
+	return nil
+}
+
+// writeCSV 写入CSV头部和记录
+func writeCSV(w *bufio.Writer, header []string, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// writeJSONLines 每条记录写为一行JSON对象，空字段省略
+func writeJSONLines(w *bufio.Writer, header []string, records [][]string) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(RecordObject(header, record)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordObject 将结果记录转换为以小写列名为键的对象，空字段省略
+func RecordObject(header, record []string) map[string]string {
+	object := make(map[string]string, len(header))
+	for i, column := range header {
+		if i < len(record) && record[i] != "" {
+			object[strings.ToLower(column)] = record[i]
+		}
+	}
+	return object
+}
@@ -0,0 +1,288 @@
+package output
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	_ "modernc.org/sqlite"
+)
+
+// 历史记录批量提交的条数和最长间隔，中途被强制结束时最多丢失一批
+const (
+	historyBatch    = 500
+	historyInterval = 5 * time.Second
+)
+
+// historySchema 历史数据库的表结构：每次扫描一行runs，每个结果一行results
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id           INTEGER NOT NULL REFERENCES runs(id),
+	ip               TEXT    NOT NULL,
+	port             INTEGER NOT NULL,
+	sni              TEXT    NOT NULL DEFAULT '',
+	cert_domain      TEXT    NOT NULL DEFAULT '',
+	cert_sha256      TEXT    NOT NULL DEFAULT '',
+	geo_code         TEXT    NOT NULL DEFAULT '',
+	feasible         INTEGER NOT NULL,
+	response_time_ms INTEGER NOT NULL,
+	error_kind       TEXT    NOT NULL DEFAULT '',
+	scanned_at       INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_ip ON results(ip, scanned_at);
+`
+
+// History 本地的历史结果数据库（SQLite），记录每次扫描的结果，用于查看目标长期的可用性、延迟和证书变化
+// 为控制数据库大小，只记录完成握手的结果，以及以前记录过的IP的失败结果（用于发现目标失效）
+type History struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	known   map[string]struct{} // 数据库中已有记录的IP，开始扫描时一次载入，随写入更新
+	tx      *sql.Tx
+	stmt    *sql.Stmt
+	runID   int64
+	pending int
+	flushed time.Time
+}
+
+// HistoryEntry 目标的一条历史记录
+type HistoryEntry struct {
+	Time         time.Time
+	Port         int
+	SNI          string
+	CertDomain   string
+	CertSHA256   string
+	GeoCode      string
+	Feasible     bool
+	ResponseTime int64
+	ErrorKind    string
+}
+
+// HistorySummary 目标历史记录的统计
+type HistorySummary struct {
+	Scans       int       // 记录次数
+	Feasible    int       // 符合条件的次数
+	Streak      int       // 截至最近一次连续符合条件的次数
+	First, Last time.Time // 第一次和最近一次记录的时间
+	MinLatency  int64     // 符合条件时的最低、平均和最高响应时间(毫秒)
+	AvgLatency  int64
+	MaxLatency  int64
+	Certs       int // 出现过的不同证书数
+}
+
+// OpenHistory 打开历史数据库，不存在时创建
+func OpenHistory(path string) (*History, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建历史数据库目录失败: %v", err)
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("打开历史数据库失败: %v", err)
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化历史数据库失败: %v", err)
+	}
+	return &History{db: db}, nil
+}
+
+// Watch 转发扫描结果并写入数据库，调用时开始一次新的扫描记录，返回的通道在results关闭后关闭
+// 写入失败时记录日志并停止写入，不影响扫描；ctx取消后不再转发，但继续读取results直到其关闭，
+// 调用方应读完返回的通道后再Close
+func (h *History) Watch(ctx context.Context, results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	err := h.startRun()
+	if err != nil {
+		logger.Warn("不记录本次扫描的历史", "error", err)
+	}
+	go func() {
+		defer close(out)
+		for result := range results {
+			if err == nil {
+				if err = h.record(result); err != nil {
+					logger.Warn("写入历史数据库失败，不再记录本次扫描", "error", err)
+				}
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+// startRun 载入已记录过的IP并插入本次扫描的记录
+func (h *History) startRun() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.known == nil {
+		known, err := h.loadKnown()
+		if err != nil {
+			return err
+		}
+		h.known = known
+	}
+	res, err := h.db.Exec("INSERT INTO runs (started_at) VALUES (?)", time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	h.runID, err = res.LastInsertId()
+	return err
+}
+
+// loadKnown 读取数据库中所有已记录过的IP
+func (h *History) loadKnown() (map[string]struct{}, error) {
+	rows, err := h.db.Query("SELECT DISTINCT ip FROM results")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[string]struct{})
+	for rows.Next() {
+		var ip string
+		if err := rows.Scan(&ip); err != nil {
+			return nil, err
+		}
+		known[ip] = struct{}{}
+	}
+	return known, rows.Err()
+}
+
+// record 在当前事务中写入一个结果，达到批量条数或间隔时提交
+func (h *History) record(result scanner.Result) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if result.IP == "" {
+		return nil
+	}
+	if h.tx == nil {
+		tx, err := h.db.Begin()
+		if err != nil {
+			return err
+		}
+		stmt, err := tx.Prepare(`INSERT INTO results
+			(run_id, ip, port, sni, cert_domain, cert_sha256, geo_code, feasible, response_time_ms, error_kind, scanned_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		h.tx, h.stmt, h.flushed = tx, stmt, time.Now()
+	}
+	if result.TLSVersion == "" {
+		// 失败的结果只记录以前记录过的IP，包括本次扫描中尚未提交的记录
+		if _, ok := h.known[result.IP]; !ok {
+			return nil
+		}
+	}
+
+	_, err := h.stmt.Exec(h.runID, result.IP, result.Port, result.SNI, result.CertDomain, result.CertSHA256, result.GeoCode,
+		result.Error == "" && result.Feasible, result.ResponseTime, string(result.ErrorKind), time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	h.known[result.IP] = struct{}{}
+	h.pending++
+	if h.pending >= historyBatch || time.Since(h.flushed) >= historyInterval {
+		return h.commit()
+	}
+	return nil
+}
+
+// commit 提交当前事务，调用者持有mu
+func (h *History) commit() error {
+	if h.tx == nil {
+		return nil
+	}
+	h.stmt.Close()
+	err := h.tx.Commit()
+	h.tx, h.stmt, h.pending = nil, nil, 0
+	return err
+}
+
+// Close 提交尚未写入的结果并关闭数据库
+func (h *History) Close() error {
+	h.mu.Lock()
+	err := h.commit()
+	h.mu.Unlock()
+	if closeErr := h.db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("保存历史数据库失败: %v", err)
+	}
+	return nil
+}
+
+// Target 返回IP的所有历史记录，按时间先后排列
+func (h *History) Target(ip string) ([]HistoryEntry, error) {
+	rows, err := h.db.Query(`SELECT scanned_at, port, sni, cert_domain, cert_sha256, geo_code, feasible, response_time_ms, error_kind
+		FROM results WHERE ip = ? ORDER BY scanned_at, rowid`, ip)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var entry HistoryEntry
+		var scannedAt int64
+		if err := rows.Scan(&scannedAt, &entry.Port, &entry.SNI, &entry.CertDomain, &entry.CertSHA256, &entry.GeoCode,
+			&entry.Feasible, &entry.ResponseTime, &entry.ErrorKind); err != nil {
+			return nil, fmt.Errorf("查询历史记录失败: %v", err)
+		}
+		entry.Time = time.Unix(scannedAt, 0).In(scanTimeZone)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %v", err)
+	}
+	return entries, nil
+}
+
+// SummarizeHistory 统计历史记录
+func SummarizeHistory(entries []HistoryEntry) HistorySummary {
+	var summary HistorySummary
+	if len(entries) == 0 {
+		return summary
+	}
+	summary.Scans = len(entries)
+	summary.First, summary.Last = entries[0].Time, entries[len(entries)-1].Time
+
+	var total int64
+	certs := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.CertSHA256 != "" {
+			certs[entry.CertSHA256] = true
+		}
+		if !entry.Feasible {
+			summary.Streak = 0
+			continue
+		}
+		summary.Streak++
+		if summary.Feasible == 0 || entry.ResponseTime < summary.MinLatency {
+			summary.MinLatency = entry.ResponseTime
+		}
+		summary.MaxLatency = max(summary.MaxLatency, entry.ResponseTime)
+		summary.Feasible++
+		total += entry.ResponseTime
+	}
+	if summary.Feasible > 0 {
+		summary.AvgLatency = total / int64(summary.Feasible)
+	}
+	summary.Certs = len(certs)
+	return summary
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 钩子命令的最长执行时间
+const hookTimeout = 30 * time.Second
+
+// HookRunner 在发现符合条件的目标时执行用户指定的命令
+// 命令为text/template模板，可引用ScanResult的字段，如 './notify.sh {{.IP}} {{.CertDomain}}'
+type HookRunner struct {
+	tmpl *template.Template
+	wg   sync.WaitGroup
+}
+
+// NewHookRunner 解析命令模板并创建钩子执行器
+func NewHookRunner(command string) (*HookRunner, error) {
+	tmpl, err := template.New("on-feasible").Option("missingkey=error").Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("解析命令模板失败: %v", err)
+	}
+
+	// 提前渲染一次，尽早发现引用了不存在字段的模板
+	if err := tmpl.Execute(io.Discard, hookTemplateData(scanner.Result{})); err != nil {
+		return nil, fmt.Errorf("命令模板无效: %v", err)
+	}
+
+	return &HookRunner{tmpl: tmpl}, nil
+}
+
+// Send 渲染命令并在后台执行，不阻塞结果处理
+func (h *HookRunner) Send(result scanner.Result) error {
+	var command bytes.Buffer
+	if err := h.tmpl.Execute(&command, hookTemplateData(result)); err != nil {
+		return fmt.Errorf("渲染命令模板失败: %v", err)
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command.String())
+		cmd.Env = append(os.Environ(), hookEnv(result)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			logger.Error("钩子命令执行失败", "error", err, "output", strings.TrimSpace(string(output)))
+		}
+	}()
+
+	return nil
+}
+
+// Close 等待所有正在执行的钩子命令结束
+func (h *HookRunner) Close() error {
+	h.wg.Wait()
+	return nil
+}
+
+// hookTemplateData 构造模板数据，字符串字段经过shell转义，避免证书内容被当作命令执行
+func hookTemplateData(result scanner.Result) map[string]interface{} {
+	return map[string]interface{}{
+		"IP":           shellQuote(result.IP),
+		"Origin":       shellQuote(result.Origin),
+		"Port":         result.Port,
+		"CertDomain":   shellQuote(result.CertDomain),
+		"CertIssuer":   shellQuote(result.CertIssuer),
+		"TLSVersion":   shellQuote(result.TLSVersion),
+		"ALPN":         shellQuote(result.ALPN),
+		"Curve":        shellQuote(result.Curve),
+		"GeoCode":      shellQuote(result.GeoCode),
+		"City":         shellQuote(result.City),
+		"ASN":          result.ASN,
+		"ASOrg":        shellQuote(result.ASOrg),
+		"Feasible":     result.Feasible,
+		"ResponseTime": result.ResponseTime,
+	}
+}
+
+// hookEnv 以环境变量的形式传递结果字段，方便脚本直接读取
+func hookEnv(result scanner.Result) []string {
+	return []string{
+		"GRD_IP=" + result.IP,
+		"GRD_ORIGIN=" + result.Origin,
+		"GRD_PORT=" + strconv.Itoa(result.Port),
+		"GRD_CERT_DOMAIN=" + result.CertDomain,
+		"GRD_CERT_ISSUER=" + result.CertIssuer,
+		"GRD_TLS_VERSION=" + result.TLSVersion,
+		"GRD_ALPN=" + result.ALPN,
+		"GRD_GEO_CODE=" + result.GeoCode,
+		"GRD_CITY=" + result.City,
+		"GRD_ASN=" + strconv.FormatUint(uint64(result.ASN), 10),
+		"GRD_AS_ORG=" + result.ASOrg,
+		"GRD_RESPONSE_TIME_MS=" + strconv.FormatInt(result.ResponseTime, 10),
+	}
+}
+
+// shellQuote 将字符串转义为单引号包裹的shell参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// maxWorkerRows 工作协程数不超过该值时逐个显示，否则只显示汇总
+const maxWorkerRows = 16
+
+// PrintMetrics 打印扫描性能统计，帮助调整线程数和超时时间
+func PrintMetrics(snap scanner.MetricsSnapshot) {
+	fmt.Printf("\n性能统计:\n")
+	fmt.Printf("─────────────────────────────────────────────────────────────\n")
+
+	// 各阶段耗时
+	fmt.Printf("%s %8s %10s %10s %12s\n", console.PadRight("阶段", 12), "次数", "平均", "最大", "累计")
+	for _, stage := range snap.Stages {
+		if stage.Count == 0 {
+			continue
+		}
+		fmt.Printf("%s %8d %10s %10s %12s\n", console.PadRight(stage.Stage.String(), 12), stage.Count,
+			roundDuration(stage.Avg), roundDuration(stage.Max), roundDuration(stage.Total))
+	}
+
+	// 工作协程吞吐量
+	if len(snap.Workers) > 0 {
+		var totalRate, totalUtil float64
+		minUtil, maxUtil := 1.0, 0.0
+		for _, worker := range snap.Workers {
+			totalRate += worker.Rate
+			totalUtil += worker.Utilization
+			minUtil = min(minUtil, worker.Utilization)
+			maxUtil = max(maxUtil, worker.Utilization)
+		}
+		avgUtil := totalUtil / float64(len(snap.Workers))
+
+		fmt.Printf("\n工作协程: %d 个 | 总吞吐: %.1f/s | 利用率: 平均 %.0f%%，最低 %.0f%%，最高 %.0f%%\n",
+			len(snap.Workers), totalRate, avgUtil*100, minUtil*100, maxUtil*100)
+		if len(snap.Workers) <= maxWorkerRows {
+			for _, worker := range snap.Workers {
+				fmt.Printf("  #%-3d %6d 个目标  %6.1f/s  利用率 %3.0f%%\n",
+					worker.ID, worker.Hosts, worker.Rate, worker.Utilization*100)
+			}
+		}
+
+		fmt.Printf("\n队列长度: 待扫描 平均 %.1f / 最大 %d | 结果 平均 %.1f / 最大 %d\n",
+			snap.HostQueue.Avg, snap.HostQueue.Max, snap.ResultQueue.Avg, snap.ResultQueue.Max)
+
+		// 简单的调优提示
+		switch {
+		case avgUtil < 0.5 && snap.HostQueue.Avg < 1:
+			fmt.Printf("💡 工作协程经常空闲且待扫描队列为空，目标生成是瓶颈，增加线程数不会更快\n")
+		case snap.ResultQueue.Avg > 100:
+			fmt.Printf("💡 结果队列积压较多，结果处理（输出、推送）是瓶颈\n")
+		case avgUtil > 0.9:
+			fmt.Printf("💡 工作协程接近满负荷，可以尝试增加线程数或降低超时时间\n")
+		}
+	}
+}
+
+// roundDuration 按数量级保留合适的精度
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
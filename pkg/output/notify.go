@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Notifier 扫描结束时发送桌面通知，大规模扫描结束时用户往往不在终端前
+// 未指定命令时使用系统自带的通知方式（Linux的notify-send、macOS的osascript、Windows的PowerShell），
+// 指定命令时执行该命令，命令为text/template模板，可引用 {{.Title}}、{{.Message}}、{{.Scanned}}、{{.Feasible}}
+type Notifier struct {
+	tmpl *template.Template // 自定义命令，为nil时使用系统通知
+}
+
+// notifyData 通知内容，字符串字段在自定义命令中经过shell转义
+type notifyData struct {
+	Title    string
+	Message  string
+	Scanned  int64
+	Feasible int64
+}
+
+// NewNotifier 创建通知器，command为空时使用系统通知
+func NewNotifier(command string) (*Notifier, error) {
+	if command == "" {
+		return &Notifier{}, nil
+	}
+	tmpl, err := template.New("notify").Option("missingkey=error").Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("解析通知命令模板失败: %v", err)
+	}
+	return &Notifier{tmpl: tmpl}, nil
+}
+
+// NotifyDone 发送扫描结束的通知，reason为停止原因，正常扫描完成时为空
+func (n *Notifier) NotifyDone(ctx context.Context, snap StatsSnapshot, reason string) {
+	title := "GetRealityDomain 扫描完成"
+	if reason != "" {
+		title = "GetRealityDomain " + reason
+	}
+	data := notifyData{
+		Title:    title,
+		Message:  fmt.Sprintf("找到 %d 个符合条件的目标，共扫描 %d 个", snap.Feasible, snap.Scanned),
+		Scanned:  snap.Scanned,
+		Feasible: snap.Feasible,
+	}
+	n.send(ctx, data)
+}
+
+// Notify 发送一条自定义标题和内容的通知，如守护模式中移除了失效的目标
+func (n *Notifier) Notify(ctx context.Context, title, message string) {
+	n.send(ctx, notifyData{Title: title, Message: message})
+}
+
+// send 执行通知命令，失败时只记录日志
+func (n *Notifier) send(ctx context.Context, data notifyData) {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cmd, err := n.command(ctx, data)
+	if err != nil {
+		logger.Warn("发送通知失败", "error", err)
+		return
+	}
+	cmd.Env = append(os.Environ(),
+		"GRD_TITLE="+data.Title,
+		"GRD_MESSAGE="+data.Message,
+		"GRD_SCANNED="+strconv.FormatInt(data.Scanned, 10),
+		"GRD_FEASIBLE="+strconv.FormatInt(data.Feasible, 10),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Warn("发送通知失败", "error", err, "output", strings.TrimSpace(string(output)))
+	}
+}
+
+// command 返回发送通知的命令
+func (n *Notifier) command(ctx context.Context, data notifyData) (*exec.Cmd, error) {
+	if n.tmpl != nil {
+		quoted := data
+		quoted.Title, quoted.Message = shellQuote(data.Title), shellQuote(data.Message)
+		var command bytes.Buffer
+		if err := n.tmpl.Execute(&command, quoted); err != nil {
+			return nil, fmt.Errorf("渲染通知命令模板失败: %v", err)
+		}
+		return exec.CommandContext(ctx, "sh", "-c", command.String()), nil
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(data.Message), strconv.Quote(data.Title))
+		return exec.CommandContext(ctx, "osascript", "-e", script), nil
+	case "windows":
+		// 通过通知区域图标显示气泡通知，内容经环境变量传入，避免转义问题
+		script := `Add-Type -AssemblyName System.Windows.Forms;` +
+			`$n = New-Object System.Windows.Forms.NotifyIcon;` +
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true;` +
+			`$n.ShowBalloonTip(10000, $env:GRD_TITLE, $env:GRD_MESSAGE, 'Info'); Start-Sleep -Seconds 10; $n.Dispose()`
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script), nil
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil, fmt.Errorf("未找到notify-send，可用 --notify-command 指定通知命令")
+		}
+		return exec.CommandContext(ctx, "notify-send", "--app-name=GetRealityDomain", data.Title, data.Message), nil
+	}
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// certPin 某个目标上次观察到的证书
+type certPin struct {
+	SHA256    string    `json:"sha256"`
+	Domain    string    `json:"domain,omitempty"`
+	FirstSeen time.Time `json:"first_seen"` // 第一次观察到该证书的时间
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// CertPins 跨扫描记录每个目标的证书指纹，标记证书自上次扫描后发生变化的目标：
+// 证书频繁更换往往意味着站点即将迁移到CDN，之后Reality配置会失效
+type CertPins struct {
+	path string
+
+	mu      sync.Mutex
+	pins    map[string]*certPin // 目标键 -> 证书
+	changed int
+}
+
+// LoadCertPins 读取证书记录文件，文件不存在时返回空记录
+func LoadCertPins(path string) (*CertPins, error) {
+	p := &CertPins{path: path, pins: make(map[string]*certPin)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取证书记录失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &p.pins); err != nil {
+		return nil, fmt.Errorf("解析证书记录失败: %v", err)
+	}
+	return p, nil
+}
+
+// Watch 转发扫描结果，证书与上次记录不同的结果在PrevCertSHA256中记录上次的指纹，返回的通道在results关闭后关闭；
+// ctx取消后不再转发，但继续读取results直到其关闭
+func (p *CertPins) Watch(ctx context.Context, results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			p.Observe(&result)
+			select {
+			case out <- result:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+// Observe 比较结果的证书指纹与上次的记录并更新记录，没有证书指纹的结果（握手失败）不影响记录
+func (p *CertPins) Observe(result *scanner.Result) {
+	if result.CertSHA256 == "" {
+		return
+	}
+	key := pinKey(*result)
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pin := p.pins[key]
+	switch {
+	case pin == nil:
+		p.pins[key] = &certPin{SHA256: result.CertSHA256, Domain: result.CertDomain, FirstSeen: now, LastSeen: now}
+		return
+	case pin.SHA256 != result.CertSHA256:
+		result.PrevCertSHA256 = pin.SHA256
+		p.changed++
+		attrs := []any{"ip", result.IP, "port", result.Port, "previous", pin.Domain, "current", result.CertDomain, "since", pin.FirstSeen.Format("2006-01-02")}
+		if result.Feasible {
+			logger.Warn("证书自上次扫描后已变化", attrs...)
+		} else {
+			logger.Debug("证书自上次扫描后已变化", attrs...)
+		}
+		pin.SHA256, pin.Domain, pin.FirstSeen = result.CertSHA256, result.CertDomain, now
+	}
+	pin.LastSeen = now
+}
+
+// Changed 返回本次扫描中证书发生变化的目标数
+func (p *CertPins) Changed() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.changed
+}
+
+// Save 写入证书记录文件，先写临时文件再重命名，避免中断时损坏已有记录
+func (p *CertPins) Save() error {
+	p.mu.Lock()
+	data, err := json.Marshal(p.pins)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("保存证书记录失败: %v", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存证书记录失败: %v", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("保存证书记录失败: %v", err)
+	}
+	return nil
+}
+
+// pinKey 返回结果的记录键：IP、端口和握手使用的SNI，同一IP以不同SNI握手时可能出示不同的证书
+func pinKey(result scanner.Result) string {
+	key := net.JoinHostPort(result.IP, strconv.Itoa(result.Port))
+	if result.SNI != "" {
+		key += "/" + result.SNI
+	}
+	return key
+}
@@ -0,0 +1,402 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+var logger = logging.For("output")
+
+// ResultProcessor 结果处理器
+type ResultProcessor struct {
+	csvWriter      *CSVWriter
+	stats          *Stats // 实时统计，可在处理过程中并发读取
+	lastUpdate     time.Time
+	successResults []scanner.Result // 存储成功的结果
+	sinks          []ResultSink     // 额外的结果输出目标
+	stopper        *scanner.Stopper // 停止条件，为nil时处理到结果通道关闭
+	quiet          bool             // 不在终端显示状态，由全屏界面等接管
+
+	// status 固定在终端底部的状态行，为nil时定期刷新整屏状态
+	status *console.StatusLine
+}
+
+// NewResultProcessor 创建新的结果处理器
+func NewResultProcessor(outputFile string) (*ResultProcessor, error) {
+	csvWriter, err := NewCSVWriter(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultProcessor{
+		csvWriter: csvWriter,
+		stats:     NewStats(0),
+	}, nil
+}
+
+// NewResultProcessorWithProgress 创建带进度的结果处理器，totalTargets为0表示目标总数未知
+func NewResultProcessorWithProgress(outputFile string, totalTargets int) (*ResultProcessor, error) {
+	csvWriter, err := NewCSVWriter(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultProcessor{
+		csvWriter:  csvWriter,
+		stats:      NewStats(totalTargets),
+		lastUpdate: time.Now(),
+	}, nil
+}
+
+// ProcessResults 处理扫描结果，静默模式下不显示状态和最终统计，由调用方在界面关闭后调用PrintSummary
+func (rp *ResultProcessor) ProcessResults(resultChan <-chan scanner.Result) {
+	if rp.status != nil && !rp.quiet {
+		rp.processWithStatusLine(resultChan)
+		rp.PrintSummary()
+		return
+	}
+	if console.Plain() && !rp.quiet {
+		rp.processPlain(resultChan)
+		rp.PrintSummary()
+		return
+	}
+
+	// 初始显示
+	if !rp.quiet {
+		rp.displayFullScreen()
+	}
+
+	// 停止条件触发后只停止分发新目标，仍处理已在各阶段中的结果，直到结果通道关闭
+	for result := range resultChan {
+		rp.Process(result)
+
+		// 每3秒更新一次状态信息
+		if !rp.quiet && time.Since(rp.lastUpdate) >= 3*time.Second {
+			rp.displayFullScreen()
+			rp.lastUpdate = time.Now()
+		}
+	}
+
+	if !rp.quiet {
+		rp.displayFullScreen()
+		rp.PrintSummary()
+	}
+}
+
+// plainProgressInterval 纯文本输出时记录进度日志的间隔
+const plainProgressInterval = 10 * time.Second
+
+// processPlain 处理扫描结果，发现的目标逐行输出，进度定期以日志记录，不清屏
+func (rp *ResultProcessor) processPlain(resultChan <-chan scanner.Result) {
+	for result := range resultChan {
+		rp.Process(result)
+		if result.Error == "" && result.Feasible {
+			fmt.Println(feasibleLine(result))
+		}
+
+		if time.Since(rp.lastUpdate) >= plainProgressInterval {
+			rp.printProgress()
+			rp.lastUpdate = time.Now()
+		}
+	}
+}
+
+// statusLineInterval 底部状态行的刷新间隔
+const statusLineInterval = time.Second
+
+// processWithStatusLine 处理扫描结果，发现的目标逐行输出在状态行上方，状态行定期原位刷新
+func (rp *ResultProcessor) processWithStatusLine(resultChan <-chan scanner.Result) {
+	rp.status.Start()
+	defer rp.status.Stop()
+
+	// 没有结果时状态行也要刷新（速率、用时）
+	ticker := time.NewTicker(statusLineInterval)
+	defer ticker.Stop()
+	rp.status.Set(rp.statusText())
+
+	for {
+		select {
+		case result, ok := <-resultChan:
+			if !ok {
+				return
+			}
+			rp.Process(result)
+			if result.Error == "" && result.Feasible {
+				rp.status.Println(feasibleLine(result))
+			}
+		case <-ticker.C:
+			rp.status.Set(rp.statusText())
+		}
+	}
+}
+
+// feasibleLine 返回实时输出中一个合规目标的摘要行，按响应时间和首选国家着色
+func feasibleLine(result scanner.Result) string {
+	return fmt.Sprintf("✅ %s (%s) - %s %s", result.IP, result.CertDomain,
+		console.Country(result.GeoCode, result.GeoCode),
+		console.Latency(result.ResponseTime, fmt.Sprintf("[%dms]", result.ResponseTime)))
+}
+
+// statusText 返回单行的状态摘要
+func (rp *ResultProcessor) statusText() string {
+	snap := rp.stats.Snapshot()
+	text := fmt.Sprintf("📊 已扫描: %d | 合规: %d | 错误: %d | 速率: %.1f/s",
+		snap.Scanned, snap.Feasible, snap.Errors, snap.Rate)
+	if snap.Skipped > 0 {
+		text += fmt.Sprintf(" | 跳过: %d", snap.Skipped)
+	}
+	if remaining := snap.Remaining(); remaining >= 0 {
+		text += fmt.Sprintf(" | 进度: %.1f%% 剩余: %d", snap.Percentage(), remaining)
+	}
+	return text
+}
+
+// PrintSummary 输出停止原因和最终统计
+func (rp *ResultProcessor) PrintSummary() {
+	if rp.stopper != nil {
+		if reason := rp.stopper.Reason(); reason != "" {
+			fmt.Printf("\n⏹️  %s，停止扫描\n", reason)
+		}
+	}
+	if !console.Plain() {
+		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+	}
+	rp.printFinalStats()
+}
+
+// Process 记录单个扫描结果，符合条件时写入输出，返回是否触发了停止条件
+func (rp *ResultProcessor) Process(result scanner.Result) bool {
+	rp.stats.Record(result)
+
+	// 只有通过所有检测的结果才输出，错误和不符合条件的结果不输出日志，减少噪音
+	if result.Error == "" && result.Feasible {
+		rp.handleFeasible(result)
+	} else if result.Middlebox != "" {
+		// 疑似中间设备干扰的失败也写入结果文件（FEASIBLE为false，读取结果时被忽略），
+		// MIDDLEBOX列记录干扰类型，便于区分被阻断的路径和不可用的主机
+		if err := rp.csvWriter.WriteResult(result); err != nil {
+			logger.Error("写入结果失败", "error", err)
+		}
+	}
+
+	// 检查停止条件
+	snap := rp.stats.Snapshot()
+	return rp.stopper != nil && rp.stopper.Observe(int(snap.Scanned), int(snap.Feasible))
+}
+
+// handleFeasible 写入CSV文件并发送到额外的输出目标
+func (rp *ResultProcessor) handleFeasible(result scanner.Result) {
+	if err := rp.csvWriter.WriteResult(result); err != nil {
+		logger.Error("写入结果失败", "error", err)
+		return
+	}
+
+	// 存储成功结果
+	rp.successResults = append(rp.successResults, result)
+
+	// 发送到额外的输出目标
+	for _, sink := range rp.sinks {
+		if err := sink.Send(result); err != nil {
+			logger.Error("发送结果失败", "error", err)
+		}
+	}
+}
+
+// displayFullScreen 全屏显示扫描状态
+func (rp *ResultProcessor) displayFullScreen() {
+	// 清屏
+	fmt.Print("\033[2J\033[H")
+
+	// 显示标题
+	fmt.Printf("扫描进行中...\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════\n\n")
+
+	snap := rp.stats.Snapshot()
+
+	// 目标总数未知（无限扫描）时不显示进度条和剩余数量
+	if snap.Total > 0 {
+		percentage := snap.Percentage()
+
+		// 计算进度条长度（总共50个字符）
+		const progressBarLength = 50
+		filledLength := int(percentage / 100 * progressBarLength)
+
+		// 构建进度条
+		progressBar := ""
+		for i := 0; i < progressBarLength; i++ {
+			if i < filledLength {
+				progressBar += "▋"
+			} else {
+				progressBar += " "
+			}
+		}
+
+		// 显示进度条
+		fmt.Printf("[%s] %.1f%%\n", progressBar, percentage)
+	} else {
+		fmt.Printf("无限扫描模式，按Ctrl+C停止\n")
+	}
+
+	fmt.Printf("已扫描: %d | 发现合规: %d | 错误: %d | 速率: %.1f/s\n",
+		snap.Scanned, snap.Feasible, snap.Errors, snap.Rate)
+
+	if remaining := snap.Remaining(); remaining >= 0 {
+		fmt.Printf("剩余: %d / %d\n", remaining, snap.Total)
+	}
+	if snap.Skipped > 0 {
+		fmt.Printf("跳过: %d（预过滤或网段无响应）\n", snap.Skipped)
+	}
+
+	fmt.Printf("\n")
+
+	// 显示最近的成功结果（最多显示最后10个）
+	if len(rp.successResults) > 0 {
+		fmt.Printf("最近发现的合规目标:\n")
+		fmt.Printf("─────────────────────────────────────────────────────────────\n")
+
+		start := 0
+		if len(rp.successResults) > 10 {
+			start = len(rp.successResults) - 10
+		}
+
+		for i := start; i < len(rp.successResults); i++ {
+			result := rp.successResults[i]
+			fmt.Println(feasibleLine(result))
+		}
+	}
+}
+
+// printCurrentStatus 打印当前状态信息（保持兼容性）
+func (rp *ResultProcessor) printCurrentStatus() {
+	rp.displayFullScreen()
+}
+
+// printProgress 打印进度信息
+func (rp *ResultProcessor) printProgress() {
+	snap := rp.stats.Snapshot()
+	logger.Info("扫描进度", "scanned", snap.Scanned, "feasible", snap.Feasible, "errors", snap.Errors)
+}
+
+// printFinalStats 打印最终统计信息
+func (rp *ResultProcessor) printFinalStats() {
+	snap := rp.stats.Snapshot()
+
+	fmt.Printf("\n扫描完成！\n")
+	fmt.Printf("总扫描数量: %d\n", snap.Scanned)
+	fmt.Printf("符合条件数: %d (%.1f%%)\n", snap.Feasible, ratio(snap.Feasible, snap.Scanned))
+	fmt.Printf("错误数量: %d (%.1f%%)\n", snap.Errors, ratio(snap.Errors, snap.Scanned))
+	if snap.Errors > 0 {
+		rp.printErrorBreakdown(snap.Errors)
+	}
+	if snap.Skipped > 0 {
+		fmt.Printf("跳过数量: %d（预过滤或网段无响应）\n", snap.Skipped)
+	}
+	fmt.Printf("扫描用时: %v\n", snap.Elapsed.Round(time.Second))
+
+	// 根据结果数量显示不同的消息
+	if snap.Feasible > 0 {
+		fmt.Printf("\n🎉 找到 %d 个符合Reality协议要求的目标！\n", snap.Feasible)
+		fmt.Printf("详细结果已保存到CSV文件中。\n")
+	} else {
+		fmt.Printf("\nℹ️  没有找到符合条件的目标\n")
+	}
+}
+
+// maxFailingSubnets 最终统计中显示的失败最多的网段数
+const maxFailingSubnets = 5
+
+// errorKindNames 错误类别在统计中的名称
+var errorKindNames = map[scanner.ErrorKind]string{
+	scanner.ErrorNone:            "其他",
+	scanner.ErrorDNS:             "域名解析失败",
+	scanner.ErrorUnsupportedHost: "不支持的目标",
+	scanner.ErrorDialTimeout:     "连接超时",
+	scanner.ErrorRefused:         "连接被拒绝",
+	scanner.ErrorReset:           "连接被重置",
+	scanner.ErrorUnreachable:     "网络不可达",
+	scanner.ErrorDial:            "其他连接错误",
+	scanner.ErrorTLSTimeout:      "TLS握手超时",
+	scanner.ErrorTLSAlert:        "TLS告警",
+	scanner.ErrorTLSEOF:          "握手中连接关闭",
+	scanner.ErrorNotTLS:          "不是TLS服务",
+	scanner.ErrorTLS:             "其他TLS错误",
+	scanner.ErrorCertMissing:     "未提供证书",
+	scanner.ErrorCanceled:        "扫描被取消",
+	scanner.ErrorGeoMismatch:     "地理位置不符",
+	scanner.ErrorInterference:    "疑似中间设备干扰",
+}
+
+// printErrorBreakdown 打印各类错误的数量和失败最多的网段，用于判断命中率低是因为空闲地址、封锁还是超时过短
+func (rp *ResultProcessor) printErrorBreakdown(total int64) {
+	fmt.Printf("错误分类:\n")
+	for _, count := range rp.stats.ErrorBreakdown() {
+		name, ok := errorKindNames[count.Kind]
+		if !ok {
+			name = string(count.Kind)
+		}
+		label := name
+		if count.Kind != scanner.ErrorNone {
+			label += " (" + string(count.Kind) + ")"
+		}
+		fmt.Printf("  %s %d (%.1f%%)\n", console.PadRight(label, 34), count.Count, ratio(count.Count, total))
+	}
+
+	subnets := rp.stats.FailingSubnets(maxFailingSubnets)
+	if len(subnets) > 1 {
+		fmt.Printf("失败最多的网段:\n")
+		for _, subnet := range subnets {
+			fmt.Printf("  %s %d / %d 失败\n", console.PadRight(subnet.Subnet.String(), 34), subnet.Failures, subnet.Results)
+		}
+	}
+}
+
+// ratio 计算百分比，total为0时返回0
+func ratio(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// Stats 返回实时统计，可在ProcessResults运行期间从其他协程读取
+func (rp *ResultProcessor) Stats() *Stats {
+	return rp.stats
+}
+
+// SetStopper 设置停止条件，每处理一个结果检查一次，触发后停止处理
+func (rp *ResultProcessor) SetStopper(stopper *scanner.Stopper) {
+	rp.stopper = stopper
+}
+
+// SetStatusLine 设置固定在终端底部的状态行，设置后ProcessResults不再定期清屏刷新整屏状态，
+// 发现的目标逐行输出；调用方应同时将终端日志输出到该状态行，使日志也在其上方滚动
+func (rp *ResultProcessor) SetStatusLine(status *console.StatusLine) {
+	rp.status = status
+}
+
+// SetQuiet 设置静默模式，开启后ProcessResults不在终端显示状态和最终统计
+func (rp *ResultProcessor) SetQuiet(quiet bool) {
+	rp.quiet = quiet
+}
+
+// AddSink 添加额外的结果输出目标，符合条件的结果会同时发送到这些目标
+func (rp *ResultProcessor) AddSink(sink ResultSink) {
+	rp.sinks = append(rp.sinks, sink)
+}
+
+// Close 关闭结果处理器
+func (rp *ResultProcessor) Close() error {
+	for _, sink := range rp.sinks {
+		if err := sink.Close(); err != nil {
+			logger.Error("关闭输出目标失败", "error", err)
+		}
+	}
+	if rp.csvWriter != nil {
+		return rp.csvWriter.Close()
+	}
+	return nil
+}
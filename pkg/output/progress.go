@@ -0,0 +1,156 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressInterval 进度事件的发送间隔
+const ProgressInterval = time.Second
+
+// ProgressEvent 机器可读的进度事件，每行一个JSON对象
+type ProgressEvent struct {
+	Event    string  `json:"event"` // progress 或 done（扫描结束时的最后一个事件）
+	Time     string  `json:"time"`
+	Scanned  int64   `json:"scanned"`
+	Feasible int64   `json:"feasible"`
+	Errors   int64   `json:"errors"`
+	Skipped  int64   `json:"skipped"`
+	Total    int64   `json:"total"`   // 目标总数，0表示未知（无限扫描）
+	Percent  float64 `json:"percent"` // 进度百分比，目标总数未知时为0
+	Rate     float64 `json:"rate"`    // 每秒扫描数
+	Elapsed  float64 `json:"elapsed"` // 已用时间（秒）
+}
+
+// ProgressEmitter 定期发送进度事件，供外部程序和网页前端显示进度，不必解析终端输出
+// 输出到标准错误，或监听Unix套接字向每个连接的客户端广播
+type ProgressEmitter struct {
+	stats    *Stats
+	out      io.Writer    // 标准错误，使用套接字时为nil
+	listener net.Listener // Unix套接字，输出到标准错误时为nil
+
+	mu    sync.Mutex
+	conns []net.Conn
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewProgressEmitter 创建进度事件输出，target为 stderr 或 unix:/path/to.sock
+func NewProgressEmitter(target string, stats *Stats) (*ProgressEmitter, error) {
+	p := &ProgressEmitter{stats: stats, done: make(chan struct{})}
+
+	switch {
+	case target == "stderr":
+		p.out = os.Stderr
+	case strings.HasPrefix(target, "unix:"):
+		path := strings.TrimPrefix(target, "unix:")
+		os.Remove(path) // 清理上次异常退出留下的套接字文件
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("监听进度事件套接字失败: %v", err)
+		}
+		p.listener = listener
+		p.wg.Add(1)
+		go p.acceptLoop()
+	default:
+		return nil, fmt.Errorf("不支持的进度事件输出: %s（可选 stderr 或 unix:/path）", target)
+	}
+
+	p.wg.Add(1)
+	go p.emitLoop()
+	return p, nil
+}
+
+// acceptLoop 接受客户端连接，直到套接字关闭
+func (p *ProgressEmitter) acceptLoop() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		p.conns = append(p.conns, conn)
+		p.mu.Unlock()
+	}
+}
+
+// emitLoop 定期发送进度事件
+func (p *ProgressEmitter) emitLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(ProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.emit("progress")
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// emit 发送一个当前统计的事件，写入失败的客户端被断开
+func (p *ProgressEmitter) emit(event string) {
+	snap := p.stats.Snapshot()
+	line, err := json.Marshal(ProgressEvent{
+		Event:    event,
+		Time:     ScanTime(),
+		Scanned:  snap.Scanned,
+		Feasible: snap.Feasible,
+		Errors:   snap.Errors,
+		Skipped:  snap.Skipped,
+		Total:    snap.Total,
+		Percent:  snap.Percentage(),
+		Rate:     snap.Rate,
+		Elapsed:  snap.Elapsed.Seconds(),
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.out != nil {
+		p.out.Write(line)
+		return
+	}
+	alive := p.conns[:0]
+	for _, conn := range p.conns {
+		conn.SetWriteDeadline(time.Now().Add(ProgressInterval))
+		if _, err := conn.Write(line); err != nil {
+			conn.Close()
+			continue
+		}
+		alive = append(alive, conn)
+	}
+	p.conns = alive
+}
+
+// Close 发送最后的done事件，关闭套接字和所有客户端连接
+func (p *ProgressEmitter) Close() error {
+	close(p.done)
+	if p.listener != nil {
+		p.listener.Close()
+	}
+	p.wg.Wait()
+
+	p.emit("done")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+	return nil
+}
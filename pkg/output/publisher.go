@@ -0,0 +1,252 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 发布通道默认值
+const (
+	defaultPublishChannel = "getrealitydomain.feasible"
+	publishDialTimeout    = 5 * time.Second
+)
+
+// Publisher 将符合条件的结果实时发布到Redis或NATS的消息通道
+type Publisher struct {
+	scheme   string // redis 或 nats
+	address  string // host:port
+	username string
+	password string
+	channel  string // Redis频道或NATS主题
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewPublisher 根据URL创建发布器
+// 支持 redis://[:password@]host[:port]/channel 和 nats://[user:pass@]host[:port]/subject
+func NewPublisher(rawURL string) (*Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的发布地址: %v", err)
+	}
+
+	p := &Publisher{
+		scheme:  strings.ToLower(u.Scheme),
+		channel: strings.TrimPrefix(u.Path, "/"),
+	}
+	if p.channel == "" {
+		p.channel = defaultPublishChannel
+	}
+	if u.User != nil {
+		p.username = u.User.Username()
+		p.password, _ = u.User.Password()
+	}
+
+	var defaultPort string
+	switch p.scheme {
+	case "redis":
+		defaultPort = "6379"
+	case "nats":
+		defaultPort = "4222"
+	default:
+		return nil, fmt.Errorf("不支持的发布协议: %s", u.Scheme)
+	}
+
+	p.address = u.Host
+	if u.Port() == "" {
+		p.address = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// connect 建立连接并完成认证
+func (p *Publisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.address, publishDialTimeout)
+	if err != nil {
+		return fmt.Errorf("连接%s失败: %v", p.scheme, err)
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+
+	if p.scheme == "redis" {
+		err = p.redisHandshake()
+	} else {
+		err = p.natsHandshake()
+	}
+	if err != nil {
+		conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+// redisHandshake Redis认证
+func (p *Publisher) redisHandshake() error {
+	if p.password == "" {
+		return nil
+	}
+
+	args := []string{"AUTH", p.password}
+	if p.username != "" {
+		args = []string{"AUTH", p.username, p.password}
+	}
+	if err := p.redisCommand(args...); err != nil {
+		return fmt.Errorf("Redis认证失败: %v", err)
+	}
+	return nil
+}
+
+// redisCommand 发送一条RESP命令并读取回复
+func (p *Publisher) redisCommand(args ...string) error {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	p.conn.SetDeadline(time.Now().Add(publishDialTimeout))
+	defer p.conn.SetDeadline(time.Time{})
+
+	if _, err := p.conn.Write([]byte(cmd.String())); err != nil {
+		return err
+	}
+
+	reply, err := p.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(reply, "-") {
+		return fmt.Errorf("%s", strings.TrimSpace(reply[1:]))
+	}
+	return nil
+}
+
+// natsHandshake NATS握手：读取INFO，发送CONNECT，并用PING确认连接可用
+func (p *Publisher) natsHandshake() error {
+	p.conn.SetDeadline(time.Now().Add(publishDialTimeout))
+	defer p.conn.SetDeadline(time.Time{})
+
+	info, err := p.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("读取NATS服务信息失败: %v", err)
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		return fmt.Errorf("无效的NATS服务响应: %s", strings.TrimSpace(info))
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "getrealitydomain",
+	}
+	if p.username != "" {
+		options["user"] = p.username
+		options["pass"] = p.password
+	}
+	connectJSON, _ := json.Marshal(options)
+
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\nPING\r\n", connectJSON); err != nil {
+		return fmt.Errorf("发送NATS连接信息失败: %v", err)
+	}
+
+	reply, err := p.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("NATS握手失败: %v", err)
+	}
+	if !strings.HasPrefix(reply, "PONG") {
+		return fmt.Errorf("NATS握手失败: %s", strings.TrimSpace(reply))
+	}
+
+	// 后台响应服务端的PING，避免连接被判定为失效
+	go p.natsReadLoop(p.conn, p.reader)
+	return nil
+}
+
+// natsReadLoop 处理NATS服务端发来的控制消息
+func (p *Publisher) natsReadLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			p.mu.Lock()
+			conn.Write([]byte("PONG\r\n"))
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Send 发布一条结果，连接断开时自动重连一次
+func (p *Publisher) Send(result scanner.Result) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("编码结果失败: %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(payload); err == nil {
+		return nil
+	}
+
+	// 重连后重试
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	if err := p.connect(); err != nil {
+		return err
+	}
+	if err := p.publish(payload); err != nil {
+		return fmt.Errorf("发布结果失败: %v", err)
+	}
+	return nil
+}
+
+// publish 通过当前连接发布消息
+func (p *Publisher) publish(payload []byte) error {
+	if p.conn == nil {
+		return fmt.Errorf("连接未建立")
+	}
+
+	if p.scheme == "redis" {
+		return p.redisCommand("PUBLISH", p.channel, string(payload))
+	}
+
+	p.conn.SetWriteDeadline(time.Now().Add(publishDialTimeout))
+	defer p.conn.SetWriteDeadline(time.Time{})
+	_, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\n", p.channel, len(payload), payload)
+	return err
+}
+
+// String 返回发布目标的描述，如 redis/频道名
+func (p *Publisher) String() string {
+	return p.scheme + "/" + p.channel
+}
+
+// Close 关闭连接
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/console"
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+)
+
+// PrintRealityTargets 打印符合Reality要求的目标
+func PrintRealityTargets(filename string) error {
+	feasibleTargets, err := LoadFeasibleRecords(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(feasibleTargets) == 0 {
+		logger.Info("没有找到符合Reality要求的目标")
+		return nil
+	}
+
+	// 打印结果
+	fmt.Println()
+	console.Box([]string{
+		"",
+		"                    ═══ Reality目标列表 ═══",
+		"",
+		fmt.Sprintf("    找到 %d 个符合条件的目标:", len(feasibleTargets)),
+		"",
+	})
+
+	fmt.Printf("%s %s %s %s %s\n",
+		console.PadRight("IP地址", 15), console.PadRight("证书域名", 25), console.PadRight("地理位置", 10),
+		console.PadRight("证书颁发者", 20), "响应时间(ms)")
+	fmt.Println(strings.Repeat("-", 85))
+
+	for _, record := range feasibleTargets {
+		fmt.Printf("%s %s %s %s %s\n",
+			console.PadRight(record[0], 15),                       // IP
+			console.PadRight(console.Truncate(record[3], 25), 25), // CERT_DOMAIN
+			console.PadRight(record[8], 10),                       // GEO_CODE
+			console.PadRight(console.Truncate(record[4], 20), 20), // CERT_ISSUER
+			record[10], // RESPONSE_TIME_MS
+		)
+	}
+
+	fmt.Println()
+	return nil
+}
+
+// ExportRealityConfig 导出Reality配置文件
+func ExportRealityConfig(filename string, configFile string) error {
+	header, feasibleTargets, err := LoadFeasibleTable(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(feasibleTargets) == 0 {
+		return fmt.Errorf("没有找到符合条件的目标")
+	}
+
+	// 创建配置文件
+	configFileHandle, err := os.Create(configFile)
+	if err != nil {
+		return fmt.Errorf("创建配置文件失败: %v", err)
+	}
+	defer configFileHandle.Close()
+
+	WriteRealityConfig(configFileHandle, header, feasibleTargets)
+
+	logging.Success(logger, "Reality配置已导出", "path", configFile)
+	return nil
+}
+
+// WriteRealityConfig 将结果记录写为Reality配置模板
+func WriteRealityConfig(w io.Writer, header []string, feasibleTargets [][]string) {
+	fmt.Fprintf(w, "# Reality目标配置文件\n")
+	fmt.Fprintf(w, "# 生成时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "# 总共找到 %d 个符合条件的目标\n\n", len(feasibleTargets))
+
+	for i, record := range feasibleTargets {
+		fmt.Fprintf(w, "# 目标 %d\n", i+1)
+		if grade := recordColumn(header, record, "CONFIDENCE"); grade != "" {
+			fmt.Fprintf(w, "# 可信度: %s\n", grade)
+		}
+		fmt.Fprintf(w, "dest: %s:%s\n", record[0], record[2]) // IP, PORT
+		fmt.Fprintf(w, "serverNames: [\"%s\"]\n", record[3])  // CERT_DOMAIN
+		fmt.Fprintf(w, "# 地理位置: %s\n", record[8])             // GEO_CODE
+		fmt.Fprintf(w, "# 证书颁发者: %s\n", record[4])            // CERT_ISSUER
+		fmt.Fprintf(w, "# 响应时间: %sms\n\n", record[10])        // RESPONSE_TIME_MS
+	}
+}
+
+// Clash.Meta配置中需要用户自行替换的占位符
+const (
+	ClashServerPlaceholder    = "YOUR_SERVER_IP"
+	ClashUUIDPlaceholder      = "YOUR_UUID"
+	ClashPublicKeyPlaceholder = "YOUR_REALITY_PUBLIC_KEY"
+	ClashShortIDPlaceholder   = "YOUR_SHORT_ID"
+)
+
+// ExportClashProvider 导出Clash.Meta的proxy-provider配置文件
+// selected为要导出的目标序号（从1开始，与结果列表中的序号一致），为空时导出全部
+func ExportClashProvider(filename string, providerFile string, selected []int) error {
+	header, feasibleTargets, err := LoadFeasibleTable(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(feasibleTargets) == 0 {
+		return fmt.Errorf("没有找到符合条件的目标")
+	}
+
+	// 按序号筛选目标
+	if len(selected) > 0 {
+		var chosen [][]string
+		for _, index := range selected {
+			if index < 1 || index > len(feasibleTargets) {
+				return fmt.Errorf("序号超出范围: %d", index)
+			}
+			chosen = append(chosen, feasibleTargets[index-1])
+		}
+		feasibleTargets = chosen
+	}
+
+	providerFileHandle, err := os.Create(providerFile)
+	if err != nil {
+		return fmt.Errorf("创建配置文件失败: %v", err)
+	}
+	defer providerFileHandle.Close()
+
+	WriteClashProvider(providerFileHandle, header, feasibleTargets)
+
+	logging.Success(logger, "Clash.Meta配置已导出", "path", providerFile, "targets", len(feasibleTargets))
+	return nil
+}
+
+// WriteClashProvider 将结果记录写为Clash.Meta的proxy-provider模板
+func WriteClashProvider(w io.Writer, header []string, feasibleTargets [][]string) {
+	fmt.Fprintf(w, "# Clash.Meta proxy-provider (vless + reality)\n")
+	fmt.Fprintf(w, "# 生成时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "# 使用前请替换 %s / %s / %s / %s\n\n",
+		ClashServerPlaceholder, ClashUUIDPlaceholder, ClashPublicKeyPlaceholder, ClashShortIDPlaceholder)
+	fmt.Fprintf(w, "proxies:\n")
+
+	for i, record := range feasibleTargets {
+		port := recordColumn(header, record, "PORT")
+		if port == "" {
+			port = "443"
+		}
+		serverName := recordColumn(header, record, "SNI")
+		if serverName == "" {
+			serverName = PrimaryServerName(recordColumn(header, record, "CERT_DOMAIN"))
+		}
+		fmt.Fprintf(w, "  # dest: %s  地理位置: %s  响应时间: %sms",
+			net.JoinHostPort(recordColumn(header, record, "IP"), port),
+			recordColumn(header, record, "GEO_CODE"), recordColumn(header, record, "RESPONSE_TIME_MS"))
+		if grade := recordColumn(header, record, "CONFIDENCE"); grade != "" {
+			fmt.Fprintf(w, "  可信度: %s", grade)
+		}
+		fmt.Fprintln(w)
+		// 名称和SNI来自证书，可能包含YAML特殊字符（如开头的*），以双引号字符串输出
+		fmt.Fprintf(w, "  - name: %s\n", strconv.Quote(fmt.Sprintf("reality-%d-%s", i+1, serverName)))
+		fmt.Fprintf(w, "    type: vless\n")
+		fmt.Fprintf(w, "    server: %s\n", ClashServerPlaceholder)
+		fmt.Fprintf(w, "    port: %s\n", port)
+		fmt.Fprintf(w, "    uuid: %s\n", ClashUUIDPlaceholder)
+		fmt.Fprintf(w, "    network: tcp\n")
+		fmt.Fprintf(w, "    udp: true\n")
+		fmt.Fprintf(w, "    tls: true\n")
+		fmt.Fprintf(w, "    flow: xtls-rprx-vision\n")
+		fmt.Fprintf(w, "    servername: %s\n", strconv.Quote(serverName))
+		fmt.Fprintf(w, "    client-fingerprint: chrome\n")
+		fmt.Fprintf(w, "    reality-opts:\n")
+		fmt.Fprintf(w, "      public-key: %s\n", ClashPublicKeyPlaceholder)
+		fmt.Fprintf(w, "      short-id: %s\n\n", ClashShortIDPlaceholder)
+	}
+}
+
+// recordColumn 按列名取记录中的值，列不存在时（旧版本的结果文件）返回空字符串
+func recordColumn(header, record []string, name string) string {
+	for i, column := range header {
+		if column == name && i < len(record) {
+			return record[i]
+		}
+	}
+	return ""
+}
+
+// RealitySnippet 生成单个目标的Reality配置片段（xray realitySettings中的dest和serverNames），
+// serverNames为证书中的非通配符域名，首选域名在前
+func RealitySnippet(ip, port, certDomain string) string {
+	primary := PrimaryServerName(certDomain)
+	serverNames := []string{strconv.Quote(primary)}
+	for _, domain := range strings.Split(certDomain, ",") {
+		domain = strings.TrimSpace(domain)
+		if domain != "" && domain != primary && !strings.HasPrefix(domain, "*.") {
+			serverNames = append(serverNames, strconv.Quote(domain))
+		}
+	}
+	return fmt.Sprintf("\"dest\": %q,\n\"serverNames\": [%s]", net.JoinHostPort(ip, port), strings.Join(serverNames, ", "))
+}
+
+// PrimaryServerName 从证书域名列表中选出适合作为serverName的域名（跳过通配符域名）
+func PrimaryServerName(certDomain string) string {
+	domains := strings.Split(certDomain, ",")
+	for _, domain := range domains {
+		domain = strings.TrimSpace(domain)
+		if domain != "" && !strings.HasPrefix(domain, "*.") {
+			return domain
+		}
+	}
+	return strings.TrimPrefix(strings.TrimSpace(domains[0]), "*.")
+}
+
+// FormatBytes 格式化字节数为人类可读的格式
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
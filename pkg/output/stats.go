@@ -0,0 +1,206 @@
+package output
+
+import (
+	"net/netip"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// maxTrackedSubnets 统计中保留的网段数上限，超出时只保留失败最多的一半，
+// 被清除的网段再次出现时重新计数（计数可能偏少），最终统计只显示失败最多的几个网段
+const maxTrackedSubnets = 4096
+
+// Stats 扫描统计，计数均为原子操作，扫描过程中可被其他组件（界面、指标接口等）并发读取
+type Stats struct {
+	scanned   atomic.Int64
+	feasible  atomic.Int64
+	errors    atomic.Int64
+	skipped   atomic.Int64
+	total     int64 // 目标总数，0表示未知
+	startTime time.Time
+
+	// 按错误类别和网段的计数，用于最终统计中的失败分析
+	mu      sync.Mutex
+	kinds   map[scanner.ErrorKind]int64
+	subnets map[netip.Prefix]*SubnetFailures
+}
+
+// ErrorCount 某一类错误的数量
+type ErrorCount struct {
+	Kind  scanner.ErrorKind
+	Count int64
+}
+
+// SubnetFailures 网段（IPv4为/24，IPv6为/48）的结果数和失败数
+type SubnetFailures struct {
+	Subnet   netip.Prefix
+	Results  int64
+	Failures int64
+}
+
+// StatsSnapshot 某一时刻的统计快照
+type StatsSnapshot struct {
+	Scanned  int64         // 已扫描数
+	Feasible int64         // 符合条件数
+	Errors   int64         // 错误数
+	Skipped  int64         // 跳过未扫描的结果数（如所在网段无响应）
+	Total    int64         // 目标总数，0表示未知
+	Elapsed  time.Duration // 已用时间
+	Rate     float64       // 每秒扫描数
+}
+
+// NewStats 创建统计，totalTargets为0表示目标总数未知
+func NewStats(totalTargets int) *Stats {
+	return &Stats{
+		total:     int64(totalTargets),
+		startTime: time.Now(),
+		kinds:     make(map[scanner.ErrorKind]int64),
+		subnets:   make(map[netip.Prefix]*SubnetFailures),
+	}
+}
+
+// Record 记录一个扫描结果
+func (s *Stats) Record(result scanner.Result) {
+	s.scanned.Add(1)
+	failed := result.Error != ""
+	if failed {
+		s.errors.Add(1)
+	} else if result.Feasible {
+		s.feasible.Add(1)
+	}
+
+	subnet, ok := resultSubnet(result.Addr)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if failed {
+		s.kinds[result.ErrorKind]++
+	}
+	if ok {
+		counts := s.subnets[subnet]
+		if counts == nil {
+			counts = &SubnetFailures{Subnet: subnet}
+			s.subnets[subnet] = counts
+		}
+		counts.Results++
+		if failed {
+			counts.Failures++
+		}
+		if len(s.subnets) > maxTrackedSubnets {
+			s.pruneSubnets()
+		}
+	}
+}
+
+// pruneSubnets 只保留失败最多的一半网段，调用者持有mu
+func (s *Stats) pruneSubnets() {
+	subnets := make([]*SubnetFailures, 0, len(s.subnets))
+	for _, counts := range s.subnets {
+		subnets = append(subnets, counts)
+	}
+	sort.Slice(subnets, func(i, j int) bool {
+		return subnets[i].Failures > subnets[j].Failures
+	})
+	for _, counts := range subnets[maxTrackedSubnets/2:] {
+		delete(s.subnets, counts.Subnet)
+	}
+}
+
+// resultSubnet 返回结果IP所在的网段，IPv4为/24，IPv6为/48
+func resultSubnet(addr netip.Addr) (netip.Prefix, bool) {
+	if !addr.IsValid() {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	bits := 24
+	if addr.Is6() {
+		bits = 48
+	}
+	prefix, err := addr.Prefix(bits)
+	return prefix, err == nil
+}
+
+// ErrorBreakdown 返回各类错误的数量，按数量从多到少排列
+func (s *Stats) ErrorBreakdown() []ErrorCount {
+	s.mu.Lock()
+	counts := make([]ErrorCount, 0, len(s.kinds))
+	for kind, count := range s.kinds {
+		counts = append(counts, ErrorCount{Kind: kind, Count: count})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Kind < counts[j].Kind
+	})
+	return counts
+}
+
+// FailingSubnets 返回失败最多的n个网段，没有失败的网段不返回
+func (s *Stats) FailingSubnets(n int) []SubnetFailures {
+	s.mu.Lock()
+	var subnets []SubnetFailures
+	for _, counts := range s.subnets {
+		if counts.Failures > 0 {
+			subnets = append(subnets, *counts)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(subnets, func(i, j int) bool {
+		if subnets[i].Failures != subnets[j].Failures {
+			return subnets[i].Failures > subnets[j].Failures
+		}
+		return subnets[i].Subnet.Addr().Less(subnets[j].Subnet.Addr())
+	})
+	return subnets[:min(n, len(subnets))]
+}
+
+// Skip 记录n个跳过未扫描的结果，计入进度但不计入已扫描数
+func (s *Stats) Skip(n int) {
+	s.skipped.Add(int64(n))
+}
+
+// Snapshot 返回当前统计快照
+func (s *Stats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{
+		Scanned:  s.scanned.Load(),
+		Feasible: s.feasible.Load(),
+		Errors:   s.errors.Load(),
+		Skipped:  s.skipped.Load(),
+		Total:    s.total,
+		Elapsed:  time.Since(s.startTime),
+	}
+	if seconds := snap.Elapsed.Seconds(); seconds > 0 {
+		snap.Rate = float64(snap.Scanned) / seconds
+	}
+	return snap
+}
+
+// Remaining 返回剩余目标数，目标总数未知时返回-1
+func (snap StatsSnapshot) Remaining() int64 {
+	if snap.Total <= 0 {
+		return -1
+	}
+	if remaining := snap.Total - snap.Scanned - snap.Skipped; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Percentage 返回扫描进度百分比(0-100)，目标总数未知时返回0
+func (snap StatsSnapshot) Percentage() float64 {
+	if snap.Total <= 0 {
+		return 0
+	}
+	if percentage := float64(snap.Scanned+snap.Skipped) / float64(snap.Total) * 100; percentage < 100 {
+		return percentage
+	}
+	return 100
+}
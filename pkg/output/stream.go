@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// 流式输出的格式
+const (
+	StreamCSV   = "csv"   // 与结果文件相同的列，首行为列名
+	StreamJSONL = "jsonl" // 每行一个JSON对象
+)
+
+// streamRecord JSONL格式的单条记录
+type streamRecord struct {
+	scanner.Result
+	ScanTime string `json:"scan_time"` // 扫描时间
+}
+
+// StreamWriter 将符合条件的结果逐条写入标准输出等Writer，每条写入后立即刷新，
+// 便于在管道中交给grep、jq等程序实时处理
+type StreamWriter struct {
+	mu     sync.Mutex
+	format string
+	out    io.Writer
+	csv    *csv.Writer // CSV格式时使用
+	record []string    // 复用的记录缓冲区
+}
+
+// NewStreamWriter 创建流式输出，format为csv或jsonl；csv格式立即写入列名
+func NewStreamWriter(out io.Writer, format string) (*StreamWriter, error) {
+	s := &StreamWriter{format: format, out: out}
+	switch format {
+	case StreamCSV:
+		s.csv = csv.NewWriter(out)
+		s.csv.Write(resultHeader())
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return nil, fmt.Errorf("写入CSV头部失败: %v", err)
+		}
+	case StreamJSONL:
+	default:
+		return nil, fmt.Errorf("不支持的输出格式: %s（可选 csv、jsonl）", format)
+	}
+	return s, nil
+}
+
+// Send 写入一条结果，实现ResultSink
+func (s *StreamWriter) Send(result scanner.Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.csv != nil {
+		s.record = formatRecord(s.record[:0], result)
+		s.csv.Write(s.record)
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return fmt.Errorf("写入CSV记录失败: %v", err)
+		}
+		return nil
+	}
+
+	payload, err := json.Marshal(streamRecord{Result: result, ScanTime: ScanTime()})
+	if err != nil {
+		return fmt.Errorf("编码结果失败: %v", err)
+	}
+	if _, err := s.out.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("写入结果失败: %v", err)
+	}
+	return nil
+}
+
+// Close 实现ResultSink，Writer由调用方关闭
+func (s *StreamWriter) Close() error {
+	return nil
+}
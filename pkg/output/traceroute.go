@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// TracerouteSuffix 路由跟踪结果文件相对于输出文件的后缀
+const TracerouteSuffix = ".traceroute.txt"
+
+// WriteTraceroutes 将路由跟踪结果写入文本文件：先列出多个目标共同经过的节点（出现次数越多，
+// 越可能是与本机共用的上游线路），再逐个列出每个目标的完整路径
+func WriteTraceroutes(filename string, paths []scanner.TracePath) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建路由跟踪文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "# 路由跟踪结果\n")
+	fmt.Fprintf(w, "# 生成时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "# 目标数: %d\n\n", len(paths))
+
+	// 统计各节点出现在多少条路径中
+	shared := make(map[netip.Addr]int)
+	for _, path := range paths {
+		seen := make(map[netip.Addr]bool)
+		for _, hop := range path.Hops {
+			if hop.Addr.IsValid() && hop.Addr != path.Target && !seen[hop.Addr] {
+				seen[hop.Addr] = true
+				shared[hop.Addr]++
+			}
+		}
+	}
+	var common []netip.Addr
+	for addr, count := range shared {
+		if count > 1 {
+			common = append(common, addr)
+		}
+	}
+	slices.SortFunc(common, func(a, b netip.Addr) int {
+		if shared[a] != shared[b] {
+			return shared[b] - shared[a]
+		}
+		return a.Compare(b)
+	})
+
+	fmt.Fprintf(w, "== 共同节点 ==\n")
+	if len(common) == 0 {
+		fmt.Fprintf(w, "（无）\n")
+	}
+	for _, addr := range common {
+		fmt.Fprintf(w, "%-40s %d/%d\n", addr, shared[addr], len(paths))
+	}
+
+	for _, path := range paths {
+		fmt.Fprintf(w, "\n== %s", path.Target)
+		if path.Domain != "" {
+			fmt.Fprintf(w, " (%s)", path.Domain)
+		}
+		switch {
+		case path.Err != nil:
+			fmt.Fprintf(w, " ==\n跟踪失败: %v\n", path.Err)
+			continue
+		case path.Reached:
+			fmt.Fprintf(w, " == %d跳\n", len(path.Hops))
+		default:
+			fmt.Fprintf(w, " == 未到达\n")
+		}
+
+		for _, hop := range path.Hops {
+			if !hop.Addr.IsValid() {
+				fmt.Fprintf(w, "%3d  *\n", hop.TTL)
+				continue
+			}
+			fmt.Fprintf(w, "%3d  %-40s %.1fms\n", hop.TTL, hop.Addr, float64(hop.RTT.Microseconds())/1000)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入路由跟踪文件失败: %v", err)
+	}
+	return nil
+}
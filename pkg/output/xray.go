@@ -0,0 +1,150 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+	"github.com/xtls/xray-core/app/proxyman/command"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/infra/conf/serial"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// xrayAPITimeout 替换入站时调用xray API（删除、添加及失败时恢复）的总超时
+const xrayAPITimeout = 15 * time.Second
+
+// XrayRotator 通过xray的gRPC API（HandlerService的RemoveInbound / AddInbound）替换运行中入站的Reality dest，不必手动修改配置和重启xray
+// 入站配置文件是只包含该入站的xray配置（{"inbounds":[...]}），替换时就地更新其中realitySettings的dest和serverNames，
+// 其余字段（密钥、shortIds等）保持不变；xray需要在api.services中启用HandlerService
+type XrayRotator struct {
+	Server  string // xray API地址，如 127.0.0.1:10085
+	Inbound string // 入站配置文件
+}
+
+// Dest 返回入站当前使用的dest
+func (x *XrayRotator) Dest() (string, error) {
+	_, _, reality, err := x.load()
+	if err != nil {
+		return "", err
+	}
+	dest, _ := reality[realityDestKey(reality)].(string)
+	return dest, nil
+}
+
+// Rotate 将入站的dest替换为结果对应的目标：通过API删除并以新的dest重新添加该入站，成功后再更新入站配置文件；
+// 添加失败时重新添加原来的入站，配置文件保持不变
+func (x *XrayRotator) Rotate(ctx context.Context, result scanner.Result) error {
+	previous, config, reality, err := x.load()
+	if err != nil {
+		return err
+	}
+	serverName := result.SNI
+	if serverName == "" {
+		serverName = PrimaryServerName(result.CertDomain)
+	}
+	reality[realityDestKey(reality)] = net.JoinHostPort(result.IP, strconv.Itoa(result.Port))
+	reality["serverNames"] = []string{serverName}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	handlers, err := buildInbounds(data)
+	if err != nil {
+		return err
+	}
+	rollback, err := buildInbounds(previous)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, xrayAPITimeout)
+	defer cancel()
+	conn, err := grpc.NewClient(x.Server, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("连接xray API失败: %v", err)
+	}
+	defer conn.Close()
+	client := command.NewHandlerServiceClient(conn)
+
+	for i, handler := range handlers {
+		// 同一tag和端口不能同时存在两个入站，只能先删除再添加；删除失败（如入站尚未添加）时仍尝试添加
+		removed := true
+		if _, err := client.RemoveInbound(ctx, &command.RemoveInboundRequest{Tag: handler.Tag}); err != nil {
+			logger.Warn("删除xray入站失败", "tag", handler.Tag, "error", err)
+			removed = false
+		}
+		if _, err := client.AddInbound(ctx, &command.AddInboundRequest{Inbound: handler}); err != nil {
+			if removed && i < len(rollback) {
+				if _, rollbackErr := client.AddInbound(ctx, &command.AddInboundRequest{Inbound: rollback[i]}); rollbackErr != nil {
+					return fmt.Errorf("添加xray入站失败: %v，恢复原入站也失败: %v", err, rollbackErr)
+				}
+			}
+			return fmt.Errorf("添加xray入站失败，已恢复原入站: %v", err)
+		}
+	}
+
+	// xray已使用新的dest，再更新配置文件，使其与运行中的入站一致
+	tmp := x.Inbound + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("写入入站配置失败: %v", err)
+	}
+	if err := os.Rename(tmp, x.Inbound); err != nil {
+		return fmt.Errorf("写入入站配置失败: %v", err)
+	}
+	return nil
+}
+
+// buildInbounds 与xray的配置加载相同，将JSON入站配置转换为API使用的InboundHandlerConfig
+func buildInbounds(data []byte) ([]*core.InboundHandlerConfig, error) {
+	xrayConfig, err := serial.DecodeJSONConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析入站配置失败: %v", err)
+	}
+	handlers := make([]*core.InboundHandlerConfig, 0, len(xrayConfig.InboundConfigs))
+	for _, inbound := range xrayConfig.InboundConfigs {
+		handler, err := inbound.Build()
+		if err != nil {
+			return nil, fmt.Errorf("解析入站配置失败: %v", err)
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// load 读取入站配置文件，返回文件内容、整个配置和第一个Reality入站的realitySettings
+func (x *XrayRotator) load() ([]byte, map[string]any, map[string]any, error) {
+	data, err := os.ReadFile(x.Inbound)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("读取入站配置失败: %v", err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, nil, fmt.Errorf("解析入站配置失败: %v", err)
+	}
+	inbounds, _ := config["inbounds"].([]any)
+	for _, inbound := range inbounds {
+		inbound, _ := inbound.(map[string]any)
+		stream, _ := inbound["streamSettings"].(map[string]any)
+		if reality, ok := stream["realitySettings"].(map[string]any); ok {
+			return data, config, reality, nil
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("入站配置 %s 中没有包含realitySettings的入站", x.Inbound)
+}
+
+// realityDestKey 返回realitySettings中dest使用的字段名，新版本的xray也接受target
+func realityDestKey(reality map[string]any) string {
+	if _, ok := reality["target"]; ok {
+		return "target"
+	}
+	return "dest"
+}
@@ -0,0 +1,113 @@
+package reverseip
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+// SourcePrefix 反查得到的验证目标在结果Origin中的来源前缀，后接服务名称
+const SourcePrefix = "reverse-ip:"
+
+// Enricher 收集握手成功但证书为空或通用证书的IP目标，扫描结束后反查托管在这些IP上的域名，
+// 生成以候选域名为SNI、直接连接原IP的验证目标
+type Enricher struct {
+	Providers  []Provider         // 依次查询的反查服务
+	Client     scanner.HTTPClient // 为nil时使用http.DefaultClient
+	MaxLookups int                // 最多反查的IP数，0表示不限制（免费接口通常有每日次数限制）
+	MaxDomains int                // 每个IP最多验证的候选域名数，0表示不限制
+
+	mu         sync.Mutex
+	candidates []netip.Addr
+	seen       map[netip.Addr]bool
+}
+
+// Watch 转发扫描结果并从中收集反查候选，返回的通道在results关闭后关闭
+func (e *Enricher) Watch(results <-chan scanner.Result) <-chan scanner.Result {
+	out := make(chan scanner.Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			e.Observe(result)
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Observe 检查单个结果，IP目标完成TLS 1.3握手且证书为空或通用证书时记录为反查候选
+func (e *Enricher) Observe(result scanner.Result) {
+	if result.Error != "" || result.SNI != "" || result.TLSVersion != "TLS 1.3" || !IsGenericCert(result.CertDomain) {
+		return
+	}
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.seen == nil {
+		e.seen = make(map[netip.Addr]bool)
+	}
+	if !e.seen[ip] {
+		e.seen[ip] = true
+		e.candidates = append(e.candidates, ip)
+	}
+}
+
+// Candidates 返回已收集的反查候选数
+func (e *Enricher) Candidates() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.candidates)
+}
+
+// Targets 反查所有候选IP，返回以发现的域名为SNI的验证目标
+// 某个服务查询失败时继续尝试下一个服务，ctx取消时返回已得到的目标
+func (e *Enricher) Targets(ctx context.Context) []scanner.Host {
+	e.mu.Lock()
+	candidates := append([]netip.Addr(nil), e.candidates...)
+	e.mu.Unlock()
+
+	if e.MaxLookups > 0 && len(candidates) > e.MaxLookups {
+		logger.Warn("反查候选过多，只查询前一部分", "candidates", len(candidates), "limit", e.MaxLookups)
+		candidates = candidates[:e.MaxLookups]
+	}
+
+	var hosts []scanner.Host
+	for _, ip := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+
+		seen := make(map[string]bool)
+		count := 0
+		for _, provider := range e.Providers {
+			domains, err := provider.Lookup(ctx, e.Client, ip)
+			if err != nil {
+				logger.Warn("反查IP失败", "ip", ip, "provider", provider.Name, "error", err)
+				continue
+			}
+			logger.Debug("反查IP完成", "ip", ip, "provider", provider.Name, "domains", len(domains))
+
+			for _, domain := range domains {
+				if seen[domain] || (e.MaxDomains > 0 && count >= e.MaxDomains) {
+					continue
+				}
+				seen[domain] = true
+				count++
+				hosts = append(hosts, scanner.Host{
+					IP:     ip,
+					Origin: domain,
+					Type:   scanner.HostTypeIP,
+					Source: SourcePrefix + provider.Name,
+				})
+			}
+		}
+	}
+
+	return hosts
+}
@@ -0,0 +1,169 @@
+// Package reverseip 通过反查IP服务（HackerTarget、RapidDNS或自定义接口）发现托管在同一IP上的域名，
+// 用于为证书为空或通用证书的IP目标补充候选serverName。
+package reverseip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/scanner"
+)
+
+var logger = logging.For("reverseip")
+
+// maxResponseSize 反查接口响应的最大读取长度
+const maxResponseSize = 4 << 20
+
+// Provider 反查IP服务
+type Provider struct {
+	Name string // 服务名称，记录在结果的来源中
+	URL  string // 请求地址模板，{ip}替换为要反查的IP
+
+	// Pattern 从响应中提取域名的正则表达式，使用第一个分组
+	// 为nil时将响应按空白、引号和标签分割，取其中的有效域名
+	Pattern *regexp.Regexp
+}
+
+// 内置的反查服务
+var (
+	HackerTarget = Provider{
+		Name: "hackertarget",
+		URL:  "https://api.hackertarget.com/reverseiplookup/?q={ip}",
+	}
+	RapidDNS = Provider{
+		Name:    "rapiddns",
+		URL:     "https://rapiddns.io/sameip/{ip}?full=1",
+		Pattern: regexp.MustCompile(`<td>([a-zA-Z0-9][a-zA-Z0-9.\-]*\.[a-zA-Z]{2,})</td>`),
+	}
+)
+
+// ParseProviders 解析逗号分隔的服务列表，支持内置名称（hackertarget、rapiddns）
+// 以及包含{ip}占位符的自定义地址
+func ParseProviders(spec string) ([]Provider, error) {
+	var providers []Provider
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+		case strings.EqualFold(part, HackerTarget.Name):
+			providers = append(providers, HackerTarget)
+		case strings.EqualFold(part, RapidDNS.Name):
+			providers = append(providers, RapidDNS)
+		case strings.Contains(part, "{ip}"):
+			u, err := url.Parse(strings.ReplaceAll(part, "{ip}", "0.0.0.0"))
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, fmt.Errorf("无效的反查地址: %s", part)
+			}
+			providers = append(providers, Provider{Name: u.Host, URL: part})
+		default:
+			return nil, fmt.Errorf("未知的反查服务: %s（支持 hackertarget、rapiddns 或包含{ip}的地址）", part)
+		}
+	}
+	return providers, nil
+}
+
+// Lookup 查询托管在ip上的域名，返回去重后的小写域名
+func (p Provider) Lookup(ctx context.Context, client scanner.HTTPClient, ip netip.Addr) ([]string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := strings.ReplaceAll(p.URL, "{ip}", url.PathEscape(ip.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求%s失败: %v", p.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s返回状态码: %d", p.Name, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取%s响应失败: %v", p.Name, err)
+	}
+
+	return p.extract(string(body), req.URL.Hostname()), nil
+}
+
+// extract 从响应中提取域名，跳过IP地址和反查服务自身的域名
+func (p Provider) extract(body, self string) []string {
+	var candidates []string
+	if p.Pattern != nil {
+		for _, match := range p.Pattern.FindAllStringSubmatch(body, -1) {
+			candidates = append(candidates, match[1])
+		}
+	} else {
+		candidates = strings.FieldsFunc(body, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == ',' || r == '"' || r == '\'' || r == '<' || r == '>'
+		})
+	}
+
+	var domains []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		domain := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(candidate), "."))
+		if seen[domain] || domain == self || strings.HasSuffix(domain, "."+self) {
+			continue
+		}
+		if _, err := netip.ParseAddr(domain); err == nil {
+			continue
+		}
+		if !strings.Contains(domain, ".") || !scanner.ValidateDomainName(domain) {
+			continue
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+// genericNames 常见的默认证书名称，出现时视为通用证书
+var genericNames = []string{
+	"localhost",
+	"example.com",
+	"invalid",
+	"traefik.default",
+	"kubernetes",
+	"ingress.local",
+}
+
+// IsGenericCert 判断证书域名是否为空或通用证书（只有通配符域名或默认证书名称），
+// 这类证书无法直接确定可用的serverName
+func IsGenericCert(certDomain string) bool {
+	if certDomain == "" {
+		return true
+	}
+
+	for _, domain := range strings.Split(certDomain, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || strings.HasPrefix(domain, "*.") {
+			continue
+		}
+		generic := false
+		for _, name := range genericNames {
+			if strings.Contains(domain, name) {
+				generic = true
+				break
+			}
+		}
+		if !generic {
+			return false
+		}
+	}
+	return true
+}
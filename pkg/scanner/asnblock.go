@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"cmp"
+	"context"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+)
+
+// maxDeferredHosts 延后扫描的目标最多暂存的数量，超出的目标直接跳过
+const maxDeferredHosts = 1 << 16
+
+// cdnMarkers 自治系统组织或证书域名中表示CDN的关键字，用于统计各ASN的CDN比例
+var cdnMarkers = []string{"cloudflare", "akamai", "fastly", "cloudfront", "edgecast", "cdn77", "incapsula", "stackpath"}
+
+// ASNStats 单个自治系统在本次扫描中的结果统计
+type ASNStats struct {
+	ASN      uint
+	Org      string
+	Results  int // 结果数
+	Failures int // 失败的结果数
+	CDN      int // 属于CDN的结果数
+	Feasible int // 符合条件的结果数
+}
+
+// ASNBlocklist 自适应ASN屏蔽：统计扫描过程中各自治系统的失败和CDN比例，产生指定数量的结果
+// 仍没有符合条件目标的ASN被判定为低产，其剩余地址被跳过或延后到其他地址之后扫描，
+// 把剩余的扫描时间集中在有产出的网络上；之后发现符合条件的目标时自动解除
+type ASNBlocklist struct {
+	db        *geo.Geo
+	threshold int
+
+	// Defer 为true时低产ASN的地址延后扫描（最多暂存maxDeferredHosts个），为false时直接跳过
+	Defer bool
+
+	// OnSkip 目标因所在ASN低产被跳过时调用（在目标迭代协程中），可用于修正进度统计
+	OnSkip func(Host)
+
+	mu       sync.Mutex
+	stats    map[uint]*ASNStats
+	skipped  int
+	deferred int
+}
+
+// NewASNBlocklist 创建自适应ASN屏蔽，threshold为判定ASN低产所需的结果数，db需要已加载ASN数据库
+func NewASNBlocklist(db *geo.Geo, threshold int) *ASNBlocklist {
+	return &ASNBlocklist{
+		db:        db,
+		threshold: threshold,
+		stats:     make(map[uint]*ASNStats),
+	}
+}
+
+// Filter 转发hosts中的目标，所在ASN已判定为低产的IP目标按Defer跳过或延后，ctx取消时提前结束
+// 延后的目标在hosts关闭后按原顺序转发
+func (b *ASNBlocklist) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		var later []Host
+		for host := range hosts {
+			if host.Type == HostTypeIP && b.blocked(host.IP) {
+				if b.Defer && len(later) < maxDeferredHosts {
+					later = append(later, host)
+					b.count(&b.deferred)
+					continue
+				}
+				b.count(&b.skipped)
+				if b.OnSkip != nil {
+					b.OnSkip(host)
+				}
+				continue
+			}
+			if !sendHost(ctx, out, host) {
+				return
+			}
+		}
+
+		for _, host := range later {
+			if !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// blocked 判断地址所在ASN是否已判定为低产，查不到ASN的地址不屏蔽
+func (b *ASNBlocklist) blocked(ip netip.Addr) bool {
+	asn := b.db.Lookup(ip).ASN
+	if asn == 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.isBlocked(b.stats[asn])
+}
+
+// isBlocked 判断ASN统计是否达到低产条件，调用方需持有锁
+func (b *ASNBlocklist) isBlocked(stats *ASNStats) bool {
+	return stats != nil && stats.Feasible == 0 && stats.Results >= b.threshold
+}
+
+// count 在锁内递增计数
+func (b *ASNBlocklist) count(n *int) {
+	b.mu.Lock()
+	*n++
+	b.mu.Unlock()
+}
+
+// Watch 转发扫描结果并统计各ASN的结果，返回的通道在results关闭后关闭
+func (b *ASNBlocklist) Watch(results <-chan Result) <-chan Result {
+	out := make(chan Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			b.Observe(result)
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Observe 统计单个结果，没有ASN信息的结果忽略
+func (b *ASNBlocklist) Observe(result Result) {
+	if result.ASN == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats[result.ASN]
+	if stats == nil {
+		stats = &ASNStats{ASN: result.ASN, Org: result.ASOrg}
+		b.stats[result.ASN] = stats
+	}
+	wasBlocked := b.isBlocked(stats)
+
+	stats.Results++
+	if result.Error != "" {
+		stats.Failures++
+	}
+	if isCDNResult(result) {
+		stats.CDN++
+	}
+	if result.Feasible {
+		stats.Feasible++
+	}
+
+	switch blocked := b.isBlocked(stats); {
+	case blocked && !wasBlocked:
+		logger.Debug("ASN没有符合条件的目标，降低优先级", "asn", stats.ASN, "org", stats.Org,
+			"results", stats.Results, "failures", stats.Failures, "cdn", stats.CDN)
+	case !blocked && wasBlocked:
+		logger.Debug("ASN发现符合条件的目标，恢复扫描", "asn", stats.ASN, "org", stats.Org)
+	}
+}
+
+// isCDNResult 判断结果是否属于CDN网络
+func isCDNResult(result Result) bool {
+	text := strings.ToLower(result.ASOrg + " " + result.CertDomain)
+	for _, marker := range cdnMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Blocked 返回当前判定为低产的ASN统计，按结果数从多到少排序
+func (b *ASNBlocklist) Blocked() []ASNStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var blocked []ASNStats
+	for _, stats := range b.stats {
+		if b.isBlocked(stats) {
+			blocked = append(blocked, *stats)
+		}
+	}
+	slices.SortFunc(blocked, func(x, y ASNStats) int {
+		return cmp.Or(cmp.Compare(y.Results, x.Results), cmp.Compare(x.ASN, y.ASN))
+	})
+	return blocked
+}
+
+// Skipped 返回已跳过和已延后的目标数
+func (b *ASNBlocklist) Skipped() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.skipped, b.deferred
+}
@@ -0,0 +1,296 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capture 握手抓包：连接指定目标时记录TCP连接上收发的全部数据，握手结束后在Dir中为每次握手写入
+// 可用Wireshark打开的pcap文件（TCP头部为按收发顺序合成的，不是真实的报文），以及包含明文的
+// ClientHello/ServerHello十六进制转储和握手结果的文本文件，用于诊断目标被判断为某种结果的原因
+type Capture struct {
+	Dir     string       // 抓包文件目录
+	Targets []netip.Addr // 需要抓包的IP
+}
+
+// Matches 返回是否需要对IP抓包，c为nil时返回false
+func (c *Capture) Matches(ip netip.Addr) bool {
+	return c != nil && slices.Contains(c.Targets, ip.Unmap())
+}
+
+// captureSegment 连接上一次读或写的数据
+type captureSegment struct {
+	time     time.Time
+	outbound bool // 由本机发出
+	data     []byte
+}
+
+// captureConn 记录收发数据的连接
+type captureConn struct {
+	net.Conn
+	start time.Time
+
+	mu       sync.Mutex
+	segments []captureSegment
+}
+
+func newCaptureConn(conn net.Conn, start time.Time) *captureConn {
+	return &captureConn{Conn: conn, start: start}
+}
+
+func (c *captureConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.record(false, b[:n])
+	return n, err
+}
+
+func (c *captureConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.record(true, b[:n])
+	return n, err
+}
+
+func (c *captureConn) record(outbound bool, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.segments = append(c.segments, captureSegment{time: time.Now(), outbound: outbound, data: slices.Clone(data)})
+}
+
+// save 将连接上记录的数据写入抓包文件和文本文件
+func (c *Capture) save(conn *captureConn, ip netip.Addr, result Result) {
+	conn.mu.Lock()
+	segments := slices.Clone(conn.segments)
+	conn.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		logger.Warn("保存握手抓包失败", "error", err)
+		return
+	}
+	name := strings.ReplaceAll(result.IP, ":", "-") + "_" + fmt.Sprint(result.Port)
+	if result.SNI != "" {
+		name += "_" + result.SNI
+	}
+	base := filepath.Join(c.Dir, name+"_"+conn.start.Format("20060102-150405.000"))
+
+	remote := netip.AddrPortFrom(ip.Unmap(), uint16(result.Port))
+	local, err := netip.ParseAddrPort(conn.LocalAddr().String())
+	if err != nil || local.Addr().Is4() != remote.Addr().Is4() {
+		// 经代理拨号等情况下没有本地TCP地址，使用占位地址
+		local = netip.AddrPortFrom(netip.IPv6Unspecified(), 50000)
+		if remote.Addr().Is4() {
+			local = netip.AddrPortFrom(netip.IPv4Unspecified(), 50000)
+		}
+	}
+
+	if err := writePcap(base+".pcap", local, remote, conn.start, segments); err != nil {
+		logger.Warn("保存握手抓包失败", "error", err)
+		return
+	}
+	if err := writeHelloDump(base+".txt", result, segments); err != nil {
+		logger.Warn("保存握手抓包失败", "error", err)
+		return
+	}
+	logger.Info("已保存握手抓包", "ip", result.IP, "port", result.Port, "pcap", base+".pcap", "hello", base+".txt")
+}
+
+// writeHelloDump 写入握手结果，以及双方明文握手记录（ClientHello、ServerHello、HelloRetryRequest等）的十六进制转储
+func writeHelloDump(filename string, result Result, segments []captureSegment) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建抓包文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "目标: %s\n", net.JoinHostPort(result.IP, fmt.Sprint(result.Port)))
+	fmt.Fprintf(w, "SNI: %s\n", result.SNI)
+	fmt.Fprintf(w, "TLS版本: %s\nALPN: %s\n椭圆曲线: %s\n", result.TLSVersion, result.ALPN, result.Curve)
+	fmt.Fprintf(w, "证书域名: %s\n证书颁发者: %s\n证书指纹: %s\n", result.CertDomain, result.CertIssuer, result.CertSHA256)
+	fmt.Fprintf(w, "响应时间: %dms\n", result.ResponseTime)
+	if result.Error != "" {
+		fmt.Fprintf(w, "错误类别: %s\n错误: %s\n", result.ErrorKind, result.Error)
+	}
+
+	for _, direction := range []struct {
+		outbound bool
+		title    string
+	}{{true, "客户端 -> 服务器"}, {false, "服务器 -> 客户端"}} {
+		var stream []byte
+		for _, segment := range segments {
+			if segment.outbound == direction.outbound {
+				stream = append(stream, segment.data...)
+			}
+		}
+		records := plaintextHandshake(stream)
+		fmt.Fprintf(w, "\n=== %s：共 %d 字节，明文握手记录 %d 字节 ===\n", direction.title, len(stream), len(records))
+		if len(records) == 0 && len(stream) > 0 {
+			// 不是TLS握手记录（如HTTP响应或其他协议），转储开头部分
+			records = stream[:min(len(stream), 512)]
+		}
+		w.WriteString(hex.Dump(records))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入抓包文件失败: %v", err)
+	}
+	return nil
+}
+
+// plaintextHandshake 返回数据流开头连续的TLS握手记录（内容类型22），遇到其他类型的记录时停止；
+// TLS 1.3中之后的握手消息是加密的，因此只包含Hello消息
+func plaintextHandshake(stream []byte) []byte {
+	end := 0
+	for len(stream)-end >= 5 && stream[end] == 22 {
+		length := int(binary.BigEndian.Uint16(stream[end+3 : end+5]))
+		if len(stream)-end-5 < length {
+			break
+		}
+		end += 5 + length
+	}
+	return stream[:end]
+}
+
+// pcap文件格式的常量
+const (
+	pcapMagic      = 0xa1b2c3d4
+	pcapLinkRawIP  = 101 // LINKTYPE_RAW：报文以IPv4或IPv6头部开始
+	pcapSnapLen    = 65535
+	pcapMaxPayload = 32768 // 合成TCP报文的最大负载
+)
+
+// writePcap 按收发顺序合成TCP三次握手和数据报文，写入pcap文件
+func writePcap(filename string, local, remote netip.AddrPort, start time.Time, segments []captureSegment) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建抓包文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:], pcapLinkRawIP)
+	w.Write(header)
+
+	const (
+		flagSYN = 0x02
+		flagPSH = 0x08
+		flagACK = 0x10
+	)
+	// 序列号从固定值开始，SYN占用一个序列号
+	clientSeq, serverSeq := uint32(1000), uint32(5000)
+	packet := func(at time.Time, outbound bool, flags byte, payload []byte) {
+		src, dst, seq, ack := local, remote, clientSeq, serverSeq
+		if !outbound {
+			src, dst, seq, ack = remote, local, serverSeq, clientSeq
+		}
+		data := synthesizePacket(src, dst, seq, ack, flags, payload)
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record[0:], uint32(at.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(at.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(data)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(data)))
+		w.Write(record)
+		w.Write(data)
+	}
+
+	packet(start, true, flagSYN, nil)
+	clientSeq++
+	packet(start, false, flagSYN|flagACK, nil)
+	serverSeq++
+	packet(start, true, flagACK, nil)
+	for _, segment := range segments {
+		for data := segment.data; len(data) > 0; {
+			chunk := data[:min(len(data), pcapMaxPayload)]
+			data = data[len(chunk):]
+			packet(segment.time, segment.outbound, flagPSH|flagACK, chunk)
+			if segment.outbound {
+				clientSeq += uint32(len(chunk))
+			} else {
+				serverSeq += uint32(len(chunk))
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入抓包文件失败: %v", err)
+	}
+	return nil
+}
+
+// synthesizePacket 合成带IP头部和TCP头部的报文
+func synthesizePacket(src, dst netip.AddrPort, seq, ack uint32, flags byte, payload []byte) []byte {
+	tcp := make([]byte, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:], src.Port())
+	binary.BigEndian.PutUint16(tcp[2:], dst.Port())
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	binary.BigEndian.PutUint32(tcp[8:], ack)
+	tcp[12] = 5 << 4 // 头部长度20字节
+	tcp[13] = flags
+	binary.BigEndian.PutUint16(tcp[14:], 65535) // 窗口
+	copy(tcp[20:], payload)
+
+	srcIP, dstIP := src.Addr().AsSlice(), dst.Addr().AsSlice()
+	var ip []byte
+	if src.Addr().Is4() {
+		ip = make([]byte, 20)
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+		binary.BigEndian.PutUint16(ip[6:], 0x4000) // 不分片
+		ip[8] = 64
+		ip[9] = 6 // TCP
+		copy(ip[12:], srcIP)
+		copy(ip[16:], dstIP)
+		binary.BigEndian.PutUint16(ip[10:], checksum(ip, 0))
+	} else {
+		ip = make([]byte, 40)
+		ip[0] = 0x60
+		binary.BigEndian.PutUint16(ip[4:], uint16(len(tcp)))
+		ip[6] = 6 // TCP
+		ip[7] = 64
+		copy(ip[8:], srcIP)
+		copy(ip[24:], dstIP)
+	}
+
+	// TCP校验和包括源、目的地址组成的伪头部
+	var pseudo uint32
+	for _, addr := range [][]byte{srcIP, dstIP} {
+		for i := 0; i < len(addr); i += 2 {
+			pseudo += uint32(binary.BigEndian.Uint16(addr[i:]))
+		}
+	}
+	pseudo += 6 + uint32(len(tcp))
+	binary.BigEndian.PutUint16(tcp[16:], checksum(tcp, pseudo))
+	return append(ip, tcp...)
+}
+
+// checksum 计算互联网校验和，initial为已累加的伪头部
+func checksum(data []byte, initial uint32) uint16 {
+	sum := initial
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
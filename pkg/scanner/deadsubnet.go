@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+)
+
+// DeadSubnets 跳过无响应的网段：同一/24（IPv6为/120）中连续出现指定次数的无响应失败（连接超时、不可达）后，
+// 该网段剩余的地址不再扫描，减少大范围扫描时在空地址段上浪费的时间
+type DeadSubnets struct {
+	threshold int
+
+	// OnSkip 目标因所在网段无响应被跳过时调用（在目标迭代协程中），可用于修正进度统计
+	OnSkip func(Host)
+
+	mu       sync.Mutex
+	failures map[netip.Prefix]int // 网段中连续的无响应失败次数
+	dead     map[netip.Prefix]bool
+	skipped  int
+}
+
+// NewDeadSubnets 创建无响应网段跳过器，threshold为判定网段无响应所需的连续失败次数
+func NewDeadSubnets(threshold int) *DeadSubnets {
+	return &DeadSubnets{
+		threshold: threshold,
+		failures:  make(map[netip.Prefix]int),
+		dead:      make(map[netip.Prefix]bool),
+	}
+}
+
+// subnet 返回地址所在的/24（IPv6为/120）网段
+func subnet(ip netip.Addr) netip.Prefix {
+	bits := 24
+	if ip.Is6() {
+		bits = 120
+	}
+	prefix, _ := ip.Prefix(bits)
+	return prefix
+}
+
+// isHardFailure 判断错误是否表示对端没有任何响应
+func isHardFailure(kind ErrorKind) bool {
+	return kind == ErrorDialTimeout || kind == ErrorUnreachable
+}
+
+// Filter 转发hosts中的目标，跳过所在网段已判定为无响应的IP目标，ctx取消时提前结束
+func (d *DeadSubnets) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		for host := range hosts {
+			if host.Type == HostTypeIP && d.isDead(host.IP) {
+				if d.OnSkip != nil {
+					d.OnSkip(host)
+				}
+				continue
+			}
+			if !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// isDead 判断地址所在网段是否已判定为无响应，是则计入跳过数
+func (d *DeadSubnets) isDead(ip netip.Addr) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dead[subnet(ip)] {
+		d.skipped++
+		return true
+	}
+	return false
+}
+
+// Watch 转发扫描结果并统计各网段的连续无响应失败，返回的通道在results关闭后关闭
+func (d *DeadSubnets) Watch(results <-chan Result) <-chan Result {
+	out := make(chan Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			d.Observe(result)
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Observe 统计单个结果：无响应失败累计所在网段的连续失败次数，其他结果（包括连接被拒绝）说明网段中有存活主机，清零计数
+func (d *DeadSubnets) Observe(result Result) {
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		return
+	}
+	prefix := subnet(ip)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dead[prefix] {
+		return
+	}
+	if !isHardFailure(result.ErrorKind) {
+		delete(d.failures, prefix)
+		return
+	}
+
+	d.failures[prefix]++
+	if d.failures[prefix] >= d.threshold {
+		delete(d.failures, prefix)
+		d.dead[prefix] = true
+		logger.Debug("网段无响应，跳过剩余地址", "subnet", prefix, "failures", d.threshold)
+	}
+}
+
+// Skipped 返回已跳过的目标数和判定为无响应的网段数
+func (d *DeadSubnets) Skipped() (int, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.skipped, len(d.dead)
+}
@@ -0,0 +1,13 @@
+// Package scanner 实现Reality目标扫描的核心逻辑：解析扫描目标（IP、CIDR、域名），
+// 生成待扫描主机序列，并发执行TLS握手并提取TLS版本、ALPN、证书等信息。
+//
+// 基本用法:
+//
+//	cfg := scanner.DefaultConfig()
+//	cfg.Judge = (&feasibility.Checker{}).Feasible
+//	s := scanner.New(cfg, nil)
+//	hosts := scanner.IterateCIDR(ctx, "1.2.3.0/24")
+//	for result := range s.ScanWithConcurrency(ctx, hosts) {
+//		fmt.Println(result.IP, result.CertDomain, result.Feasible)
+//	}
+package scanner
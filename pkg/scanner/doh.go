@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dohMaxResponse DoH响应的最大长度
+const dohMaxResponse = 64 << 10
+
+// DoHResolver 通过DNS over HTTPS（RFC 8484）解析域名，用于从指定地区的解析器取得GeoDNS的应答
+// ECS有效时在查询中附带EDNS客户端子网，公共解析器按该子网而不是本机地址选择应答
+type DoHResolver struct {
+	URL    string       // DoH地址，如 https://dns.google/dns-query
+	ECS    netip.Prefix // EDNS客户端子网，无效时不附带
+	Client HTTPClient   // 为nil时使用http.DefaultClient
+}
+
+// RegionResolver 某个地区的域名解析器
+type RegionResolver struct {
+	Region   string
+	Resolver Resolver
+}
+
+// ParseRegionResolver 解析地区解析器参数，格式为 地区=DoH地址[,ECS子网]，如 jp=https://dns.google/dns-query,203.0.113.0/24
+func ParseRegionResolver(spec string, client HTTPClient) (RegionResolver, error) {
+	region, rest, ok := strings.Cut(spec, "=")
+	if !ok || region == "" || rest == "" {
+		return RegionResolver{}, fmt.Errorf("无效的地区解析器: %s（格式为 地区=DoH地址[,ECS子网]）", spec)
+	}
+	url, subnet, hasSubnet := strings.Cut(rest, ",")
+	if !strings.HasPrefix(url, "https://") {
+		return RegionResolver{}, fmt.Errorf("无效的DoH地址: %s", url)
+	}
+	resolver := &DoHResolver{URL: url, Client: client}
+	if hasSubnet {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return RegionResolver{}, fmt.Errorf("无效的ECS子网: %s", subnet)
+		}
+		resolver.ECS = prefix.Masked()
+	}
+	return RegionResolver{Region: region, Resolver: resolver}, nil
+}
+
+// LookupNetIP 查询A和AAAA记录，network为 ip4 或 ip6 时只查询对应类型
+func (r *DoHResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	var types []dnsmessage.Type
+	if network != "ip6" {
+		types = append(types, dnsmessage.TypeA)
+	}
+	if network != "ip4" {
+		types = append(types, dnsmessage.TypeAAAA)
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		addrs []netip.Addr
+		errs  []error
+	)
+	for _, qtype := range types {
+		wg.Add(1)
+		go func(qtype dnsmessage.Type) {
+			defer wg.Done()
+			found, err := r.query(ctx, host, qtype)
+			mu.Lock()
+			defer mu.Unlock()
+			addrs = append(addrs, found...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(qtype)
+	}
+	wg.Wait()
+
+	if len(addrs) == 0 {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, fmt.Errorf("%s 没有地址记录", host)
+	}
+	return addrs, nil
+}
+
+// LookupAddr 不支持反向解析，DoH解析器只用于域名目标
+func (r *DoHResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return nil, fmt.Errorf("DoH解析器不支持反向解析")
+}
+
+// query 发送一次DoH查询，返回应答中的地址
+func (r *DoHResolver) query(ctx context.Context, host string, qtype dnsmessage.Type) ([]netip.Addr, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("无效的域名: %v", err)
+	}
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	if r.ECS.IsValid() {
+		msg.Additionals = append(msg.Additionals, r.ecsOption())
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("构造DNS查询失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("DoH查询失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH查询失败: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dohMaxResponse))
+	if err != nil {
+		return nil, fmt.Errorf("读取DoH响应失败: %v", err)
+	}
+
+	var answer dnsmessage.Message
+	if err := answer.Unpack(body); err != nil {
+		return nil, fmt.Errorf("解析DoH响应失败: %v", err)
+	}
+	if answer.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("DoH查询失败: %s", answer.RCode)
+	}
+
+	// CNAME链由解析器展开，只取地址记录
+	var addrs []netip.Addr
+	for _, rr := range answer.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			addrs = append(addrs, netip.AddrFrom4(body.A))
+		case *dnsmessage.AAAAResource:
+			addrs = append(addrs, netip.AddrFrom16(body.AAAA))
+		}
+	}
+	return addrs, nil
+}
+
+// ecsOption 返回携带EDNS客户端子网（RFC 7871）的OPT记录
+func (r *DoHResolver) ecsOption() dnsmessage.Resource {
+	family, addr := uint16(1), r.ECS.Addr().AsSlice()
+	if r.ECS.Addr().Is6() {
+		family = 2
+	}
+	bits := r.ECS.Bits()
+	data := []byte{byte(family >> 8), byte(family), byte(bits), 0}
+	data = append(data, addr[:(bits+7)/8]...)
+
+	var opt dnsmessage.Resource
+	opt.Header.Name = dnsmessage.MustNewName(".")
+	opt.Header.Type = dnsmessage.TypeOPT
+	opt.Header.Class = dnsmessage.Class(4096) // UDP负载大小
+	opt.Body = &dnsmessage.OPTResource{Options: []dnsmessage.Option{{Code: 8, Data: data}}}
+	return opt
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// ErrorKind 扫描失败的类别，用于统计和按类别筛选失败结果
+type ErrorKind string
+
+const (
+	ErrorNone            ErrorKind = ""                 // 没有错误
+	ErrorDNS             ErrorKind = "dns-fail"         // 域名解析失败
+	ErrorUnsupportedHost ErrorKind = "unsupported-host" // 不支持的主机类型
+	ErrorDialTimeout     ErrorKind = "dial-timeout"     // TCP连接超时
+	ErrorRefused         ErrorKind = "refused"          // 连接被拒绝
+	ErrorReset           ErrorKind = "reset"            // 连接被重置
+	ErrorUnreachable     ErrorKind = "unreachable"      // 网络或主机不可达
+	ErrorDial            ErrorKind = "dial-other"       // 其他TCP连接错误
+	ErrorTLSTimeout      ErrorKind = "tls-timeout"      // TLS握手超时
+	ErrorTLSAlert        ErrorKind = "tls-alert"        // 对端发送了TLS告警
+	ErrorTLSEOF          ErrorKind = "tls-eof"          // 握手过程中连接被关闭
+	ErrorNotTLS          ErrorKind = "not-tls"          // 对端不是TLS服务
+	ErrorTLS             ErrorKind = "tls-other"        // 其他TLS握手错误
+	ErrorCertMissing     ErrorKind = "cert-missing"     // 对端未提供证书
+	ErrorCanceled        ErrorKind = "canceled"         // 扫描被取消
+	ErrorGeoMismatch     ErrorKind = "geo-mismatch"     // 地理位置与目标期望的不符
+	ErrorInterference    ErrorKind = "interference"     // 疑似中间设备干扰，具体类型见Result.Middlebox
+)
+
+// classifyDialError 判断TCP连接错误的类别
+func classifyDialError(err error) ErrorKind {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case isTimeout(err):
+		return ErrorDialTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorReset
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return ErrorUnreachable
+	default:
+		return ErrorDial
+	}
+}
+
+// classifyTLSError 判断TLS握手错误的类别
+func classifyTLSError(err error) ErrorKind {
+	var opErr *net.OpError
+	var headerErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case isTimeout(err):
+		return ErrorTLSTimeout
+	case errors.As(err, &opErr) && opErr.Op == "remote error":
+		// crypto/tls将收到的告警包装为Op为"remote error"的net.OpError
+		return ErrorTLSAlert
+	case errors.As(err, &headerErr):
+		return ErrorNotTLS
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorTLSEOF
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorReset
+	default:
+		return ErrorTLS
+	}
+}
+
+// isTimeout 判断错误是否由超时引起
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
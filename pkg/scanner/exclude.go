@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// Exclusions 排除名单：不扫描其中的地址，如要求不被扫描（opt-out）的网络
+// IP目标在握手前跳过，域名解析得到的地址在扫描时跳过
+type Exclusions struct {
+	Prefixes []netip.Prefix
+
+	// OnSkip IP目标因在排除名单中被跳过时调用，可用于修正进度统计
+	OnSkip func(Host)
+}
+
+// ParseExclusions 读取排除名单，每行一个IP或CIDR，#之后为注释
+func ParseExclusions(r io.Reader) (*Exclusions, error) {
+	e := &Exclusions{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(text)
+			if addrErr != nil {
+				return nil, fmt.Errorf("排除名单第 %d 行无效: %s", line, text)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		e.Prefixes = append(e.Prefixes, prefix.Masked())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取排除名单失败: %v", err)
+	}
+	return e, nil
+}
+
+// Contains 返回地址是否在排除名单中，e为nil时返回false
+func (e *Exclusions) Contains(ip netip.Addr) bool {
+	if e == nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range e.Prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter 转发不在排除名单中的目标，返回的通道在hosts关闭或ctx取消后关闭
+func (e *Exclusions) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+	go func() {
+		defer close(out)
+		for host := range hosts {
+			if host.Type == HostTypeIP && e.Contains(host.IP) {
+				logger.Debug("目标在排除名单中，跳过", "ip", host.IP)
+				if e.OnSkip != nil {
+					e.OnSkip(host)
+				}
+				continue
+			}
+			if !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+	return out
+}
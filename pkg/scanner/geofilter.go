@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+)
+
+// ParseCountries 解析逗号分隔的国家代码列表，如 JP,KR,US，返回去重后的大写代码
+func ParseCountries(spec string) ([]string, error) {
+	var countries []string
+	for _, part := range strings.Split(spec, ",") {
+		code := strings.ToUpper(strings.TrimSpace(part))
+		if code == "" {
+			continue
+		}
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("无效的国家代码: %s（应为两位字母，如 JP）", part)
+		}
+		if !slices.Contains(countries, code) {
+			countries = append(countries, code)
+		}
+	}
+	if len(countries) == 0 {
+		return nil, fmt.Errorf("国家代码列表为空: %q", spec)
+	}
+	return countries, nil
+}
+
+// GeoFilter 握手前的地理位置预过滤：查询IP目标的国家，跳过不在名单中的地址，
+// 省去对不符合要求的地址建立连接和握手的时间；域名目标在解析前无法判断，原样转发
+type GeoFilter struct {
+	db        *geo.Geo
+	countries []string
+
+	// OnSkip 目标因地理位置被跳过时调用（在目标迭代协程中），可用于修正进度统计
+	OnSkip func(Host)
+}
+
+// NewGeoFilter 创建地理位置预过滤，countries为允许的国家代码（大写）
+func NewGeoFilter(db *geo.Geo, countries []string) *GeoFilter {
+	return &GeoFilter{db: db, countries: countries}
+}
+
+// Allowed 判断IP所在国家是否在名单中，无法确定国家的地址视为不在名单中
+func (f *GeoFilter) Allowed(host Host) bool {
+	if host.Type != HostTypeIP || !host.IP.IsValid() {
+		return true
+	}
+	return slices.Contains(f.countries, f.db.GetGeo(host.IP))
+}
+
+// Filter 转发hosts中地理位置符合名单的目标，ctx取消时提前结束
+func (f *GeoFilter) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		for host := range hosts {
+			if !f.Allowed(host) {
+				if f.OnSkip != nil {
+					f.OnSkip(host)
+				}
+				continue
+			}
+			if !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
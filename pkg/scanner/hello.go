@@ -0,0 +1,549 @@
+package scanner
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 自定义ClientHello探测结果，记录在Result.HelloProfile中；
+// 收到告警时记录为 alert:<告警描述>，如 alert:handshake_failure
+const (
+	HelloOK      = "ok"      // 服务器以TLS 1.3 ServerHello响应
+	HelloHRR     = "hrr"     // 服务器要求以其他椭圆曲线重新发送ClientHello（HelloRetryRequest）
+	HelloTLS12   = "tls1.2"  // 服务器选择了TLS 1.2或更低版本
+	HelloReset   = "reset"   // 连接被重置或关闭
+	HelloTimeout = "timeout" // 超时未收到响应
+	HelloError   = "error"   // 其他错误
+)
+
+// HelloProfile 自定义ClientHello的配置：支持的椭圆曲线及顺序、签名算法、扩展集合及顺序和ALPN，
+// 用于模仿特定客户端（浏览器）的ClientHello，检查目标对真实客户端的响应。
+// crypto/tls不允许控制签名算法和扩展顺序，因此探测时自行构造ClientHello，只读取服务器的第一条响应
+type HelloProfile struct {
+	Name       string                // 配置名称，如 chrome
+	Curves     []tls.CurveID         // 支持的椭圆曲线，按优先顺序；key_share只包含第一个
+	SigAlgs    []tls.SignatureScheme // 签名算法，按优先顺序
+	Extensions []uint16              // 扩展类型，按发送顺序；未知的扩展以空内容发送
+	ALPN       []string              // ALPN协议，按优先顺序
+}
+
+// TLS扩展类型
+const (
+	extServerName       uint16 = 0
+	extStatusRequest    uint16 = 5
+	extSupportedGroups  uint16 = 10
+	extECPointFormats   uint16 = 11
+	extSignatureAlgs    uint16 = 13
+	extALPN             uint16 = 16
+	extSCT              uint16 = 18
+	extPadding          uint16 = 21
+	extExtendedMaster   uint16 = 23
+	extCompressCert     uint16 = 27
+	extRecordSizeLimit  uint16 = 28
+	extDelegatedCreds   uint16 = 34
+	extSessionTicket    uint16 = 35
+	extSupportedVersion uint16 = 43
+	extPSKModes         uint16 = 45
+	extKeyShare         uint16 = 51
+	extRenegotiation    uint16 = 0xff01
+)
+
+// helloExtensionNames 扩展名称，用于解析配置
+var helloExtensionNames = map[string]uint16{
+	"server_name":                  extServerName,
+	"status_request":               extStatusRequest,
+	"supported_groups":             extSupportedGroups,
+	"ec_point_formats":             extECPointFormats,
+	"signature_algorithms":         extSignatureAlgs,
+	"alpn":                         extALPN,
+	"signed_certificate_timestamp": extSCT,
+	"padding":                      extPadding,
+	"extended_master_secret":       extExtendedMaster,
+	"compress_certificate":         extCompressCert,
+	"record_size_limit":            extRecordSizeLimit,
+	"delegated_credentials":        extDelegatedCreds,
+	"session_ticket":               extSessionTicket,
+	"supported_versions":           extSupportedVersion,
+	"psk_key_exchange_modes":       extPSKModes,
+	"key_share":                    extKeyShare,
+	"renegotiation_info":           extRenegotiation,
+}
+
+// helloCurveNames 椭圆曲线名称，只包含可以生成key_share的曲线
+var helloCurveNames = map[string]tls.CurveID{
+	"x25519": tls.X25519,
+	"p256":   tls.CurveP256,
+	"p384":   tls.CurveP384,
+	"p521":   tls.CurveP521,
+}
+
+// helloSigAlgNames 签名算法名称（IANA名称）
+var helloSigAlgNames = map[string]tls.SignatureScheme{
+	"ecdsa_secp256r1_sha256": tls.ECDSAWithP256AndSHA256,
+	"ecdsa_secp384r1_sha384": tls.ECDSAWithP384AndSHA384,
+	"ecdsa_secp521r1_sha512": tls.ECDSAWithP521AndSHA512,
+	"ed25519":                tls.Ed25519,
+	"rsa_pss_rsae_sha256":    tls.PSSWithSHA256,
+	"rsa_pss_rsae_sha384":    tls.PSSWithSHA384,
+	"rsa_pss_rsae_sha512":    tls.PSSWithSHA512,
+	"rsa_pkcs1_sha256":       tls.PKCS1WithSHA256,
+	"rsa_pkcs1_sha384":       tls.PKCS1WithSHA384,
+	"rsa_pkcs1_sha512":       tls.PKCS1WithSHA512,
+	"rsa_pkcs1_sha1":         tls.PKCS1WithSHA1,
+	"ecdsa_sha1":             tls.ECDSAWithSHA1,
+}
+
+// helloPresets 内置的客户端配置，取自各浏览器的ClientHello（省略GREASE，Chrome的扩展顺序固定为一种排列）
+var helloPresets = map[string]HelloProfile{
+	"chrome": {
+		Curves: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		SigAlgs: []tls.SignatureScheme{
+			tls.ECDSAWithP256AndSHA256, tls.PSSWithSHA256, tls.PKCS1WithSHA256, tls.ECDSAWithP384AndSHA384,
+			tls.PSSWithSHA384, tls.PKCS1WithSHA384, tls.PSSWithSHA512, tls.PKCS1WithSHA512,
+		},
+		Extensions: []uint16{
+			extServerName, extExtendedMaster, extRenegotiation, extSupportedGroups, extECPointFormats,
+			extSessionTicket, extALPN, extStatusRequest, extSignatureAlgs, extSCT, extKeyShare,
+			extPSKModes, extSupportedVersion, extCompressCert, extPadding,
+		},
+		ALPN: []string{"h2", "http/1.1"},
+	},
+	"firefox": {
+		Curves: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521},
+		SigAlgs: []tls.SignatureScheme{
+			tls.ECDSAWithP256AndSHA256, tls.ECDSAWithP384AndSHA384, tls.ECDSAWithP521AndSHA512, tls.PSSWithSHA256,
+			tls.PSSWithSHA384, tls.PSSWithSHA512, tls.PKCS1WithSHA256, tls.PKCS1WithSHA384, tls.PKCS1WithSHA512,
+			tls.ECDSAWithSHA1, tls.PKCS1WithSHA1,
+		},
+		Extensions: []uint16{
+			extServerName, extExtendedMaster, extRenegotiation, extSupportedGroups, extECPointFormats,
+			extSessionTicket, extALPN, extStatusRequest, extDelegatedCreds, extKeyShare, extSupportedVersion,
+			extSignatureAlgs, extPSKModes, extRecordSizeLimit, extPadding,
+		},
+		ALPN: []string{"h2", "http/1.1"},
+	},
+	"safari": {
+		Curves: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521},
+		SigAlgs: []tls.SignatureScheme{
+			tls.ECDSAWithP256AndSHA256, tls.PSSWithSHA256, tls.PKCS1WithSHA256, tls.ECDSAWithP384AndSHA384,
+			tls.ECDSAWithSHA1, tls.PSSWithSHA384, tls.PKCS1WithSHA384, tls.PSSWithSHA512, tls.PKCS1WithSHA512,
+			tls.PKCS1WithSHA1,
+		},
+		Extensions: []uint16{
+			extServerName, extExtendedMaster, extRenegotiation, extSupportedGroups, extECPointFormats,
+			extALPN, extStatusRequest, extSignatureAlgs, extSCT, extKeyShare, extPSKModes,
+			extSupportedVersion, extCompressCert, extPadding,
+		},
+		ALPN: []string{"h2", "http/1.1"},
+	},
+}
+
+// HelloPresets 返回内置客户端配置的名称
+func HelloPresets() []string {
+	names := make([]string, 0, len(helloPresets))
+	for name := range helloPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseHelloProfile 解析ClientHello配置，格式为 [预设][;键=值...]，以分号分隔，键包括：
+// curves=x25519,p256（第一个用于key_share）、sigalgs=ecdsa_secp256r1_sha256,...、
+// ext=server_name,key_share,...（扩展名称或类型编号，按发送顺序）、alpn=h2,http/1.1（none表示不发送）。
+// 未指定预设时以chrome为基础，如 "firefox"、"chrome;curves=p256,x25519"
+func ParseHelloProfile(spec string) (*HelloProfile, error) {
+	fields := strings.Split(spec, ";")
+	name := "chrome"
+	if first := strings.TrimSpace(fields[0]); first != "" && !strings.Contains(first, "=") {
+		name = strings.ToLower(first)
+		fields = fields[1:]
+	}
+	preset, ok := helloPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的ClientHello预设: %s（可选 %s）", name, strings.Join(HelloPresets(), "、"))
+	}
+	profile := preset
+	profile.Name = name
+
+	custom := false
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的ClientHello配置: %s（应为 键=值）", field)
+		}
+		custom = true
+		items := splitList(value)
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "curves":
+			profile.Curves, err = parseNames(items, helloCurveNames, "椭圆曲线")
+			if err == nil && len(profile.Curves) == 0 {
+				err = errors.New("至少需要一个椭圆曲线")
+			}
+		case "sigalgs":
+			profile.SigAlgs, err = parseNames(items, helloSigAlgNames, "签名算法")
+		case "ext":
+			profile.Extensions, err = parseExtensions(items)
+		case "alpn":
+			profile.ALPN = items
+			if len(items) == 1 && items[0] == "none" {
+				profile.ALPN = nil
+			}
+		default:
+			err = fmt.Errorf("未知的ClientHello配置项: %s（可选 curves、sigalgs、ext、alpn）", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if custom {
+		profile.Name += "+custom"
+	}
+	return &profile, nil
+}
+
+// splitList 拆分逗号分隔的列表，去除空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseNames 按名称表解析列表，不区分大小写
+func parseNames[T any](items []string, names map[string]T, kind string) ([]T, error) {
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		value, ok := names[strings.ToLower(item)]
+		if !ok {
+			return nil, fmt.Errorf("未知的%s: %s", kind, item)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// parseExtensions 解析扩展列表，每项为扩展名称或类型编号，不允许重复
+func parseExtensions(items []string) ([]uint16, error) {
+	var extensions []uint16
+	for _, item := range items {
+		ext, ok := helloExtensionNames[strings.ToLower(item)]
+		if !ok {
+			n, err := strconv.ParseUint(item, 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("未知的扩展: %s", item)
+			}
+			ext = uint16(n)
+		}
+		if slices.Contains(extensions, ext) {
+			return nil, fmt.Errorf("重复的扩展: %s", item)
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions, nil
+}
+
+// helloCipherSuites ClientHello中的密码套件：TLS 1.3的三个套件和常见的TLS 1.2 ECDHE套件
+var helloCipherSuites = []uint16{
+	tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384, tls.TLS_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+}
+
+// helloPaddedLen 带padding扩展时ClientHello握手消息至少填充到的长度（与BoringSSL相同），
+// 避开部分负载均衡器无法处理的256-511字节长度
+const helloPaddedLen = 512
+
+// keyShareCurve 返回椭圆曲线对应的ecdh曲线
+func keyShareCurve(id tls.CurveID) (ecdh.Curve, error) {
+	switch id {
+	case tls.X25519:
+		return ecdh.X25519(), nil
+	case tls.CurveP256:
+		return ecdh.P256(), nil
+	case tls.CurveP384:
+		return ecdh.P384(), nil
+	case tls.CurveP521:
+		return ecdh.P521(), nil
+	}
+	return nil, fmt.Errorf("不支持的椭圆曲线: %v", id)
+}
+
+// buildClientHello 按配置构造包含ClientHello的TLS记录
+func (p *HelloProfile) buildClientHello(sni string) ([]byte, error) {
+	random := make([]byte, 64)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	var keyShare []byte
+	if len(p.Curves) > 0 {
+		curve, err := keyShareCurve(p.Curves[0])
+		if err != nil {
+			return nil, err
+		}
+		key, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		keyShare = key.PublicKey().Bytes()
+	}
+
+	// 各扩展的内容，padding在最后计算
+	extensionData := func(ext uint16) []byte {
+		switch ext {
+		case extServerName:
+			entry := appendVector16([]byte{0}, []byte(sni)) // host_name
+			return appendVector16(nil, entry)
+		case extStatusRequest:
+			return []byte{1, 0, 0, 0, 0} // ocsp，无responder_id和扩展
+		case extSupportedGroups:
+			var groups []byte
+			for _, curve := range p.Curves {
+				groups = binary.BigEndian.AppendUint16(groups, uint16(curve))
+			}
+			return appendVector16(nil, groups)
+		case extECPointFormats:
+			return []byte{1, 0} // uncompressed
+		case extSignatureAlgs, extDelegatedCreds:
+			var algs []byte
+			for _, alg := range p.SigAlgs {
+				algs = binary.BigEndian.AppendUint16(algs, uint16(alg))
+			}
+			return appendVector16(nil, algs)
+		case extALPN:
+			var protos []byte
+			for _, proto := range p.ALPN {
+				protos = appendVector8(protos, []byte(proto))
+			}
+			return appendVector16(nil, protos)
+		case extCompressCert:
+			return []byte{2, 0, 2} // brotli
+		case extRecordSizeLimit:
+			return []byte{0x40, 0x01}
+		case extRenegotiation:
+			return []byte{0}
+		case extSupportedVersion:
+			return []byte{4, 0x03, 0x04, 0x03, 0x03}
+		case extPSKModes:
+			return []byte{1, 1} // psk_dhe_ke
+		case extKeyShare:
+			if keyShare == nil {
+				return appendVector16(nil, nil)
+			}
+			entry := binary.BigEndian.AppendUint16(nil, uint16(p.Curves[0]))
+			entry = appendVector16(entry, keyShare)
+			return appendVector16(nil, entry)
+		}
+		return nil
+	}
+
+	var extensions []byte
+	padding := false
+	for _, ext := range p.Extensions {
+		// 服务器名称为空（IP目标）时不发送server_name，没有ALPN协议时不发送alpn
+		if ext == extServerName && sni == "" || ext == extALPN && len(p.ALPN) == 0 {
+			continue
+		}
+		if ext == extPadding {
+			padding = true
+			continue
+		}
+		extensions = binary.BigEndian.AppendUint16(extensions, ext)
+		extensions = appendVector16(extensions, extensionData(ext))
+	}
+
+	body := []byte{0x03, 0x03} // legacy_version: TLS 1.2
+	body = append(body, random[:32]...)
+	body = appendVector8(body, random[32:]) // legacy_session_id，兼容中间设备
+	var suites []byte
+	for _, suite := range helloCipherSuites {
+		suites = binary.BigEndian.AppendUint16(suites, suite)
+	}
+	body = appendVector16(body, suites)
+	body = append(body, 1, 0) // compression_methods: null
+
+	if padding {
+		// 握手消息 = 4字节头部 + body + 2字节扩展长度 + 扩展 + 4字节padding扩展头部 + 填充
+		length := 4 + len(body) + 2 + len(extensions) + 4
+		fill := max(helloPaddedLen-length, 0)
+		extensions = binary.BigEndian.AppendUint16(extensions, extPadding)
+		extensions = appendVector16(extensions, make([]byte, fill))
+	}
+	body = appendVector16(body, extensions)
+
+	message := []byte{1, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))} // client_hello
+	message = append(message, body...)
+	record := []byte{22, 0x03, 0x01} // handshake，记录层版本为TLS 1.0
+	return appendVector16(record, message), nil
+}
+
+// appendVector8 追加以1字节长度为前缀的数据
+func appendVector8(b, data []byte) []byte {
+	return append(append(b, byte(len(data))), data...)
+}
+
+// appendVector16 追加以2字节长度为前缀的数据
+func appendVector16(b, data []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(b, uint16(len(data))), data...)
+}
+
+// helloRetryRandom HelloRetryRequest中固定的random（RFC 8446 4.1.3）
+var helloRetryRandom = []byte{
+	0xcf, 0x21, 0xad, 0x74, 0xe5, 0x9a, 0x61, 0x11, 0xbe, 0x1d, 0x8c, 0x02, 0x1e, 0x65, 0xb8, 0x91,
+	0xc2, 0xa2, 0x11, 0x16, 0x7a, 0xbb, 0x8c, 0x5e, 0x07, 0x9e, 0x09, 0xe2, 0xc8, 0xa8, 0x33, 0x9c,
+}
+
+// ProbeHello 以配置的ClientHello连接目标，返回服务器的第一条响应（HelloOK等常量或 alert:<描述>）；
+// 只读取ServerHello，不完成握手
+func (s *Scanner) ProbeHello(ctx context.Context, ip netip.Addr, port int, sni string) string {
+	profile := s.cfg.HelloProfile
+	if profile == nil || s.pace(ctx, ip) != nil {
+		return HelloError
+	}
+	hello, err := profile.buildClientHello(sni)
+	if err != nil {
+		return HelloError
+	}
+	timeout := time.Duration(s.cfg.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := s.cfg.Dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(ip, uint16(port)).String())
+	if err != nil {
+		return HelloError
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// ctx取消时中止阻塞的读写
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := conn.Write(hello); err != nil {
+		return helloFailure(err)
+	}
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return helloFailure(err)
+	}
+	length := int(binary.BigEndian.Uint16(header[3:5]))
+	if length == 0 || length > 1<<14+256 {
+		return HelloError
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return helloFailure(err)
+	}
+
+	switch header[0] {
+	case 21: // alert
+		if len(payload) < 2 {
+			return HelloError
+		}
+		return "alert:" + alertName(payload[1])
+	case 22: // handshake
+		return parseServerHello(payload)
+	}
+	return HelloError
+}
+
+// helloFailure 将连接错误归类为探测结果
+func helloFailure(err error) string {
+	switch classifyTLSError(err) {
+	case ErrorTLSTimeout:
+		return HelloTimeout
+	case ErrorTLSEOF, ErrorReset:
+		return HelloReset
+	}
+	return HelloError
+}
+
+// parseServerHello 根据ServerHello的random和supported_versions扩展判断服务器的选择
+func parseServerHello(msg []byte) string {
+	// 握手头部(4) + legacy_version(2) + random(32) + session_id长度(1)
+	if len(msg) < 39 || msg[0] != 2 {
+		return HelloError
+	}
+	random := msg[6:38]
+	rest := msg[38:]
+	sessionLen := int(rest[0])
+	// session_id + cipher_suite(2) + compression_method(1)
+	if len(rest) < 1+sessionLen+3 {
+		return HelloError
+	}
+	rest = rest[1+sessionLen+3:]
+
+	version := binary.BigEndian.Uint16(msg[4:6])
+	if len(rest) >= 2 {
+		extensions := rest[2:]
+		for len(extensions) >= 4 {
+			ext := binary.BigEndian.Uint16(extensions[:2])
+			n := int(binary.BigEndian.Uint16(extensions[2:4]))
+			if len(extensions) < 4+n {
+				break
+			}
+			if ext == extSupportedVersion && n == 2 {
+				version = binary.BigEndian.Uint16(extensions[4:6])
+			}
+			extensions = extensions[4+n:]
+		}
+	}
+	switch {
+	case version != tls.VersionTLS13:
+		return HelloTLS12
+	case string(random) == string(helloRetryRandom):
+		return HelloHRR
+	}
+	return HelloOK
+}
+
+// alertNames TLS告警描述（RFC 8446 6）
+var alertNames = map[byte]string{
+	0:   "close_notify",
+	10:  "unexpected_message",
+	20:  "bad_record_mac",
+	22:  "record_overflow",
+	40:  "handshake_failure",
+	42:  "bad_certificate",
+	47:  "illegal_parameter",
+	50:  "decode_error",
+	51:  "decrypt_error",
+	70:  "protocol_version",
+	71:  "insufficient_security",
+	80:  "internal_error",
+	86:  "inappropriate_fallback",
+	109: "missing_extension",
+	110: "unsupported_extension",
+	112: "unrecognized_name",
+	120: "no_application_protocol",
+}
+
+// alertName 返回告警描述的名称，未知的告警返回编号
+func alertName(desc byte) string {
+	if name, ok := alertNames[desc]; ok {
+		return name
+	}
+	return strconv.Itoa(int(desc))
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net/netip"
+	"time"
+)
+
+// Inspection 单次握手的详细信息，用于调试单个目标
+type Inspection struct {
+	Result
+
+	DialTime      time.Duration // TCP连接耗时
+	HandshakeTime time.Duration // TLS握手耗时
+	CipherSuite   string        // 协商的密码套件
+	Chain         []CertSummary // 服务器出示的证书链，叶子证书在前
+}
+
+// CertSummary 证书链中单个证书的摘要
+type CertSummary struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	PublicKey string // 公钥算法
+	Signature string // 签名算法
+	SHA256    string // 指纹（十六进制）
+}
+
+// Inspect 与目标的指定端口完成一次握手并记录详细信息，sni为空时不发送SNI；
+// 只进行握手和地理位置查询，不判断可行性，也不进行SNI验证、MTU探测等附加测试
+func (s *Scanner) Inspect(ctx context.Context, ip netip.Addr, port int, sni string) Inspection {
+	var detail Inspection
+	host := Host{IP: ip, Origin: ip.String(), Type: HostTypeIP, Port: port, SNI: sni}
+	detail.Result = s.inspectHandshake(ctx, ip, port, host, &detail)
+	if s.geoDB != nil {
+		loc := s.geoDB.Lookup(ip)
+		detail.GeoCode = loc.CountryCode
+		detail.City = loc.City
+		detail.ASN = loc.ASN
+		detail.ASOrg = loc.ASOrg
+	}
+	return detail
+}
+
+// record 记录连接状态中的密码套件和证书链
+func (d *Inspection) record(state tls.ConnectionState) {
+	d.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	for _, cert := range state.PeerCertificates {
+		fingerprint := sha256.Sum256(cert.Raw)
+		d.Chain = append(d.Chain, CertSummary{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			PublicKey: cert.PublicKeyAlgorithm.String(),
+			Signature: cert.SignatureAlgorithm.String(),
+			SHA256:    hex.EncodeToString(fingerprint[:]),
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"net/netip"
+	"time"
+)
+
+// 中间设备干扰的类型，记录在Result.Middlebox中
+const (
+	InterferenceReset   = "rst"         // ClientHello发出后连接立即被重置或关闭
+	InterferenceSNI     = "sni"         // 不带SNI握手成功，带SNI握手失败，疑似按SNI阻断
+	InterferenceTimeout = "tls-timeout" // TCP连接正常，TLS握手持续超时
+)
+
+// resetWindow 握手在TCP连接延迟的两倍再加上该时间内失败，视为ClientHello发出后立即被重置
+const resetWindow = 100 * time.Millisecond
+
+// suspectInterference 判断握手错误是否可能由中间设备干扰引起：
+// 连接建立后被重置、关闭或超时；连接超时、被拒绝等错误说明主机本身不可用
+func suspectInterference(kind ErrorKind) bool {
+	return kind == ErrorReset || kind == ErrorTLSEOF || kind == ErrorTLSTimeout
+}
+
+// detectInterference 对握手失败的目标重新探测，判断失败是否呈现中间设备干扰的特征，返回干扰类型，
+// 不符合任何特征（包括重试成功的偶发失败）时返回空字符串
+// 先以相同SNI重试并记录TCP连接和握手各自的耗时，带SNI失败时再不带SNI握手一次
+func (s *Scanner) detectInterference(ctx context.Context, ip netip.Addr, port int, sni string) string {
+	kind, tcpRTT, elapsed := s.probeHandshake(ctx, ip, port, sni)
+	if !suspectInterference(kind) || tcpRTT == 0 {
+		return "" // 重试成功、TCP连接失败或对端明确拒绝
+	}
+
+	// 带SNI失败而不带SNI成功，说明服务器可用、阻断与SNI有关
+	if sni != "" {
+		if plain, _, _ := s.probeHandshake(ctx, ip, port, ""); plain == ErrorNone {
+			return InterferenceSNI
+		}
+	}
+
+	switch kind {
+	case ErrorReset, ErrorTLSEOF:
+		if elapsed <= 2*tcpRTT+resetWindow {
+			return InterferenceReset
+		}
+	case ErrorTLSTimeout:
+		return InterferenceTimeout
+	}
+	return ""
+}
+
+// probeHandshake 完成一次TCP连接和TLS握手，返回错误类别（成功时为ErrorNone）、TCP连接耗时和握手耗时
+// 连接阶段的错误按classifyDialError分类且TCP连接耗时为0，握手阶段的错误按classifyTLSError分类
+func (s *Scanner) probeHandshake(ctx context.Context, ip netip.Addr, port int, sni string) (ErrorKind, time.Duration, time.Duration) {
+	if err := s.pace(ctx, ip); err != nil {
+		return classifyDialError(err), 0, 0
+	}
+	timeout := time.Duration(s.cfg.Timeout) * time.Second
+	dialCtx, dialCancel := context.WithTimeout(ctx, timeout)
+	defer dialCancel()
+	dialStart := time.Now()
+	conn, err := s.cfg.Dialer.DialContext(dialCtx, "tcp", netip.AddrPortFrom(ip, uint16(port)).String())
+	if err != nil {
+		return classifyDialError(err), 0, 0
+	}
+	defer conn.Close()
+	tcpRTT := time.Since(dialStart)
+
+	tlsConfig := s.tlsConfig
+	if sni != "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = sni
+	}
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tlsConn := tls.Client(conn, tlsConfig)
+	defer tlsConn.Close()
+	handshakeStart := time.Now()
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		return classifyTLSError(err), tcpRTT, time.Since(handshakeStart)
+	}
+	return ErrorNone, tcpRTT, time.Since(handshakeStart)
+}
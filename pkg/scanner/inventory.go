@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// ParseHostsFile 解析/etc/hosts格式的文件（"IP 名称1 名称2 # 注释"）
+// 每个名称生成一个以该名称为SNI、直接连接对应IP的目标，回环地址和非域名的名称会被跳过
+func ParseHostsFile(r io.Reader) ([]Host, error) {
+	var hosts []Host
+	seen := make(map[Host]bool)
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := lines.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			logger.Debug("跳过无效的hosts记录", "line", lines.Text())
+			continue
+		}
+		ip = ip.Unmap()
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			continue
+		}
+
+		for _, name := range fields[1:] {
+			name = normalizeName(name)
+			if !ValidateDomainName(name) || !strings.Contains(name, ".") {
+				continue
+			}
+			host := Host{IP: ip, Origin: name, Type: HostTypeIP}
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("读取hosts文件失败: %v", err)
+	}
+
+	return hosts, nil
+}
+
+// ParseZoneFile 解析BIND区域文件，origin为区域名（文件中的$ORIGIN优先）
+// A/AAAA记录生成以记录名为SNI、直接连接记录地址的目标；
+// 只有CNAME等其他记录的名称作为域名目标，扫描时再解析
+func ParseZoneFile(r io.Reader, origin string) ([]Host, error) {
+	origin = normalizeName(origin)
+
+	var hosts []Host
+	seen := make(map[Host]bool)
+	withAddress := make(map[string]bool) // 已有A/AAAA记录的名称
+	var otherNames []string              // 只有其他记录的名称，按出现顺序
+	var owner string                     // 上一条记录的名称，所有者为空的记录沿用
+	depth := 0                           // 括号嵌套层数，括号内为多行记录的续行
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := lines.Text()
+		if idx := strings.IndexByte(line, ';'); idx >= 0 {
+			line = line[:idx]
+		}
+
+		// 多行记录（如SOA）的续行不包含需要的信息
+		continued := depth > 0
+		depth += strings.Count(line, "(") - strings.Count(line, ")")
+		if continued || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(line))
+		if len(fields) == 0 {
+			continue
+		}
+
+		// 指令
+		if strings.HasPrefix(fields[0], "$") {
+			if strings.EqualFold(fields[0], "$ORIGIN") && len(fields) > 1 {
+				origin = normalizeName(fields[1])
+			}
+			continue
+		}
+
+		// 行首为空白时沿用上一条记录的名称
+		if line[0] != ' ' && line[0] != '\t' {
+			owner = qualifyName(fields[0], origin)
+			fields = fields[1:]
+		}
+
+		rrType, rdata := parseRecord(fields)
+		if owner == "" || strings.HasPrefix(owner, "*") || !ValidateDomainName(owner) {
+			continue
+		}
+
+		switch rrType {
+		case "A", "AAAA":
+			ip, err := netip.ParseAddr(rdata)
+			if err != nil {
+				logger.Debug("跳过无效的地址记录", "line", lines.Text())
+				continue
+			}
+			withAddress[owner] = true
+			host := Host{IP: ip.Unmap(), Origin: owner, Type: HostTypeIP}
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		case "CNAME":
+			otherNames = append(otherNames, owner)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("读取区域文件失败: %v", err)
+	}
+
+	for _, name := range otherNames {
+		host := Host{Origin: name, Type: HostTypeDomain}
+		if !withAddress[name] && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts, nil
+}
+
+// parseRecord 跳过TTL和类别字段，返回记录类型和第一个数据字段
+func parseRecord(fields []string) (string, string) {
+	for i, field := range fields {
+		upper := strings.ToUpper(field)
+		if upper == "IN" || upper == "CH" || upper == "HS" || isTTL(field) {
+			continue
+		}
+		if i+1 < len(fields) {
+			return upper, fields[i+1]
+		}
+		return upper, ""
+	}
+	return "", ""
+}
+
+// isTTL 判断字段是否为TTL（纯数字或带单位，如 3600、1h、1d）
+func isTTL(field string) bool {
+	if field == "" || field[0] < '0' || field[0] > '9' {
+		return false
+	}
+	for _, c := range strings.ToLower(field) {
+		if (c < '0' || c > '9') && !strings.ContainsRune("smhdw", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// qualifyName 将区域文件中的相对名称补全为完整域名
+func qualifyName(name, origin string) string {
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return normalizeName(name)
+	case origin == "":
+		return normalizeName(name)
+	default:
+		return normalizeName(name + "." + origin)
+	}
+}
+
+// normalizeName 去掉末尾的点并转为小写
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
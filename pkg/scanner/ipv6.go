@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"strconv"
+)
+
+// IPv6无限扫描模式中各类候选地址的数量
+const (
+	ipv6LowHosts  = 0xff // ::1 到 ::ff
+	ipv6Neighbors = 64   // 种子接口标识符较小时上下各扩展的地址数
+	ipv6EUI64Span = 64   // EUI-64地址上下各扩展的网卡序号数
+)
+
+// IPv6Candidates 返回IPv6无限扫描模式中依次扫描的地址：都在种子地址所在的/64中，按存在主机的可能性排序，不含重复。
+// IPv6地址空间中逐个递增没有意义，服务器的接口标识符通常是手工分配的小数字（::1、::2、::1:1）、
+// 嵌入的IPv4地址（::c0a8:101 或 ::192:168:1:1）或由MAC地址生成的EUI-64（同一厂商的网卡序号相邻），
+// 因此依次尝试：种子地址、与种子相同形式的相邻地址、常见的手工分配地址
+func IPv6Candidates(seed netip.Addr) []netip.Addr {
+	bytes := seed.As16()
+	prefix := binary.BigEndian.Uint64(bytes[:8])
+	seedIID := binary.BigEndian.Uint64(bytes[8:])
+
+	seen := make(map[uint64]bool)
+	var candidates []netip.Addr
+	add := func(iid uint64) {
+		// 接口标识符全为0的是子网路由器任播地址
+		if iid == 0 || seen[iid] {
+			return
+		}
+		seen[iid] = true
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], prefix)
+		binary.BigEndian.PutUint64(b[8:], iid)
+		candidates = append(candidates, netip.AddrFrom16(b).WithZone(seed.Zone()))
+	}
+
+	seen[seedIID] = true
+	candidates = append(candidates, seed)
+
+	// 接口标识符较小时是手工分配的，相邻的地址也可能在使用
+	if seedIID>>32 == 0 {
+		for i := uint64(1); i <= ipv6Neighbors; i++ {
+			if seedIID >= i {
+				add(seedIID - i)
+			}
+			add(seedIID + i)
+		}
+	}
+
+	// 嵌入IPv4地址时扫描同一/24嵌入的其他地址
+	if iids := embeddedIPv4Neighbors(seedIID); iids != nil {
+		for _, iid := range iids {
+			add(iid)
+		}
+	}
+
+	// EUI-64地址（中间为ff:fe）保持厂商部分不变，扫描相邻的网卡序号
+	if seedIID>>24&0xffff == 0xfffe {
+		nic := seedIID & 0xffffff
+		for i := uint64(1); i <= ipv6EUI64Span; i++ {
+			if nic >= i {
+				add(seedIID - i)
+			}
+			if nic+i <= 0xffffff {
+				add(seedIID + i)
+			}
+		}
+	}
+
+	// 常见的手工分配地址：::1-::ff、::1:1-::ff:1、::100-::ff00
+	for i := uint64(1); i <= ipv6LowHosts; i++ {
+		add(i)
+	}
+	for i := uint64(1); i <= ipv6LowHosts; i++ {
+		add(i<<16 | 1)
+	}
+	for i := uint64(1); i <= ipv6LowHosts; i++ {
+		add(i << 8)
+	}
+
+	// 种子地址之外跳过回环、多播等无效地址
+	valid := candidates[:0]
+	for i, ip := range candidates {
+		if i == 0 || isValidIP(ip) {
+			valid = append(valid, ip)
+		}
+	}
+	return valid
+}
+
+// embeddedIPv4Neighbors 接口标识符嵌入了IPv4地址时，返回以同样形式嵌入同一/24中其他地址的接口标识符，否则返回nil。
+// 支持两种形式：低32位为IPv4地址（::c0a8:101），以及四组各写一个十进制字节（::192:168:1:1）
+func embeddedIPv4Neighbors(iid uint64) []uint64 {
+	var iids []uint64
+	switch {
+	case iid>>32 == 0 && iid>>24 != 0:
+		base := iid &^ 0xff
+		for last := uint64(1); last < 0xff; last++ {
+			iids = append(iids, base|last)
+		}
+	case decimalGroups(iid):
+		base := iid &^ 0xffff
+		for last := 1; last < 0xff; last++ {
+			// 十进制数字按十六进制书写，如 192 写作 0x192
+			group, _ := strconv.ParseUint(strconv.Itoa(last), 16, 16)
+			iids = append(iids, base|group)
+		}
+	}
+	return iids
+}
+
+// decimalGroups 判断接口标识符的四组是否都是按十六进制书写的十进制字节（0-255），且第一组不为0
+func decimalGroups(iid uint64) bool {
+	if iid>>48 == 0 {
+		return false
+	}
+	for shift := 0; shift < 64; shift += 16 {
+		text := strconv.FormatUint(iid>>shift&0xffff, 16)
+		n, err := strconv.Atoi(text)
+		if err != nil || n > 255 {
+			return false
+		}
+	}
+	return true
+}
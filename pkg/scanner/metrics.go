@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stage 扫描阶段
+type Stage int
+
+const (
+	StageResolve   Stage = iota // 域名解析
+	StageDial                   // TCP连接
+	StageHandshake              // TLS握手
+	StagePostCheck              // 可行性判断（CDN检测、ping等）
+	stageCount
+)
+
+// String 返回阶段名称
+func (s Stage) String() string {
+	switch s {
+	case StageResolve:
+		return "域名解析"
+	case StageDial:
+		return "TCP连接"
+	case StageHandshake:
+		return "TLS握手"
+	case StagePostCheck:
+		return "可行性判断"
+	default:
+		return "未知"
+	}
+}
+
+// stageMetrics 单个阶段的耗时统计
+type stageMetrics struct {
+	count atomic.Int64
+	total atomic.Int64 // 纳秒
+	max   atomic.Int64 // 纳秒
+}
+
+func (m *stageMetrics) observe(d time.Duration) {
+	m.count.Add(1)
+	m.total.Add(int64(d))
+	for {
+		current := m.max.Load()
+		if int64(d) <= current || m.max.CompareAndSwap(current, int64(d)) {
+			return
+		}
+	}
+}
+
+// workerMetrics 单个工作协程的统计
+type workerMetrics struct {
+	hosts atomic.Int64 // 处理的目标数
+	busy  atomic.Int64 // 处理目标花费的时间，纳秒
+}
+
+// queueMetrics 队列长度采样
+type queueMetrics struct {
+	samples atomic.Int64
+	total   atomic.Int64
+	max     atomic.Int64
+}
+
+func (m *queueMetrics) observe(depth int) {
+	m.samples.Add(1)
+	m.total.Add(int64(depth))
+	for {
+		current := m.max.Load()
+		if int64(depth) <= current || m.max.CompareAndSwap(current, int64(depth)) {
+			return
+		}
+	}
+}
+
+// Metrics 扫描性能统计：各工作协程的吞吐量、各阶段耗时和队列长度，可并发读取
+type Metrics struct {
+	startTime time.Time
+	workers   []*workerMetrics
+	stages    [stageCount]stageMetrics
+	hostQueue queueMetrics // 待扫描目标队列
+	results   queueMetrics // 结果队列
+}
+
+// StageSnapshot 阶段耗时快照
+type StageSnapshot struct {
+	Stage Stage
+	Count int64
+	Avg   time.Duration
+	Max   time.Duration
+	Total time.Duration
+}
+
+// WorkerSnapshot 工作协程快照
+type WorkerSnapshot struct {
+	ID          int
+	Hosts       int64   // 处理的目标数
+	Rate        float64 // 每秒处理目标数
+	Utilization float64 // 忙碌时间占比(0-1)
+}
+
+// QueueSnapshot 队列长度快照
+type QueueSnapshot struct {
+	Avg float64
+	Max int64
+}
+
+// MetricsSnapshot 性能统计快照
+type MetricsSnapshot struct {
+	Elapsed     time.Duration
+	Workers     []WorkerSnapshot
+	Stages      []StageSnapshot
+	HostQueue   QueueSnapshot
+	ResultQueue QueueSnapshot
+}
+
+// newMetrics 为workers个工作协程创建统计
+func newMetrics(workers int) *Metrics {
+	m := &Metrics{startTime: time.Now(), workers: make([]*workerMetrics, workers)}
+	for i := range m.workers {
+		m.workers[i] = &workerMetrics{}
+	}
+	return m
+}
+
+// observeStage 记录阶段耗时，m为nil时忽略
+func (m *Metrics) observeStage(stage Stage, start time.Time) {
+	if m != nil {
+		m.stages[stage].observe(time.Since(start))
+	}
+}
+
+// Snapshot 返回当前性能统计快照
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	elapsed := time.Since(m.startTime)
+	snap := MetricsSnapshot{Elapsed: elapsed}
+
+	for i, w := range m.workers {
+		ws := WorkerSnapshot{ID: i, Hosts: w.hosts.Load()}
+		if elapsed > 0 {
+			ws.Rate = float64(ws.Hosts) / elapsed.Seconds()
+			ws.Utilization = float64(w.busy.Load()) / float64(elapsed)
+		}
+		snap.Workers = append(snap.Workers, ws)
+	}
+
+	for i := range m.stages {
+		stage := &m.stages[i]
+		ss := StageSnapshot{
+			Stage: Stage(i),
+			Count: stage.count.Load(),
+			Max:   time.Duration(stage.max.Load()),
+			Total: time.Duration(stage.total.Load()),
+		}
+		if ss.Count > 0 {
+			ss.Avg = ss.Total / time.Duration(ss.Count)
+		}
+		snap.Stages = append(snap.Stages, ss)
+	}
+
+	snap.HostQueue = m.hostQueue.snapshot()
+	snap.ResultQueue = m.results.snapshot()
+	return snap
+}
+
+func (m *queueMetrics) snapshot() QueueSnapshot {
+	qs := QueueSnapshot{Max: m.max.Load()}
+	if samples := m.samples.Load(); samples > 0 {
+		qs.Avg = float64(m.total.Load()) / float64(samples)
+	}
+	return qs
+}
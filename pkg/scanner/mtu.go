@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// MTU探测结果，记录在Result.MTU中
+const (
+	MTUOK        = "ok"        // 大尺寸ClientHello握手成功
+	MTUBlackhole = "blackhole" // 普通握手成功但大尺寸ClientHello超时，路径上存在PMTU黑洞或丢弃大报文的中间设备
+	MTUReset     = "reset"     // 大尺寸ClientHello导致连接被重置或关闭
+	MTUAlert     = "alert"     // 对端拒绝了大尺寸ClientHello
+	MTUError     = "error"     // 其他错误
+)
+
+// largeHelloProtos 放大ClientHello的填充ALPN协议，使ClientHello约4KB、需要多个TCP分段传输；
+// 服务器忽略不认识的ALPN协议，仍然协商h2
+var largeHelloProtos = func() []string {
+	protos := []string{"h2", "http/1.1"}
+	for i := 0; i < 16; i++ {
+		protos = append(protos, fmt.Sprintf("x-pad-%02d-%s", i, strings.Repeat("0", 240)))
+	}
+	return protos
+}()
+
+// probeMTU 使用约4KB的ClientHello重新握手，检查大尺寸握手报文能否完整到达目标
+// 对端无法正常处理需要分段或分片的握手时，作为Reality目标容易出现间歇性连接失败
+func (s *Scanner) probeMTU(ctx context.Context, ip netip.Addr, port int, sni string) string {
+	if s.pace(ctx, ip) != nil {
+		return MTUError
+	}
+	timeout := time.Duration(s.cfg.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := s.cfg.Dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(ip, uint16(port)).String())
+	if err != nil {
+		return MTUError
+	}
+	defer conn.Close()
+
+	tlsConfig := s.tlsConfig.Clone()
+	tlsConfig.ServerName = sni
+	tlsConfig.NextProtos = largeHelloProtos
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	defer tlsConn.Close()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		switch classifyTLSError(err) {
+		case ErrorTLSTimeout:
+			return MTUBlackhole
+		case ErrorTLSEOF, ErrorReset:
+			return MTUReset
+		case ErrorTLSAlert:
+			return MTUAlert
+		default:
+			return MTUError
+		}
+	}
+	return MTUOK
+}
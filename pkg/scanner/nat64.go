@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// WellKnownNAT64Prefix RFC 6052 规定的NAT64知名前缀
+var WellKnownNAT64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// nat64DetectName RFC 7050 用于发现NAT64前缀的域名，只有A记录192.0.0.170和192.0.0.171，
+// 经DNS64解析得到的AAAA记录即为以NAT64前缀合成的地址
+const nat64DetectName = "ipv4only.arpa"
+
+// nat64Markers ipv4only.arpa 的A记录
+var nat64Markers = []netip.Addr{netip.AddrFrom4([4]byte{192, 0, 0, 170}), netip.AddrFrom4([4]byte{192, 0, 0, 171})}
+
+// nat64Offsets RFC 6052 各前缀长度下IPv4地址四个字节在IPv6地址中的位置，第8字节（u字段）必须为0
+var nat64Offsets = map[int][4]int{
+	32: {4, 5, 6, 7},
+	40: {5, 6, 7, 9},
+	48: {6, 7, 9, 10},
+	56: {7, 9, 10, 11},
+	64: {9, 10, 11, 12},
+	96: {12, 13, 14, 15},
+}
+
+// ParseNAT64Prefix 解析NAT64前缀，前缀长度必须是 RFC 6052 允许的32、40、48、56、64或96
+func ParseNAT64Prefix(s string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil || !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return netip.Prefix{}, fmt.Errorf("无效的NAT64前缀: %s（应为IPv6前缀，如 64:ff9b::/96）", s)
+	}
+	if _, ok := nat64Offsets[prefix.Bits()]; !ok {
+		return netip.Prefix{}, fmt.Errorf("无效的NAT64前缀长度: /%d（支持 /32、/40、/48、/56、/64、/96）", prefix.Bits())
+	}
+	return prefix.Masked(), nil
+}
+
+// SynthesizeNAT64 返回IPv4地址经NAT64前缀合成的IPv6地址，ip不是IPv4地址时原样返回
+func SynthesizeNAT64(prefix netip.Prefix, ip netip.Addr) netip.Addr {
+	ip = ip.Unmap()
+	offsets, ok := nat64Offsets[prefix.Bits()]
+	if !ip.Is4() || !ok {
+		return ip
+	}
+	b := prefix.Masked().Addr().As16()
+	v4 := ip.As4()
+	for i, offset := range offsets {
+		b[offset] = v4[i]
+	}
+	return netip.AddrFrom16(b)
+}
+
+// DetectNAT64 通过DNS64解析 ipv4only.arpa 发现NAT64前缀（RFC 7050），
+// 解析器不是DNS64（没有合成的AAAA记录）时返回错误
+func DetectNAT64(ctx context.Context, resolver Resolver) (netip.Prefix, error) {
+	addrs, err := resolver.LookupNetIP(ctx, "ip6", nat64DetectName)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("未检测到DNS64: %v", err)
+	}
+	for _, addr := range addrs {
+		if !addr.Is6() || addr.Is4In6() {
+			continue
+		}
+		// 从最常见的/96开始，找到嵌入了标记地址的前缀长度
+		for _, bits := range []int{96, 64, 56, 48, 40, 32} {
+			prefix, _ := addr.Prefix(bits)
+			for _, marker := range nat64Markers {
+				if SynthesizeNAT64(prefix, marker) == addr {
+					return prefix, nil
+				}
+			}
+		}
+	}
+	return netip.Prefix{}, fmt.Errorf("未检测到DNS64: %s 没有合成的AAAA记录", nat64DetectName)
+}
+
+// NAT64Dialer 在只有IPv6的网络中连接IPv4目标：IPv4地址改为连接经NAT64前缀合成的IPv6地址，
+// 域名没有AAAA记录时解析其A记录后同样合成。扫描结果中仍记录原来的IPv4地址
+type NAT64Dialer struct {
+	Dialer   Dialer       // 实际拨号的Dialer，为nil时使用net.Dialer
+	Prefix   netip.Prefix // NAT64前缀
+	Resolver Resolver     // 解析域名，为nil时使用net.DefaultResolver
+}
+
+// DialContext 实现Dialer接口
+func (d *NAT64Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return dialer.DialContext(ctx, network, net.JoinHostPort(SynthesizeNAT64(d.Prefix, ip).String(), port))
+	}
+
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	// DNS64已合成或本身有AAAA记录时直接连接IPv6地址，否则合成IPv4地址
+	var ipv6, ipv4 []netip.Addr
+	for _, addr := range addrs {
+		if addr = addr.Unmap(); addr.Is6() {
+			ipv6 = append(ipv6, addr)
+		} else {
+			ipv4 = append(ipv4, SynthesizeNAT64(d.Prefix, addr))
+		}
+	}
+	var lastErr error
+	for _, addr := range append(ipv6, ipv4...) {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("域名没有可用的地址: %s", host)
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+)
+
+// Neighborhood 命中后优先扫描周边地址：符合条件的结果所在网段中尚未扫描的地址插队到普通目标之前，
+// 适用于CIDR、IP区间和无限扫描模式（可用的目标往往集中在同一服务商相邻的机架上）
+type Neighborhood struct {
+	bits int // IPv4网段前缀长度，IPv6网段包含相同数量的地址
+
+	mu       sync.Mutex
+	blocks   map[netip.Prefix]*neighborBlock
+	pending  []Host
+	promoted map[netip.Addr]bool // 已插队发送的地址，普通目标中再次出现时跳过
+	wake     chan struct{}
+}
+
+// neighborBlock 网段中已由普通目标发送的地址，网段内地址全部发送后删除
+type neighborBlock struct {
+	emitted  [4]uint64 // 按网段内序号记录已发送的地址，网段最多256个地址
+	count    int
+	expanded bool // 已因命中加入过插队目标
+	template Host // 网段内第一个目标，插队目标沿用其来源和端口
+}
+
+// MinNeighborhoodBits 周边网段前缀长度的下限，网段最多包含256个地址
+const MinNeighborhoodBits = 24
+
+// NewNeighborhood 创建周边优先扫描，bits为IPv4周边网段的前缀长度（如28表示/28共16个地址），
+// IPv6使用包含相同地址数的网段
+func NewNeighborhood(bits int) (*Neighborhood, error) {
+	if bits < MinNeighborhoodBits || bits > 31 {
+		return nil, fmt.Errorf("无效的周边网段: /%d（支持 /%d 到 /31）", bits, MinNeighborhoodBits)
+	}
+	return &Neighborhood{
+		bits:     bits,
+		blocks:   make(map[netip.Prefix]*neighborBlock),
+		promoted: make(map[netip.Addr]bool),
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// block 返回地址所在的周边网段以及地址在网段内的序号
+func (n *Neighborhood) block(ip netip.Addr) (netip.Prefix, int) {
+	bits := n.bits
+	if ip.Is6() {
+		bits += 96
+	}
+	prefix, _ := ip.Prefix(bits)
+	last := ip.As16()[15]
+	return prefix, int(last) & (1<<(ip.BitLen()-bits) - 1)
+}
+
+// Prioritize 转发hosts中的目标，有插队目标时优先发送；插队扫描过的地址不会再次发送
+// hosts关闭且没有插队目标时关闭返回的通道，ctx取消时提前结束
+func (n *Neighborhood) Prioritize(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		for {
+			if host, ok := n.next(); ok {
+				if !sendHost(ctx, out, host) {
+					return
+				}
+				continue
+			}
+
+			select {
+			case host, ok := <-hosts:
+				if !ok {
+					return
+				}
+				if n.mark(host) && !sendHost(ctx, out, host) {
+					return
+				}
+			case <-n.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// next 取出下一个插队目标，跳过加入后已由普通目标发送的地址
+func (n *Neighborhood) next() (Host, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for len(n.pending) > 0 {
+		host := n.pending[0]
+		n.pending = n.pending[1:]
+
+		prefix, idx := n.block(host.IP)
+		if block := n.blocks[prefix]; block == nil || block.emitted[idx/64]&(1<<(idx%64)) != 0 {
+			continue
+		}
+		n.promoted[host.IP] = true
+		return host, true
+	}
+	return Host{}, false
+}
+
+// mark 记录即将发送的普通目标，地址已经插队发送过时返回false
+func (n *Neighborhood) mark(host Host) bool {
+	if host.Type != HostTypeIP || !host.IP.IsValid() {
+		return true
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	prefix, idx := n.block(host.IP)
+	block := n.blocks[prefix]
+	if block == nil {
+		block = &neighborBlock{template: host}
+		n.blocks[prefix] = block
+	}
+	if block.emitted[idx/64]&(1<<(idx%64)) == 0 {
+		block.emitted[idx/64] |= 1 << (idx % 64)
+		block.count++
+	}
+
+	// 网段已全部扫描，不会再有插队目标
+	if block.count == 1<<(prefix.Addr().BitLen()-prefix.Bits()) {
+		delete(n.blocks, prefix)
+	}
+
+	if n.promoted[host.IP] {
+		delete(n.promoted, host.IP)
+		return false
+	}
+	return true
+}
+
+// Watch 转发扫描结果，符合条件的结果所在网段中尚未扫描的地址加入插队目标，返回的通道在results关闭后关闭
+func (n *Neighborhood) Watch(results <-chan Result) <-chan Result {
+	out := make(chan Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			n.Observe(result)
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Observe 检查单个结果，符合条件时将所在网段中尚未扫描的地址加入插队目标
+// 插队目标不会超出原始目标（CIDR或IP区间）的范围
+func (n *Neighborhood) Observe(result Result) {
+	if !result.Feasible {
+		return
+	}
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	prefix, _ := n.block(ip)
+	block := n.blocks[prefix]
+	if block == nil || block.expanded {
+		// 网段已全部扫描，或命中不是来自本次扫描的目标
+		return
+	}
+	block.expanded = true
+
+	start, end, _, boundsErr := AddressBounds(block.template.Origin)
+	added := 0
+	for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+		_, idx := n.block(addr)
+		if block.emitted[idx/64]&(1<<(idx%64)) != 0 || n.promoted[addr] || !isValidIP(addr) {
+			continue
+		}
+		if boundsErr == nil && (addr.Compare(start) < 0 || addr.Compare(end) > 0) {
+			continue
+		}
+		host := block.template
+		host.IP = addr
+		n.pending = append(n.pending, host)
+		added++
+	}
+	if added == 0 {
+		return
+	}
+
+	logger.Debug("命中后优先扫描周边地址", "ip", result.IP, "block", prefix, "hosts", added)
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,25 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// Dialer 建立TCP连接，*net.Dialer 即满足该接口
+// 可替换为代理拨号器或带统计的实现
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Resolver 解析域名和反向解析IP，*net.Resolver 即满足该接口
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// HTTPClient 发送HTTP请求，*http.Client 即满足该接口
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
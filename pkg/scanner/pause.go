@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+)
+
+// Pauser 暂停目标分发：暂停期间不再向扫描线程转发新目标，正在进行的握手照常完成
+type Pauser struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{} // 暂停期间有效，恢复时关闭
+}
+
+// Pause 暂停分发新目标，已暂停时不做任何事
+func (p *Pauser) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		p.paused = true
+		p.resume = make(chan struct{})
+	}
+}
+
+// Resume 恢复分发目标，未暂停时不做任何事
+func (p *Pauser) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		p.paused = false
+		close(p.resume)
+	}
+}
+
+// Toggle 切换暂停状态，返回切换后是否处于暂停
+func (p *Pauser) Toggle() bool {
+	if p.Paused() {
+		p.Resume()
+		return false
+	}
+	p.Pause()
+	return true
+}
+
+// Paused 返回当前是否处于暂停
+func (p *Pauser) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// wait 暂停期间阻塞直到恢复，ctx取消时返回false
+func (p *Pauser) wait(ctx context.Context) bool {
+	p.mu.Lock()
+	resume := p.resume
+	paused := p.paused
+	p.mu.Unlock()
+
+	if !paused {
+		return true
+	}
+	select {
+	case <-resume:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Filter 转发hosts中的目标，暂停期间停止转发，ctx取消时提前结束
+func (p *Pauser) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		for host := range hosts {
+			if !p.wait(ctx) || !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePorts 解析端口列表，支持逗号分隔和区间，如 "443,8443,2050-2053"
+// 返回的端口按首次出现的顺序去重
+func ParsePorts(spec string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end := part, part
+		if idx := strings.Index(part, "-"); idx > 0 {
+			start, end = part[:idx], part[idx+1:]
+		}
+
+		from, err := parsePort(start)
+		if err != nil {
+			return nil, fmt.Errorf("无效的端口: %s", part)
+		}
+		to, err := parsePort(end)
+		if err != nil {
+			return nil, fmt.Errorf("无效的端口: %s", part)
+		}
+		if from > to {
+			return nil, fmt.Errorf("无效的端口区间: %s", part)
+		}
+
+		for port := from; port <= to; port++ {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("端口列表为空")
+	}
+	return ports, nil
+}
+
+// parsePort 解析单个端口号
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("端口不是数字: %q", s)
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("端口超出范围 1-65535: %d", port)
+	}
+	return port, nil
+}
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EdgeSuffixes 反向解析名称属于CDN边缘节点或云负载均衡的常见后缀，这类地址不可能作为Reality目标
+var EdgeSuffixes = []string{
+	".cloudfront.net",
+	".akamaitechnologies.com",
+	".akamaiedge.net",
+	".akamai.net",
+	".edgecastcdn.net",
+	".llnw.net",
+	".fastly.net",
+	".cdn77.com",
+	".azureedge.net",
+	".incapdns.net",
+	".cdngc.net",
+	".stackpathdns.com",
+	".elb.amazonaws.com",
+	".cloudapp.azure.com",
+}
+
+// ptrTimeout 单次反向解析的超时时间
+const ptrTimeout = 2 * time.Second
+
+// PTRFilter 握手前的反向解析预过滤：反向解析IP目标，名称属于CDN边缘节点或云负载均衡时跳过，
+// 解析失败或没有PTR记录的地址照常扫描；反向解析较慢，由多个协程并发进行，转发顺序与输入不完全一致
+type PTRFilter struct {
+	Resolver Resolver // 反向解析使用的解析器
+	Workers  int      // 并发反向解析的协程数
+	Suffixes []string // 需要跳过的名称后缀，为nil时使用EdgeSuffixes
+
+	// OnSkip 目标因反向解析名称被跳过时调用（可能在多个协程中并发调用），可用于修正进度统计
+	OnSkip func(Host)
+}
+
+// Edge 反向解析IP目标，返回匹配的CDN/负载均衡名称，不匹配或无法解析时返回空字符串
+func (f *PTRFilter) Edge(ctx context.Context, host Host) string {
+	if host.Type != HostTypeIP || !host.IP.IsValid() {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ptrTimeout)
+	defer cancel()
+	names, err := f.Resolver.LookupAddr(ctx, host.IP.String())
+	if err != nil {
+		return ""
+	}
+
+	suffixes := f.Suffixes
+	if suffixes == nil {
+		suffixes = EdgeSuffixes
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(name, suffix) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// Filter 转发hosts中反向解析名称不属于CDN/负载均衡的目标，ctx取消时提前结束
+func (f *PTRFilter) Filter(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+	workers := max(f.Workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				if name := f.Edge(ctx, host); name != "" {
+					logger.Debug("反向解析为CDN或负载均衡，跳过", "ip", host.IP, "ptr", name)
+					if f.OnSkip != nil {
+						f.OnSkip(host)
+					}
+					continue
+				}
+				if !sendHost(ctx, out, host) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 域名解析缓存的有效期，解析失败的结果只短时间缓存，避免临时故障导致域名在整次扫描中被跳过
+const (
+	resolveCacheTTL         = 5 * time.Minute
+	resolveCacheNegativeTTL = time.Minute
+)
+
+// resolveCache 域名解析缓存：同一域名的并发解析只进行一次，成功和失败的结果都会缓存
+type resolveCache struct {
+	mu      sync.Mutex
+	entries map[string]*resolveEntry
+}
+
+// resolveEntry 单个域名的解析结果，done关闭后ips和err可读
+type resolveEntry struct {
+	done    chan struct{}
+	ips     []netip.Addr
+	err     error
+	expires time.Time
+}
+
+func newResolveCache() *resolveCache {
+	return &resolveCache{entries: make(map[string]*resolveEntry)}
+}
+
+// lookup 返回域名的解析结果，缓存未命中或已过期时调用resolve解析；
+// 其他协程正在解析同一域名时等待其结果，ctx取消时放弃等待
+func (c *resolveCache) lookup(ctx context.Context, domain string, resolve func(context.Context, string) ([]netip.Addr, error)) ([]netip.Addr, error) {
+	c.mu.Lock()
+	entry := c.entries[domain]
+	if entry != nil {
+		select {
+		case <-entry.done:
+			if time.Now().After(entry.expires) {
+				entry = nil
+			}
+		default:
+		}
+	}
+
+	if entry == nil {
+		entry = &resolveEntry{done: make(chan struct{})}
+		c.entries[domain] = entry
+		c.mu.Unlock()
+
+		entry.ips, entry.err = resolve(ctx, domain)
+		ttl := resolveCacheTTL
+		if entry.err != nil {
+			ttl = resolveCacheNegativeTTL
+		}
+		entry.expires = time.Now().Add(ttl)
+		close(entry.done)
+
+		// 因取消而失败的结果不代表域名无法解析，不缓存
+		if ctx.Err() != nil {
+			c.mu.Lock()
+			if c.entries[domain] == entry {
+				delete(c.entries, domain)
+			}
+			c.mu.Unlock()
+		}
+		return entry.ips, entry.err
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.ips, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// prefetch 由ResolveWorkers个协程提前解析域名目标，解析完成（结果已缓存）后再转发给握手阶段，
+// 使DNS延迟不占用扫描线程；其他类型的目标直接转发，转发顺序与输入不完全一致
+func (s *Scanner) prefetch(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host, s.cfg.ResolveWorkers)
+
+	var wg sync.WaitGroup
+	wg.Add(s.cfg.ResolveWorkers)
+	for range s.cfg.ResolveWorkers {
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				if host.Type == HostTypeDomain {
+					// 错误在握手阶段从缓存中取得后生成结果
+					if len(s.cfg.Regions) > 0 {
+						s.ResolveRegions(ctx, host.Origin)
+					} else {
+						s.ResolveDomain(ctx, host.Origin)
+					}
+				}
+				if !sendHost(ctx, out, host) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// RegionAddr 按地区解析得到的地址及返回该地址的地区
+type RegionAddr struct {
+	IP      netip.Addr
+	Regions []string
+}
+
+// ResolveRegions 由每个地区的解析器并发解析域名，按配置顺序合并各地区的应答并去重；
+// 部分地区解析失败时只使用成功的应答，所有地区都失败时返回错误。每个地区的结果分别缓存
+func (s *Scanner) ResolveRegions(ctx context.Context, domain string) ([]RegionAddr, error) {
+	answers := make([][]netip.Addr, len(s.cfg.Regions))
+	errs := make([]error, len(s.cfg.Regions))
+	var wg sync.WaitGroup
+	for i, region := range s.cfg.Regions {
+		wg.Add(1)
+		go func(i int, region RegionResolver) {
+			defer wg.Done()
+			answers[i], errs[i] = s.dnsCache.lookup(ctx, region.Region+"/"+domain, func(ctx context.Context, _ string) ([]netip.Addr, error) {
+				return region.Resolver.LookupNetIP(ctx, "ip", domain)
+			})
+			if errs[i] != nil {
+				logger.Debug("地区解析失败", "region", region.Region, "domain", domain, "error", errs[i])
+			}
+		}(i, region)
+	}
+	wg.Wait()
+
+	var (
+		result  []RegionAddr
+		index   = make(map[netip.Addr]int)
+		lastErr error
+	)
+	for i, region := range s.cfg.Regions {
+		if errs[i] != nil {
+			lastErr = fmt.Errorf("%s: %v", region.Region, errs[i])
+			continue
+		}
+		for _, ip := range answers[i] {
+			ip = ip.Unmap()
+			if !s.cfg.IPv6 && !ip.Is4() {
+				continue
+			}
+			if j, ok := index[ip]; ok {
+				if !slices.Contains(result[j].Regions, region.Region) {
+					result[j].Regions = append(result[j].Regions, region.Region)
+				}
+				continue
+			}
+			index[ip] = len(result)
+			result = append(result, RegionAddr{IP: ip, Regions: []string{region.Region}})
+		}
+	}
+
+	if len(result) == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("域名解析失败: %v", lastErr)
+		}
+		return nil, fmt.Errorf("没有找到有效的IP地址")
+	}
+	return result, nil
+}
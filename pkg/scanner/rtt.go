@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// tcpPingInterval 追加TCP连接之间的间隔
+const tcpPingInterval = 100 * time.Millisecond
+
+// tcpPing 追加TCPPings次TCP连接（连接成功后立即关闭），返回包括首次连接在内所有成功连接延迟的中位数(毫秒)
+// 单次连接延迟受排队和重传影响较大，中位数更能反映到目标的网络距离
+func (s *Scanner) tcpPing(ctx context.Context, ip netip.Addr, port int, first int64) int64 {
+	samples := []int64{first}
+	address := netip.AddrPortFrom(ip, uint16(port)).String()
+	timeout := time.Duration(s.cfg.Timeout) * time.Second
+
+	for i := 0; i < s.cfg.TCPPings; i++ {
+		select {
+		case <-time.After(tcpPingInterval):
+		case <-ctx.Done():
+			return median(samples)
+		}
+
+		if s.pace(ctx, ip) != nil {
+			return median(samples)
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		conn, err := s.cfg.Dialer.DialContext(dialCtx, "tcp", address)
+		cancel()
+		if err != nil {
+			continue
+		}
+		samples = append(samples, time.Since(start).Milliseconds())
+		conn.Close()
+	}
+
+	return median(samples)
+}
+
+// median 返回样本的中位数，会对samples排序
+func median(samples []int64) int64 {
+	slices.Sort(samples)
+	n := len(samples)
+	if n%2 == 1 {
+		return samples[n/2]
+	}
+	return (samples[n/2-1] + samples[n/2]) / 2
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// AddressBounds 返回CIDR或IP区间（支持端口后缀）的起止地址和端口
+func AddressBounds(target string) (netip.Addr, netip.Addr, int, error) {
+	target, port, err := SplitHostPort(target)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, 0, err
+	}
+
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		prefix = prefix.Masked()
+		start := prefix.Addr().Unmap()
+		end := addrOffset(start, new(big.Int).Sub(CIDRSize(prefix), big.NewInt(1)))
+		return start, end, port, nil
+	}
+
+	start, end, err := ParseRange(target)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, 0, fmt.Errorf("不是CIDR或IP区间: %s", target)
+	}
+	return start, end, port, nil
+}
+
+// CountSampleTargets 返回从CIDR或IP区间中抽样n个地址时实际产生的目标数以及地址总数
+func CountSampleTargets(target string, n int) (int, *big.Int, error) {
+	start, end, _, err := AddressBounds(target)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size := RangeSize(start, end)
+	if size.Cmp(big.NewInt(int64(n))) < 0 {
+		return int(size.Int64()), size, nil
+	}
+	return n, size, nil
+}
+
+// IterateSample 从CIDR或IP区间中均匀随机抽取n个不重复的地址，支持端口后缀，
+// 用于快速了解大网段（如/8）的整体情况；地址总数不超过n时按顺序扫描全部地址
+func IterateSample(ctx context.Context, target string, n int) <-chan Host {
+	hostChan := make(chan Host, 100)
+
+	go func() {
+		defer close(hostChan)
+
+		origin, _, _ := SplitHostPort(target)
+		start, end, port, err := AddressBounds(target)
+		if err != nil {
+			logger.Error("解析抽样目标失败", "target", target, "error", err)
+			return
+		}
+
+		size := RangeSize(start, end)
+		count := 0
+		if size.Cmp(big.NewInt(int64(n))) <= 0 {
+			for ip := start; ip.IsValid() && ip.Compare(end) <= 0; ip = ip.Next() {
+				if !sendHost(ctx, hostChan, Host{IP: ip, Origin: origin, Type: HostTypeIP, Port: port}) {
+					return
+				}
+				count++
+			}
+		} else {
+			// n远小于地址总数时重复抽中的概率很低，记录已抽中的地址去重即可
+			seen := make(map[netip.Addr]bool, n)
+			for count < n {
+				offset, err := rand.Int(rand.Reader, size)
+				if err != nil {
+					logger.Error("生成随机地址失败", "error", err)
+					return
+				}
+				ip := addrOffset(start, offset)
+				if seen[ip] {
+					continue
+				}
+				seen[ip] = true
+
+				if !sendHost(ctx, hostChan, Host{IP: ip, Origin: origin, Type: HostTypeIP, Port: port}) {
+					return
+				}
+				count++
+			}
+		}
+
+		logger.Debug("抽样完成", "target", origin, "count", count, "addresses", size)
+	}()
+
+	return hostChan
+}
@@ -0,0 +1,590 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MengMengCode/GetRealityDomain/internal/logging"
+	"github.com/MengMengCode/GetRealityDomain/pkg/geo"
+)
+
+var logger = logging.For("scanner")
+
+// Scanner 按照给定配置执行扫描
+// 配置在创建时复制，不同配置的Scanner可以在同一进程中同时运行
+type Scanner struct {
+	cfg       Config
+	geoDB     *geo.Geo                // 地理位置数据库，为nil时跳过地理位置查询
+	metrics   atomic.Pointer[Metrics] // 最近一次ScanWithConcurrency的性能统计
+	tlsConfig *tls.Config             // 不带SNI的共享TLS配置，IP目标直接复用，避免每个连接重新分配
+	dnsCache  *resolveCache           // 域名解析缓存
+}
+
+// New 创建扫描器，cfg为nil时使用默认配置，未设置的字段使用默认值
+func New(cfg *Config, geoDB *geo.Geo) *Scanner {
+	defaults := DefaultConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+
+	s := &Scanner{cfg: *cfg, geoDB: geoDB}
+	if s.cfg.Port <= 0 {
+		s.cfg.Port = defaults.Port
+	}
+	if len(s.cfg.Ports) == 0 {
+		s.cfg.Ports = []int{s.cfg.Port}
+	} else {
+		s.cfg.Ports = append([]int(nil), s.cfg.Ports...)
+		s.cfg.Port = s.cfg.Ports[0]
+	}
+	if s.cfg.Thread <= 0 {
+		s.cfg.Thread = defaults.Thread
+	}
+	if s.cfg.Timeout <= 0 {
+		s.cfg.Timeout = defaults.Timeout
+	}
+	if s.cfg.Dialer == nil {
+		s.cfg.Dialer = defaults.Dialer
+	}
+	if s.cfg.Resolver == nil {
+		s.cfg.Resolver = defaults.Resolver
+	}
+	if s.cfg.HTTPClient == nil {
+		s.cfg.HTTPClient = defaults.HTTPClient
+	}
+	s.tlsConfig = newTLSConfig()
+	s.dnsCache = newResolveCache()
+	return s
+}
+
+// newTLSConfig 创建Reality专用TLS配置
+func newTLSConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,                       // 跳过证书验证
+		NextProtos:         []string{"h2", "http/1.1"}, // ALPN协议优先HTTP/2
+		CurvePreferences:   []tls.CurveID{tls.X25519},  // 强制使用X25519椭圆曲线
+	}
+}
+
+// Metrics 返回最近一次ScanWithConcurrency的性能统计，尚未开始扫描时返回nil
+func (s *Scanner) Metrics() *Metrics {
+	return s.metrics.Load()
+}
+
+// Config 返回扫描器使用的配置副本
+func (s *Scanner) Config() Config {
+	return s.cfg
+}
+
+// ScanTLS 执行TLS扫描，ctx取消时尽快中止连接并放弃发送结果
+func (s *Scanner) ScanTLS(ctx context.Context, host Host, resultChan chan<- Result) {
+	// 根据主机类型获取IP地址
+	switch host.Type {
+	case HostTypeIP:
+		// 单个IP是大规模扫描的热路径，直接扫描，不为其分配切片
+		s.scanPorts(ctx, host.IP, host, resultChan)
+	case HostTypeDomain:
+		if len(s.cfg.Regions) > 0 {
+			s.scanRegions(ctx, host, resultChan)
+			return
+		}
+		resolveStart := time.Now()
+		ips, err := s.ResolveDomain(ctx, host.Origin)
+		s.metrics.Load().observeStage(StageResolve, resolveStart)
+		if err != nil {
+			sendResult(ctx, resultChan, Result{
+				IP:        "",
+				Origin:    host.resultOrigin(),
+				Port:      s.primaryPort(host),
+				ErrorKind: ErrorDNS,
+				Error:     fmt.Sprintf("域名解析失败: %v", err),
+			})
+			return
+		}
+
+		// 扫描每个IP
+		for _, ip := range ips {
+			if ctx.Err() != nil {
+				return
+			}
+			if s.cfg.Exclude.Contains(ip) {
+				logger.Debug("解析得到的地址在排除名单中，跳过", "domain", host.Origin, "ip", ip)
+				continue
+			}
+			s.scanPorts(ctx, ip, host, resultChan)
+		}
+	default:
+		sendResult(ctx, resultChan, Result{
+			IP:        "",
+			Origin:    host.resultOrigin(),
+			Port:      s.primaryPort(host),
+			ErrorKind: ErrorUnsupportedHost,
+			Error:     "不支持的主机类型",
+		})
+	}
+}
+
+// scanRegions 按地区解析域名目标并扫描所有地区返回的IP，结果记录返回该IP的地区
+func (s *Scanner) scanRegions(ctx context.Context, host Host, resultChan chan<- Result) {
+	resolveStart := time.Now()
+	addrs, err := s.ResolveRegions(ctx, host.Origin)
+	s.metrics.Load().observeStage(StageResolve, resolveStart)
+	if err != nil {
+		sendResult(ctx, resultChan, Result{
+			IP:        "",
+			Origin:    host.resultOrigin(),
+			Port:      s.primaryPort(host),
+			ErrorKind: ErrorDNS,
+			Error:     err.Error(),
+		})
+		return
+	}
+
+	for _, addr := range addrs {
+		if ctx.Err() != nil {
+			return
+		}
+		if s.cfg.Exclude.Contains(addr.IP) {
+			logger.Debug("解析得到的地址在排除名单中，跳过", "domain", host.Origin, "ip", addr.IP)
+			continue
+		}
+		regionHost := host
+		regionHost.Region = strings.Join(addr.Regions, ",")
+		s.scanPorts(ctx, addr.IP, regionHost, resultChan)
+	}
+}
+
+// sendResult 发送扫描结果，ctx已取消时放弃发送并返回false
+func sendResult(ctx context.Context, resultChan chan<- Result, result Result) bool {
+	select {
+	case resultChan <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// primaryPort 返回目标的首个扫描端口，用于记录未进入端口扫描阶段的结果
+func (s *Scanner) primaryPort(host Host) int {
+	if host.Port > 0 {
+		return host.Port
+	}
+	return s.cfg.Port
+}
+
+// scanPorts 依次扫描IP的每个端口，每个端口产生一条结果；目标指定了端口时只扫描该端口
+func (s *Scanner) scanPorts(ctx context.Context, ip netip.Addr, host Host, resultChan chan<- Result) {
+	if host.Port > 0 {
+		s.scanSingleIP(ctx, ip, host.Port, host, resultChan)
+		return
+	}
+	for _, port := range s.cfg.Ports {
+		if ctx.Err() != nil {
+			return
+		}
+		s.scanSingleIP(ctx, ip, port, host, resultChan)
+	}
+}
+
+// scanSingleIP 扫描单个IP地址的指定端口，失败时按配置尝试备用端口，然后判断可行性并发送结果
+func (s *Scanner) scanSingleIP(ctx context.Context, ip netip.Addr, port int, host Host, resultChan chan<- Result) {
+	result := s.handshake(ctx, ip, port, host)
+	if !isTLS13(result) && len(s.cfg.DiscoverPorts) > 0 {
+		result = s.discoverPort(ctx, ip, host, result)
+	}
+
+	// 区分中间设备干扰和主机本身的故障
+	if s.cfg.DetectInterference && suspectInterference(result.ErrorKind) {
+		if kind := s.detectInterference(ctx, ip, result.Port, result.SNI); kind != "" {
+			result.Middlebox = kind
+			result.ErrorKind = ErrorInterference
+			result.Error = fmt.Sprintf("疑似中间设备干扰(%s): %s", kind, result.Error)
+		}
+	}
+
+	// 以候选SNI重新握手，记录出示了匹配证书的SNI
+	if isTLS13(result) {
+		if candidates := s.sniCandidates(ctx, ip, host, result); len(candidates) > 0 {
+			result.SNIMatch = strings.Join(s.verifySNIs(ctx, ip, result.Port, result.SNIMatch, candidates), ",")
+		}
+	}
+
+	// 多次TCP连接取延迟中位数
+	if isTLS13(result) && s.cfg.TCPPings > 0 {
+		result.TCPRTT = s.tcpPing(ctx, ip, result.Port, result.TCPRTT)
+	}
+
+	// 检测大尺寸握手报文能否完整到达
+	if isTLS13(result) && s.cfg.ProbeMTU {
+		result.MTU = s.probeMTU(ctx, ip, result.Port, result.SNI)
+	}
+
+	// 检查目标对模仿真实客户端的ClientHello的响应
+	if isTLS13(result) && s.cfg.HelloProfile != nil {
+		result.HelloProfile = s.ProbeHello(ctx, ip, result.Port, result.SNI)
+	}
+
+	// 根据ICMP应答的TTL估算跳数
+	if isTLS13(result) && s.cfg.ProbeTTL {
+		result.TTL = probeTTL(ctx, ip)
+		result.Hops = EstimateHops(result.TTL)
+	}
+
+	// 获取地理位置信息
+	if s.geoDB != nil {
+		loc := s.geoDB.Lookup(ip)
+		result.GeoCode = loc.CountryCode
+		result.City = loc.City
+		result.ASN = loc.ASN
+		result.ASOrg = loc.ASOrg
+
+		// 目标指定了期望的地理位置时，不符的结果记为失败
+		if host.Country != "" && result.Error == "" && !strings.EqualFold(result.GeoCode, host.Country) {
+			result.ErrorKind = ErrorGeoMismatch
+			result.Error = fmt.Sprintf("地理位置不符: 期望%s，实际%s", strings.ToUpper(host.Country), result.GeoCode)
+		}
+	}
+
+	// 判断是否符合Reality要求
+	if result.Error == "" && s.cfg.Judge != nil {
+		judgeStart := time.Now()
+		result.Feasible = s.cfg.Judge(ctx, result)
+		s.metrics.Load().observeStage(StagePostCheck, judgeStart)
+	}
+
+	// 发送结果
+	if !sendResult(ctx, resultChan, result) {
+		return
+	}
+
+	// 详细输出，未开启调试日志时跳过以免为每个结果构造日志属性
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	logger.Debug("扫描完成",
+		"ip", result.IP, "port", result.Port, "feasible", result.Feasible,
+		"tls", result.TLSVersion, "alpn", result.ALPN, "domain", result.CertDomain,
+		"response_time_ms", result.ResponseTime, "error", result.Error)
+}
+
+// isTLS13 判断结果是否完成了TLS 1.3握手
+func isTLS13(result Result) bool {
+	return result.Error == "" && result.TLSVersion == "TLS 1.3"
+}
+
+// maxSNICandidates 每个目标最多验证的候选SNI数
+const maxSNICandidates = 8
+
+// sniCandidates 返回需要验证的候选SNI：目标自带的其余SNI；
+// 开启VerifySNI时，未指定SNI的IP目标再加上证书中的域名和PTR记录
+func (s *Scanner) sniCandidates(ctx context.Context, ip netip.Addr, host Host, result Result) []string {
+	candidates := host.sniCandidates()
+	if s.cfg.VerifySNI && host.serverName() == "" {
+		for _, domain := range strings.Split(result.CertDomain, ",") {
+			if domain != "" && !strings.HasPrefix(domain, "*") {
+				candidates = append(candidates, strings.ToLower(domain))
+			}
+		}
+
+		ptrCtx, cancel := context.WithTimeout(ctx, ptrTimeout)
+		names, err := s.cfg.Resolver.LookupAddr(ptrCtx, ip.String())
+		cancel()
+		if err == nil {
+			for _, name := range names {
+				name = strings.ToLower(strings.TrimSuffix(name, "."))
+				if ValidateDomainName(name) && strings.Contains(name, ".") {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+	}
+
+	// 去掉重复和已用于握手的SNI
+	var unique []string
+	for _, sni := range candidates {
+		if sni != result.SNI && !slices.Contains(unique, sni) {
+			unique = append(unique, sni)
+		}
+	}
+	if len(unique) > maxSNICandidates {
+		unique = unique[:maxSNICandidates]
+	}
+	return unique
+}
+
+// verifySNIs 使用每个候选SNI重新握手，返回服务器出示了匹配证书的SNI，matched为已匹配的握手SNI
+func (s *Scanner) verifySNIs(ctx context.Context, ip netip.Addr, port int, matched string, candidates []string) []string {
+	var matches []string
+	if matched != "" {
+		matches = append(matches, matched)
+	}
+	for _, sni := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		result := s.handshake(ctx, ip, port, Host{IP: ip, Origin: ip.String(), Type: HostTypeIP, SNI: sni})
+		if result.SNIMatch != "" {
+			matches = append(matches, result.SNIMatch)
+		}
+	}
+
+	if len(matches) > 0 {
+		logger.Debug("SNI验证完成", "ip", ip, "port", port, "candidates", len(candidates), "matched", matches)
+	}
+	return matches
+}
+
+// discoverPort 主端口未完成TLS 1.3握手时依次尝试备用端口，返回第一个成功的结果
+// 所有备用端口都失败时返回主端口的结果
+func (s *Scanner) discoverPort(ctx context.Context, ip netip.Addr, host Host, primary Result) Result {
+	for _, port := range s.cfg.DiscoverPorts {
+		if ctx.Err() != nil {
+			break
+		}
+		if port == primary.Port || slices.Contains(s.cfg.Ports, port) {
+			continue // 已作为主端口扫描过
+		}
+
+		result := s.handshake(ctx, ip, port, host)
+		if isTLS13(result) {
+			logger.Debug("发现可用端口", "ip", result.IP, "port", port, "primary_port", primary.Port)
+			return result
+		}
+	}
+	return primary
+}
+
+// handshake 连接IP的指定端口完成TLS握手，并提取协议和证书信息
+func (s *Scanner) handshake(ctx context.Context, ip netip.Addr, port int, host Host) Result {
+	return s.inspectHandshake(ctx, ip, port, host, nil)
+}
+
+// inspectHandshake 完成握手，detail不为nil时另外记录各阶段耗时、密码套件和证书链
+func (s *Scanner) inspectHandshake(ctx context.Context, ip netip.Addr, port int, host Host, detail *Inspection) Result {
+	result := Result{
+		IP:     ip.String(),
+		Addr:   ip,
+		Origin: host.resultOrigin(),
+		Port:   port,
+		Region: host.Region,
+	}
+
+	// 低调扫描时按限速规则等待，等待时间不计入响应时间
+	if err := s.pace(ctx, ip); err != nil {
+		result.ErrorKind = classifyDialError(err)
+		result.Error = fmt.Sprintf("TCP连接失败: %v", err)
+		return result
+	}
+	startTime := time.Now()
+
+	// 建立TCP连接
+	address := netip.AddrPortFrom(ip, uint16(port)).String()
+	dialCtx, dialCancel := context.WithTimeout(ctx, time.Duration(s.cfg.Timeout)*time.Second)
+	defer dialCancel()
+	metrics := s.metrics.Load()
+	dialStart := time.Now()
+	conn, err := s.cfg.Dialer.DialContext(dialCtx, "tcp", address)
+	metrics.observeStage(StageDial, dialStart)
+	if detail != nil {
+		detail.DialTime = time.Since(dialStart)
+	}
+	if err != nil {
+		result.ErrorKind = classifyDialError(err)
+		result.Error = fmt.Sprintf("TCP连接失败: %v", err)
+		return result
+	}
+	defer conn.Close()
+	result.TCPRTT = time.Since(dialStart).Milliseconds()
+
+	// 抓包的目标记录连接上收发的数据，握手结束后保存
+	if s.cfg.Capture.Matches(ip) {
+		captured := newCaptureConn(conn, dialStart)
+		conn = captured
+		defer func() { s.cfg.Capture.save(captured, ip, result) }()
+	}
+
+	// 如果原始输入是域名，使用域名作为SNI；如果是IP，不发送SNI，直接复用共享配置
+	tlsConfig := s.tlsConfig
+	if sni := host.serverName(); sni != "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = sni
+		result.SNI = sni
+	}
+
+	// 执行TLS握手，超时与连接超时一致
+	handshakeCtx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.Timeout)*time.Second)
+	defer cancel()
+	tlsConn := tls.Client(conn, tlsConfig)
+	handshakeStart := time.Now()
+	err = tlsConn.HandshakeContext(handshakeCtx)
+	metrics.observeStage(StageHandshake, handshakeStart)
+	if detail != nil {
+		detail.HandshakeTime = time.Since(handshakeStart)
+	}
+	if err != nil {
+		result.ErrorKind = classifyTLSError(err)
+		result.Error = fmt.Sprintf("TLS握手失败: %v", err)
+		return result
+	}
+	defer tlsConn.Close()
+
+	// 获取连接状态
+	state := tlsConn.ConnectionState()
+	if detail != nil {
+		detail.record(state)
+	}
+
+	// 记录响应时间
+	result.ResponseTime = time.Since(startTime).Milliseconds()
+
+	// 提取TLS版本
+	result.TLSVersion = getTLSVersionString(state.Version)
+
+	// 提取ALPN协商结果
+	result.ALPN = state.NegotiatedProtocol
+
+	// 提取椭圆曲线信息
+	result.Curve = getCurveString(state.CipherSuite)
+
+	// 提取证书信息
+	if len(state.PeerCertificates) == 0 {
+		result.ErrorKind = ErrorCertMissing
+		result.Error = "对端未提供证书"
+		return result
+	}
+	cert := state.PeerCertificates[0]
+	fingerprint := sha256.Sum256(cert.Raw)
+	result.CertSHA256 = hex.EncodeToString(fingerprint[:])
+	if result.SNI != "" && cert.VerifyHostname(result.SNI) == nil {
+		result.SNIMatch = result.SNI
+	}
+
+	// 获取证书域名 - 优先使用DNSNames，如果为空则使用CommonName
+	if len(cert.DNSNames) > 0 {
+		// 过滤出有效的域名（包含"."）
+		var validDomains []string
+		for _, domain := range cert.DNSNames {
+			if strings.Contains(domain, ".") {
+				validDomains = append(validDomains, domain)
+			}
+		}
+		if len(validDomains) > 0 {
+			result.CertDomain = strings.Join(validDomains, ",")
+		}
+	}
+
+	// 如果DNSNames中没有有效域名，尝试使用CommonName
+	if result.CertDomain == "" && cert.Subject.CommonName != "" && strings.Contains(cert.Subject.CommonName, ".") {
+		result.CertDomain = cert.Subject.CommonName
+	}
+
+	// 获取证书颁发者
+	result.CertIssuer = cert.Issuer.CommonName
+	if result.CertIssuer == "" && len(cert.Issuer.Organization) > 0 {
+		result.CertIssuer = cert.Issuer.Organization[0]
+	}
+
+	return result
+}
+
+// getTLSVersionString 获取TLS版本字符串
+func getTLSVersionString(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("Unknown(0x%04x)", version)
+	}
+}
+
+// getCurveString 获取椭圆曲线字符串
+func getCurveString(cipherSuite uint16) string {
+	// 由于Go的TLS实现中，椭圆曲线信息不直接暴露在ConnectionState中
+	// 我们通过TLS配置强制使用X25519，所以这里直接返回X25519
+	// 在实际的TLS 1.3连接中，如果握手成功，说明使用了我们指定的X25519
+	return "X25519"
+}
+
+// BatchScan 批量扫描，直到hostChan关闭或ctx取消
+func (s *Scanner) BatchScan(ctx context.Context, hostChan <-chan Host, resultChan chan<- Result) {
+	s.batchScan(ctx, hostChan, resultChan, nil)
+}
+
+// batchScan 批量扫描并记录工作协程的统计，worker为nil时不记录
+func (s *Scanner) batchScan(ctx context.Context, hostChan <-chan Host, resultChan chan<- Result, worker *workerMetrics) {
+	metrics := s.metrics.Load()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case host, ok := <-hostChan:
+			if !ok {
+				return
+			}
+			if metrics != nil {
+				metrics.hostQueue.observe(len(hostChan))
+				metrics.results.observe(len(resultChan))
+			}
+
+			start := time.Now()
+			s.ScanTLS(ctx, host, resultChan)
+			if worker != nil {
+				worker.hosts.Add(1)
+				worker.busy.Add(int64(time.Since(start)))
+			}
+		}
+	}
+}
+
+// ScanWithConcurrency 并发扫描，ctx取消后所有工作协程退出并关闭结果通道
+func (s *Scanner) ScanWithConcurrency(ctx context.Context, hostChan <-chan Host) <-chan Result {
+	resultChan := make(chan Result, 1000)
+
+	// 每次扫描重新统计性能
+	metrics := newMetrics(s.cfg.Thread)
+	s.metrics.Store(metrics)
+
+	// 域名目标由独立的协程池提前解析
+	if s.cfg.ResolveWorkers > 0 {
+		hostChan = s.prefetch(ctx, hostChan)
+	}
+
+	// 使用sync.WaitGroup来等待所有工作协程完成
+	var wg sync.WaitGroup
+
+	// 启动工作协程
+	for i := 0; i < s.cfg.Thread; i++ {
+		wg.Add(1)
+		go func(worker *workerMetrics) {
+			defer wg.Done()
+			if !s.cfg.Stealth.jitterStart(ctx) {
+				return
+			}
+			s.batchScan(ctx, hostChan, resultChan, worker)
+		}(metrics.workers[i])
+	}
+
+	// 启动一个协程来关闭结果通道
+	go func() {
+		wg.Wait() // 等待所有工作协程完成
+		close(resultChan)
+	}()
+
+	return resultChan
+}
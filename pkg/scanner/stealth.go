@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// maxStealthSubnets 记录的网段数超过该值时清理已过期的网段
+const maxStealthSubnets = 4096
+
+// Stealth 低调扫描的限速规则，适合在收到端口扫描投诉就会封号的服务商上运行：
+// 每次连接前随机等待、全局和每个网段的连接速率上限、扫描线程错开启动。
+// 每次TCP连接（包括握手、MTU探测、追加的TCP连接等）都受限，等待时间不计入响应时间
+type Stealth struct {
+	Delay       time.Duration // 每个扫描线程两次连接之间的随机间隔上限，实际间隔均匀分布在 [Delay/2, Delay]
+	Rate        float64       // 全局每秒最多发起的连接数，0表示不限制
+	SubnetRate  float64       // 同一/24（IPv6为/48）每秒最多发起的连接数，0表示不限制
+	StartJitter time.Duration // 扫描线程启动前随机等待的上限，避免扫描开始时的连接突发
+
+	mu      sync.Mutex
+	next    time.Time                  // 全局下一次允许连接的时间
+	subnets map[netip.Prefix]time.Time // 网段 -> 下一次允许连接的时间
+}
+
+// DefaultStealth 返回"低调"配置的限速规则
+func DefaultStealth() *Stealth {
+	return &Stealth{
+		Delay:       2 * time.Second,
+		Rate:        2,
+		SubnetRate:  0.2,
+		StartJitter: 30 * time.Second,
+	}
+}
+
+// wait 随机等待后按全局和网段的速率上限等待到允许连接的时间，ctx取消时返回错误
+func (st *Stealth) wait(ctx context.Context, ip netip.Addr) error {
+	var delay time.Duration
+	if st.Delay > 0 {
+		delay = st.Delay/2 + rand.N(st.Delay/2+1)
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		return err
+	}
+	return sleepContext(ctx, time.Until(st.reserve(ip)))
+}
+
+// reserve 预约一次连接，返回全局和所在网段下一次允许连接的时间中较晚者
+func (st *Stealth) reserve(ip netip.Addr) time.Time {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	at := now
+	if st.Rate > 0 && st.next.After(at) {
+		at = st.next
+	}
+	var subnet netip.Prefix
+	if st.SubnetRate > 0 && ip.IsValid() {
+		bits := 24
+		if !ip.Unmap().Is4() {
+			bits = 48
+		}
+		subnet, _ = ip.Unmap().Prefix(bits)
+		if next := st.subnets[subnet]; next.After(at) {
+			at = next
+		}
+	}
+
+	if st.Rate > 0 {
+		st.next = at.Add(rateInterval(st.Rate))
+	}
+	if subnet.IsValid() {
+		if st.subnets == nil {
+			st.subnets = make(map[netip.Prefix]time.Time)
+		}
+		if len(st.subnets) >= maxStealthSubnets {
+			for prefix, next := range st.subnets {
+				if next.Before(now) {
+					delete(st.subnets, prefix)
+				}
+			}
+		}
+		st.subnets[subnet] = at.Add(rateInterval(st.SubnetRate))
+	}
+	return at
+}
+
+// jitterStart 扫描线程启动前随机等待，ctx取消时返回false；st为nil时直接返回
+func (st *Stealth) jitterStart(ctx context.Context) bool {
+	if st == nil || st.StartJitter <= 0 {
+		return true
+	}
+	return sleepContext(ctx, rand.N(st.StartJitter)) == nil
+}
+
+// pace 按低调扫描的限速规则等待，未启用时直接返回
+func (s *Scanner) pace(ctx context.Context, ip netip.Addr) error {
+	if s.cfg.Stealth == nil {
+		return nil
+	}
+	return s.cfg.Stealth.wait(ctx, ip)
+}
+
+// rateInterval 返回速率对应的连接间隔
+func rateInterval(rate float64) time.Duration {
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// sleepContext 等待指定时长，ctx取消时提前返回其错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
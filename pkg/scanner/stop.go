@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 停止原因，可通过 context.Cause 从扫描ctx中取得
+var (
+	ErrMaxFeasible = errors.New("已达到符合条件的目标数上限")
+	ErrMaxScanned  = errors.New("已达到扫描目标数上限")
+	ErrMaxDuration = errors.New("已达到扫描时长上限")
+	ErrCoverage    = errors.New("已达到目标覆盖率")
+)
+
+// StopConditions 扫描停止条件，各字段为零值时表示不限制
+type StopConditions struct {
+	MaxFeasible int           // 找到的符合条件目标数上限
+	MaxScanned  int           // 已扫描目标数上限
+	MaxDuration time.Duration // 扫描时长上限
+	Coverage    float64       // 已扫描目标占目标总数的百分比(0-100)，目标总数未知时无效
+}
+
+// Stopper 集中判断扫描停止条件，任一条件满足时取消扫描ctx，目标生成随之停止；
+// 扫描和后续各阶段使用不随停止条件取消的ctx（见Feed），已开始的握手和检查仍会完成并写入结果
+type Stopper struct {
+	ctx          context.Context
+	cond         StopConditions
+	totalTargets int
+	cancel       context.CancelCauseFunc
+	timer        *time.Timer
+}
+
+// NewStopper 创建停止条件判断器，返回的ctx应传给目标迭代器和扫描器
+// totalTargets为目标总数，0表示未知（此时覆盖率条件无效）
+func NewStopper(ctx context.Context, cond StopConditions, totalTargets int) (context.Context, *Stopper) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	s := &Stopper{ctx: ctx, cond: cond, totalTargets: totalTargets, cancel: cancel}
+
+	if cond.MaxDuration > 0 {
+		s.timer = time.AfterFunc(cond.MaxDuration, func() {
+			s.Stop(ErrMaxDuration)
+		})
+	}
+
+	return ctx, s
+}
+
+// Observe 根据当前计数判断是否需要停止，已触发停止时返回true
+func (s *Stopper) Observe(scanned, feasible int) bool {
+	switch {
+	case s.cond.MaxFeasible > 0 && feasible >= s.cond.MaxFeasible:
+		s.Stop(ErrMaxFeasible)
+	case s.cond.MaxScanned > 0 && scanned >= s.cond.MaxScanned:
+		s.Stop(ErrMaxScanned)
+	case s.cond.Coverage > 0 && s.totalTargets > 0 &&
+		float64(scanned)/float64(s.totalTargets)*100 >= s.cond.Coverage:
+		s.Stop(ErrCoverage)
+	}
+	return s.ctx.Err() != nil
+}
+
+// Feed 转发hosts直到ctx取消，输出通道不带缓冲，ctx取消后扫描线程不再取得新目标，随后关闭通道；
+// 扫描器使用不随停止条件取消的ctx时，停止条件只停止分发新目标，不中断进行中的握手
+func Feed(ctx context.Context, hosts <-chan Host) <-chan Host {
+	out := make(chan Host)
+
+	go func() {
+		defer close(out)
+		for host := range hosts {
+			if ctx.Err() != nil || !sendHost(ctx, out, host) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Stop 以指定原因停止扫描，重复调用只保留第一次的原因
+func (s *Stopper) Stop(reason error) {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.cancel(reason)
+}
+
+// Reason 返回停止原因的说明，扫描尚未停止时返回空字符串
+func (s *Stopper) Reason() string {
+	cause := context.Cause(s.ctx)
+	switch {
+	case cause == nil:
+		return ""
+	case errors.Is(cause, context.Canceled):
+		return "扫描已取消"
+	default:
+		return fmt.Sprint(cause)
+	}
+}
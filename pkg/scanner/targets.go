@@ -0,0 +1,794 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// domainPattern 基本的域名正则表达式，预先编译避免每个目标重复编译
+var domainPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// ValidateDomainName 验证域名格式是否正确
+func ValidateDomainName(domain string) bool {
+	if len(domain) == 0 || len(domain) > 253 {
+		return false
+	}
+	return domainPattern.MatchString(domain)
+}
+
+// NextIP 获取下一个或上一个IP地址，越过地址空间边界时返回无效的零值
+func NextIP(ip netip.Addr, increment bool) netip.Addr {
+	if increment {
+		return ip.Next()
+	}
+	return ip.Prev()
+}
+
+// MaxCountedHosts 计入进度的最大目标数，地址数更多的网段（如IPv6的/64）仍会逐个扫描，
+// 但目标数视为未知，进度中不显示剩余数
+const MaxCountedHosts = 1 << 40
+
+// countedTargets 将精确的地址总数转换为进度使用的目标数，超过MaxCountedHosts时返回0（未知）
+func countedTargets(size *big.Int) int {
+	if size.Cmp(big.NewInt(MaxCountedHosts)) > 0 {
+		return 0
+	}
+	return int(size.Int64())
+}
+
+// CIDRSize 返回CIDR包含的地址总数
+func CIDRSize(prefix netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+}
+
+// CountCIDRTargets 返回扫描CIDR时产生的目标数以及网段的地址总数，地址数超过MaxCountedHosts时目标数为0（未知）
+func CountCIDRTargets(cidr string) (int, *big.Int, error) {
+	cidr, _, err := SplitHostPort(cidr)
+	if err != nil {
+		return 0, nil, err
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size := CIDRSize(prefix)
+	return countedTargets(size), size, nil
+}
+
+// ParseRange 解析IP区间，如 192.168.1.10-192.168.1.250 或 1.2.3.0-1.2.5.255
+// 起止地址必须属于同一地址族且起始地址不大于结束地址
+func ParseRange(rng string) (netip.Addr, netip.Addr, error) {
+	startStr, endStr, ok := strings.Cut(rng, "-")
+	if !ok {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的IP区间: %s", rng)
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(startStr))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的IP区间起始地址: %s", startStr)
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(endStr))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的IP区间结束地址: %s", endStr)
+	}
+
+	start, end = start.Unmap(), end.Unmap()
+	if start.BitLen() != end.BitLen() {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("IP区间的起止地址不属于同一地址族: %s", rng)
+	}
+	if start.Compare(end) > 0 {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("IP区间的起始地址大于结束地址: %s", rng)
+	}
+	return start, end, nil
+}
+
+// RangeSize 返回IP区间包含的地址总数
+func RangeSize(start, end netip.Addr) *big.Int {
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end.AsSlice()), new(big.Int).SetBytes(start.AsSlice()))
+	return size.Add(size, big.NewInt(1))
+}
+
+// CountRangeTargets 返回扫描IP区间时产生的目标数以及区间的地址总数，地址数超过MaxCountedHosts时目标数为0（未知）
+func CountRangeTargets(rng string) (int, *big.Int, error) {
+	rng, _, err := SplitHostPort(rng)
+	if err != nil {
+		return 0, nil, err
+	}
+	start, end, err := ParseRange(rng)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size := RangeSize(start, end)
+	return countedTargets(size), size, nil
+}
+
+// SplitHostPort 拆分目标的端口后缀，如 example.com:8443、1.2.3.4:2053、[2001:db8::1]:443，
+// 没有端口后缀时port为0；不带方括号的IPv6地址不会被拆分
+func SplitHostPort(target string) (string, int, error) {
+	target = strings.TrimSpace(target)
+
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		// 没有端口或是不带方括号的IPv6地址
+		return target, 0, nil
+	}
+
+	port, err := parsePort(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("无效的端口: %s", target)
+	}
+	return host, port, nil
+}
+
+// 解析主机字符串失败的类别，可用 errors.Is 判断
+var (
+	ErrEmptyHost    = errors.New("目标为空")
+	ErrInvalidPort  = errors.New("无效的端口")
+	ErrInvalidURL   = errors.New("无效的URL")
+	ErrInvalidZone  = errors.New("无效的IPv6区域")
+	ErrInvalidRange = errors.New("无效的IP区间")
+	ErrUnknownHost  = errors.New("无法解析主机")
+)
+
+// HostError 解析主机字符串失败的原因
+type HostError struct {
+	Input  string // 原始输入
+	Kind   error  // 失败类别，为上面的Err*之一
+	Detail string // 补充说明，可以为空
+}
+
+func (e *HostError) Error() string {
+	if e.Input == "" {
+		return e.Kind.Error()
+	}
+	if e.Detail == "" {
+		return fmt.Sprintf("%v: %s", e.Kind, e.Input)
+	}
+	return fmt.Sprintf("%v: %s（%s）", e.Kind, e.Input, e.Detail)
+}
+
+func (e *HostError) Unwrap() error {
+	return e.Kind
+}
+
+// ParseHost 解析主机字符串，返回Host结构体，失败时返回*HostError
+// 支持端口后缀（如 example.com:8443、[2001:db8::1]:443），此时该目标只扫描指定端口；
+// 还接受带区域的IPv6地址（如 fe80::1%eth0）、不带端口的方括号IPv6地址、以末尾点结尾的域名，
+// 以及URL（如 https://example.com:8443/path，只取其中的主机和端口）。
+// 返回的Origin为规范化后的主机部分，不含端口、方括号和域名末尾的点
+func ParseHost(hostStr string) (Host, error) {
+	input := strings.TrimSpace(hostStr)
+	if input == "" {
+		return Host{}, &HostError{Input: hostStr, Kind: ErrEmptyHost}
+	}
+
+	hostStr, port, err := splitHost(input)
+	if err != nil {
+		return Host{}, err
+	}
+
+	// 尝试解析为IP地址，IPv6地址可以带区域
+	if ip, err := netip.ParseAddr(hostStr); err == nil {
+		return Host{
+			IP:     ip.Unmap(),
+			Origin: hostStr,
+			Type:   HostTypeIP,
+			Port:   port,
+		}, nil
+	}
+	if strings.Contains(hostStr, "%") {
+		return Host{}, &HostError{Input: input, Kind: ErrInvalidZone, Detail: "区域只能用于单个IPv6地址，如 fe80::1%eth0"}
+	}
+
+	// 尝试解析为CIDR
+	if _, err := netip.ParsePrefix(hostStr); err == nil {
+		return Host{
+			Origin: hostStr,
+			Type:   HostTypeCIDR,
+			Port:   port,
+		}, nil
+	}
+
+	// 尝试解析为IP区间，起始部分是IP地址时不再当作域名
+	if _, _, err := ParseRange(hostStr); err == nil {
+		return Host{
+			Origin: hostStr,
+			Type:   HostTypeRange,
+			Port:   port,
+		}, nil
+	} else if start, _, ok := strings.Cut(hostStr, "-"); ok {
+		if _, addrErr := netip.ParseAddr(strings.TrimSpace(start)); addrErr == nil {
+			return Host{}, &HostError{Input: input, Kind: ErrInvalidRange, Detail: err.Error()}
+		}
+	}
+
+	// 尝试解析为域名，末尾的点表示完全限定域名，不影响解析
+	domain := strings.ToLower(strings.TrimSuffix(hostStr, "."))
+	if ValidateDomainName(domain) {
+		return Host{
+			Origin: domain,
+			Type:   HostTypeDomain,
+			Port:   port,
+		}, nil
+	}
+
+	return Host{}, &HostError{Input: input, Kind: ErrUnknownHost}
+}
+
+// splitHost 从输入中取出主机部分和端口，去掉URL的协议和路径以及IPv6地址的方括号
+func splitHost(input string) (string, int, error) {
+	if strings.Contains(input, "://") {
+		u, err := url.Parse(input)
+		if err != nil || u.Hostname() == "" {
+			return "", 0, &HostError{Input: input, Kind: ErrInvalidURL}
+		}
+		port := 0
+		if u.Port() != "" {
+			if port, err = parsePort(u.Port()); err != nil {
+				return "", 0, &HostError{Input: input, Kind: ErrInvalidPort, Detail: err.Error()}
+			}
+		}
+		return u.Hostname(), port, nil
+	}
+
+	// 方括号中的IPv6地址，可以不带端口，如 [2001:db8::1]
+	if strings.HasPrefix(input, "[") && strings.HasSuffix(input, "]") {
+		return input[1 : len(input)-1], 0, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(input)
+	if err != nil {
+		// 没有端口或是不带方括号的IPv6地址
+		if strings.HasPrefix(input, "[") {
+			return "", 0, &HostError{Input: input, Kind: ErrUnknownHost, Detail: "方括号不完整"}
+		}
+		return input, 0, nil
+	}
+	port, err := parsePort(portStr)
+	if err != nil {
+		return "", 0, &HostError{Input: input, Kind: ErrInvalidPort, Detail: err.Error()}
+	}
+	return host, port, nil
+}
+
+// ParseTarget 解析目标文件中的一行：主机后可跟空格分隔的 key=value 选项，# 之后为注释
+// 支持的选项：port（只扫描该端口，同端口后缀）、sni（握手使用的SNI，逗号分隔多个时其余作为候选验证）、
+// country（期望的地理位置代码）
+// 例如 1.2.3.0/24 port=8443 sni=www.example.com country=JP
+func ParseTarget(line string) (Host, error) {
+	if idx := strings.IndexByte(line, '#'); idx >= 0 {
+		line = line[:idx]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Host{}, fmt.Errorf("目标为空")
+	}
+
+	host, err := ParseHost(fields[0])
+	if err != nil {
+		return Host{}, err
+	}
+
+	for _, option := range fields[1:] {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok || value == "" {
+			return Host{}, fmt.Errorf("无效的目标选项: %s", option)
+		}
+		switch strings.ToLower(key) {
+		case "port":
+			port, err := parsePort(value)
+			if err != nil {
+				return Host{}, fmt.Errorf("无效的端口选项: %s", option)
+			}
+			host.Port = port
+		case "sni":
+			// 多个SNI用逗号分隔，第一个用于握手，其余作为候选验证
+			var snis []string
+			for _, sni := range strings.Split(value, ",") {
+				sni = strings.ToLower(strings.TrimSuffix(sni, "."))
+				if !ValidateDomainName(sni) {
+					return Host{}, fmt.Errorf("无效的SNI选项: %s", option)
+				}
+				snis = append(snis, sni)
+			}
+			host.SNI = strings.Join(snis, ",")
+		case "country", "geo":
+			if len(value) != 2 {
+				return Host{}, fmt.Errorf("无效的地理位置选项: %s（应为两位国家代码）", option)
+			}
+			host.Country = strings.ToUpper(value)
+		default:
+			return Host{}, fmt.Errorf("未知的目标选项: %s（支持 port、sni、country）", key)
+		}
+	}
+
+	return host, nil
+}
+
+// Iterate 从Reader中迭代读取主机信息，ctx取消时停止读取并关闭通道
+func Iterate(ctx context.Context, reader io.Reader) <-chan Host {
+	return iterate(ctx, reader, false)
+}
+
+// IterateUnique 与Iterate相同，但内容相同的行（解析后的目标和选项相同）只展开一次；
+// 只记录读到的行而不是展开后的地址，重叠的CIDR和IP区间中的相同地址不会去重
+func IterateUnique(ctx context.Context, reader io.Reader) <-chan Host {
+	return iterate(ctx, reader, true)
+}
+
+// iterate 逐行读取并展开目标，unique为true时跳过重复的行
+func iterate(ctx context.Context, reader io.Reader, unique bool) <-chan Host {
+	hostChan := make(chan Host, 100) // 带缓冲的channel
+
+	go func() {
+		defer close(hostChan)
+
+		var seen map[Host]struct{}
+		if unique {
+			seen = make(map[Host]struct{})
+		}
+		duplicates := 0
+		defer func() {
+			if duplicates > 0 {
+				logger.Info("已跳过重复的目标", "count", duplicates)
+			}
+		}()
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+
+			// 跳过空行和注释行
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			// 解析主机及行内选项
+			host, err := ParseTarget(line)
+			if err != nil {
+				logger.Debug("解析失败", "line", line, "error", err)
+				continue
+			}
+			if seen != nil {
+				if _, ok := seen[host]; ok {
+					duplicates++
+					continue
+				}
+				seen[host] = struct{}{}
+			}
+
+			// 如果是CIDR或IP区间，展开所有IP
+			switch host.Type {
+			case HostTypeCIDR:
+				if !expandCIDR(ctx, host, hostChan) {
+					return
+				}
+			case HostTypeRange:
+				if !expandRange(ctx, host, hostChan) {
+					return
+				}
+			default:
+				if !sendHost(ctx, hostChan, host) {
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			logger.Error("读取输入时出错", "error", err)
+		}
+	}()
+
+	return hostChan
+}
+
+// IterateHosts 依次发送给定的扫描目标，ctx取消时提前结束
+func IterateHosts(ctx context.Context, hosts []Host) <-chan Host {
+	hostChan := make(chan Host, 100)
+
+	go func() {
+		defer close(hostChan)
+		for _, host := range hosts {
+			if !sendHost(ctx, hostChan, host) {
+				return
+			}
+		}
+	}()
+
+	return hostChan
+}
+
+// sendHost 发送扫描目标，ctx已取消时放弃发送并返回false
+func sendHost(ctx context.Context, hostChan chan<- Host, host Host) bool {
+	select {
+	case hostChan <- host:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// expandCIDR 逐个发送CIDR所包含的IP地址（不预先展开，网段大小不受限制），展开的目标沿用target的端口等选项，ctx取消时返回false
+func expandCIDR(ctx context.Context, target Host, hostChan chan<- Host) bool {
+	cidr := target.Origin
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		logger.Error("解析CIDR失败", "cidr", cidr, "error", err)
+		return true
+	}
+	prefix = prefix.Masked()
+
+	// 遍历网络中的所有IP，越过网段末尾时Next返回的地址不再属于该网段
+	count := 0
+	for ip := prefix.Addr(); prefix.Contains(ip); ip = ip.Next() {
+		host := target
+		host.IP, host.Type = ip, HostTypeIP
+		if !sendHost(ctx, hostChan, host) {
+			return false
+		}
+		count++
+	}
+
+	logger.Debug("CIDR展开完成", "cidr", cidr, "count", count)
+	return true
+}
+
+// expandRange 逐个发送IP区间所包含的IP地址（不预先展开，区间大小不受限制），展开的目标沿用target的端口等选项，ctx取消时返回false
+func expandRange(ctx context.Context, target Host, hostChan chan<- Host) bool {
+	rng := target.Origin
+	start, end, err := ParseRange(rng)
+	if err != nil {
+		logger.Error("解析IP区间失败", "range", rng, "error", err)
+		return true
+	}
+
+	// 结束地址为地址空间末尾时Next返回无效地址
+	count := 0
+	for ip := start; ip.IsValid() && ip.Compare(end) <= 0; ip = ip.Next() {
+		host := target
+		host.IP, host.Type = ip, HostTypeIP
+		if !sendHost(ctx, hostChan, host) {
+			return false
+		}
+		count++
+	}
+
+	logger.Debug("IP区间展开完成", "range", rng, "count", count)
+	return true
+}
+
+// IterateRange 迭代IP区间中的所有IP地址，支持端口后缀，ctx取消时提前结束
+func IterateRange(ctx context.Context, rng string) <-chan Host {
+	hostChan := make(chan Host, 100)
+
+	go func() {
+		defer close(hostChan)
+		rng, port, err := SplitHostPort(rng)
+		if err != nil {
+			logger.Error("解析IP区间失败", "range", rng, "error", err)
+			return
+		}
+		expandRange(ctx, Host{Origin: rng, Port: port}, hostChan)
+	}()
+
+	return hostChan
+}
+
+// Expansion 无限扫描模式的扩展进度，记录从种子IP已向下和向上扩展的地址数，可在扫描过程中并发读取；
+// IPv6种子只使用high，记录已发送的IPv6Candidates候选地址数（含种子）
+type Expansion struct {
+	low, high atomic.Uint64
+}
+
+// NewExpansion 从已保存的进度创建扩展进度，继续扫描时从种子IP向下第low+1个、向上第high+1个地址开始
+func NewExpansion(low, high uint64) *Expansion {
+	e := &Expansion{}
+	e.low.Store(low)
+	e.high.Store(high)
+	return e
+}
+
+// Offsets 返回已向下和向上扩展的地址数
+func (e *Expansion) Offsets() (uint64, uint64) {
+	return e.low.Load(), e.high.Load()
+}
+
+// ExpandOptions 无限扫描模式的扩展方式，只作用于IPv4种子；零值表示逐个地址交替向下、向上扩展，直到地址空间边界
+type ExpandOptions struct {
+	Stride    int    // 相邻两个扫描地址的间隔，如4表示每4个地址扫描一个，0或1表示扫描每个地址
+	MaxRadius uint64 // 每个方向距种子IP的最大地址数，0表示不限制
+	Chunk     int    // 每轮在一个方向上连续扩展的地址块大小，块按大小对齐，如16表示每次扫描完一个/28再换方向；0或1表示逐个交替
+}
+
+// maxIPv4 IPv4地址空间的最大值
+const maxIPv4 = 1<<32 - 1
+
+// IterateAddr 无限扫描模式，从指定IP开始逐个交替向上下扩展，直到ctx取消或地址空间耗尽；
+// IPv6地址改为依次扫描IPv6Candidates，扫描完所在/64中的候选地址后结束
+func IterateAddr(ctx context.Context, addr string) <-chan Host {
+	return IterateAddrFrom(ctx, addr, nil, ExpandOptions{})
+}
+
+// IterateAddrFrom 与IterateAddr相同，但按opts扩展，从progress记录的位置继续并在扫描过程中更新progress，
+// progress为nil时从种子IP开始
+func IterateAddrFrom(ctx context.Context, addr string, progress *Expansion, opts ExpandOptions) <-chan Host {
+	hostChan := make(chan Host, 100)
+	if progress == nil {
+		progress = &Expansion{}
+	}
+
+	go func() {
+		defer close(hostChan)
+
+		// 解析初始IP和端口后缀
+		addr, port, err := SplitHostPort(addr)
+		if err != nil {
+			logger.Error("无效的IP地址", "ip", addr, "error", err)
+			return
+		}
+		initialIP, err := netip.ParseAddr(addr)
+		if err != nil {
+			logger.Error("无效的IP地址", "ip", addr)
+			return
+		}
+		initialIP = initialIP.Unmap()
+		if initialIP.Is6() {
+			iterateIPv6(ctx, hostChan, initialIP, addr, port, progress)
+			return
+		}
+
+		// 从头开始时发送初始IP
+		low, high := progress.Offsets()
+		if low == 0 && high == 0 {
+			if !sendHost(ctx, hostChan, Host{
+				IP:     initialIP,
+				Origin: addr,
+				Type:   HostTypeIP,
+				Port:   port,
+			}) {
+				return
+			}
+		} else {
+			logger.Info("从上次的进度继续扩展", "ip", addr, "low", low, "high", high)
+		}
+
+		send := func(ip netip.Addr) bool {
+			return sendHost(ctx, hostChan, Host{IP: ip, Origin: addr, Type: HostTypeIP, Port: port})
+		}
+		b := initialIP.As4()
+		seed := uint64(binary.BigEndian.Uint32(b[:]))
+		down := newExpander(seed, false, &progress.low, opts)
+		up := newExpander(seed, true, &progress.high, opts)
+
+		// 两个方向轮流扩展一个地址块，一个方向到达边界或最大半径后只扩展另一个方向
+		for !down.exhausted() || !up.exhausted() {
+			if !down.expand(ctx, send) || !up.expand(ctx, send) {
+				return
+			}
+		}
+		logger.Debug("无限扫描模式扩展完成", "ip", addr, "low", down.distance.Load(), "high", up.distance.Load())
+	}()
+
+	return hostChan
+}
+
+// expander 无限扫描模式中一个方向的扩展状态，地址以uint64计算，不会在地址空间边界回绕
+type expander struct {
+	seed     uint64         // 种子IP
+	up       bool           // 是否向上扩展
+	distance *atomic.Uint64 // 已扩展到的距离，即Expansion中对应方向的进度
+	limit    uint64         // 该方向可扩展的最大距离：到地址空间边界的距离和最大半径中较小者
+	stride   uint64         // 相邻两个扫描地址的间隔
+	chunk    uint64         // 地址块大小
+}
+
+// newExpander 创建一个方向的扩展状态
+func newExpander(seed uint64, up bool, distance *atomic.Uint64, opts ExpandOptions) *expander {
+	e := &expander{seed: seed, up: up, distance: distance, limit: seed, stride: 1, chunk: 1}
+	if up {
+		e.limit = maxIPv4 - seed
+	}
+	if opts.MaxRadius > 0 {
+		e.limit = min(e.limit, opts.MaxRadius)
+	}
+	if opts.Stride > 1 {
+		e.stride = uint64(opts.Stride)
+	}
+	if opts.Chunk > 1 {
+		e.chunk = uint64(opts.Chunk)
+	}
+	return e
+}
+
+// exhausted 返回该方向是否已扩展到边界
+func (e *expander) exhausted() bool {
+	return e.distance.Load()+e.stride > e.limit
+}
+
+// at 返回距种子IP指定距离的地址
+func (e *expander) at(distance uint64) uint64 {
+	if e.up {
+		return e.seed + distance
+	}
+	return e.seed - distance
+}
+
+// expand 发送当前地址块中剩余的地址，下一个地址进入另一个地址块或到达边界时结束；
+// 回环、多播等地址跳过不发送，发送成功或跳过后才计入进度，ctx取消时返回false
+func (e *expander) expand(ctx context.Context, send func(netip.Addr) bool) bool {
+	for !e.exhausted() {
+		if ctx.Err() != nil {
+			return false
+		}
+		distance := e.distance.Load() + e.stride
+		addr := e.at(distance)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(addr))
+		if ip := netip.AddrFrom4(b); isValidIP(ip) && !send(ip) {
+			return false
+		}
+		e.distance.Store(distance)
+
+		if distance+e.stride > e.limit || e.at(distance+e.stride)/e.chunk != addr/e.chunk {
+			break
+		}
+	}
+	return true
+}
+
+// iterateIPv6 从progress记录的位置依次发送种子地址的IPv6Candidates，发送成功后才计入进度
+func iterateIPv6(ctx context.Context, hostChan chan<- Host, seed netip.Addr, origin string, port int, progress *Expansion) {
+	candidates := IPv6Candidates(seed)
+	_, sent := progress.Offsets()
+	if sent > 0 {
+		logger.Info("从上次的进度继续扫描", "ip", origin, "sent", sent, "candidates", len(candidates))
+	}
+	for _, ip := range candidates[min(sent, uint64(len(candidates))):] {
+		if !sendHost(ctx, hostChan, Host{IP: ip, Origin: origin, Type: HostTypeIP, Port: port}) {
+			return
+		}
+		progress.high.Add(1)
+	}
+}
+
+// IterateCIDR 迭代CIDR网段中的所有IP地址，支持端口后缀，ctx取消时提前结束
+func IterateCIDR(ctx context.Context, cidr string) <-chan Host {
+	hostChan := make(chan Host, 100)
+
+	go func() {
+		defer close(hostChan)
+		cidr, port, err := SplitHostPort(cidr)
+		if err != nil {
+			logger.Error("解析CIDR失败", "cidr", cidr, "error", err)
+			return
+		}
+		expandCIDR(ctx, Host{Origin: cidr, Port: port}, hostChan)
+	}()
+
+	return hostChan
+}
+
+// addrOffset 返回addr之后（offset为负时之前）第offset个地址，越过地址空间边界时返回无效的零值
+func addrOffset(addr netip.Addr, offset *big.Int) netip.Addr {
+	sum := new(big.Int).Add(new(big.Int).SetBytes(addr.AsSlice()), offset)
+	buf := make([]byte, addr.BitLen()/8)
+	if sum.Sign() < 0 || sum.BitLen() > len(buf)*8 {
+		return netip.Addr{}
+	}
+	sum.FillBytes(buf)
+	result, _ := netip.AddrFromSlice(buf)
+	return result
+}
+
+// isValidIP 检查IP是否有效（避免广播地址、回环地址等）
+func isValidIP(ip netip.Addr) bool {
+	if !ip.IsValid() {
+		return false
+	}
+
+	// 跳过回环地址
+	if ip.IsLoopback() {
+		return false
+	}
+
+	// 跳过多播地址
+	if ip.IsMulticast() {
+		return false
+	}
+
+	// 跳过私有地址（可选）
+	// if ip.IsPrivate() {
+	//     return false
+	// }
+
+	return true
+}
+
+// FetchDomainsFromURL 从URL获取域名列表
+func (s *Scanner) FetchDomainsFromURL(ctx context.Context, url string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取URL内容失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应内容失败: %v", err)
+	}
+
+	// 使用正则表达式提取域名
+	re := regexp.MustCompile(`(http|https)://(.*?)[/"\s<>]+`)
+	matches := re.FindAllStringSubmatch(string(body), -1)
+
+	domains := make(map[string]bool) // 使用map去重
+	for _, match := range matches {
+		if len(match) >= 3 {
+			domain := strings.ToLower(strings.TrimSpace(match[2]))
+			if ValidateDomainName(domain) {
+				domains[domain] = true
+			}
+		}
+	}
+
+	// 转换为切片
+	result := make([]string, 0, len(domains))
+	for domain := range domains {
+		result = append(result, domain)
+	}
+
+	return result, nil
+}
+
+// ResolveDomain 解析域名为IP地址，解析结果（包括失败）会缓存一段时间
+func (s *Scanner) ResolveDomain(ctx context.Context, domain string) ([]netip.Addr, error) {
+	ips, err := s.dnsCache.lookup(ctx, domain, func(ctx context.Context, domain string) ([]netip.Addr, error) {
+		return s.cfg.Resolver.LookupNetIP(ctx, "ip", domain)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("域名解析失败: %v", err)
+	}
+
+	// 过滤IPv4或IPv6地址
+	var result []netip.Addr
+	for _, ip := range ips {
+		ip = ip.Unmap()
+		if s.cfg.IPv6 || ip.Is4() {
+			result = append(result, ip)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("没有找到有效的IP地址")
+	}
+
+	return result, nil
+}
+
+// IsPrivateIP 检查IP是否为私有地址（IPv4 RFC 1918 和 IPv6 ULA）
+func IsPrivateIP(ip netip.Addr) bool {
+	return ip.Unmap().IsPrivate()
+}
@@ -0,0 +1,269 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// DefaultTraceHops 路由跟踪的默认最大跳数
+const DefaultTraceHops = 30
+
+const (
+	traceHopTimeout = time.Second     // 每一跳等待应答的时间
+	traceDeadline   = 2 * time.Minute // 单个目标路由跟踪的总时限
+	traceWorkers    = 4               // 同时跟踪的目标数
+
+	traceProtoICMPv4 = 1  // ICMPv4协议号
+	traceProtoICMPv6 = 58 // ICMPv6协议号
+	ipv4HeaderMinLen = 20 // IPv4报头最小长度
+	ipv6HeaderLen    = 40 // IPv6固定报头长度
+	echoHeaderLen    = 8  // ICMP回显报文头长度
+)
+
+// ErrTracePermission 没有创建原始ICMP套接字的权限（需要root或CAP_NET_RAW）
+var ErrTracePermission = errors.New("路由跟踪需要root权限或CAP_NET_RAW")
+
+// TraceHop 路由跟踪中的一跳，Addr无效表示该跳没有应答
+type TraceHop struct {
+	TTL  int
+	Addr netip.Addr
+	RTT  time.Duration
+}
+
+// TracePath 到单个目标的路由跟踪结果
+type TracePath struct {
+	Target  netip.Addr
+	Domain  string // 目标的证书域名
+	Hops    []TraceHop
+	Reached bool // 是否收到了目标本身的应答
+	Err     error
+}
+
+// Traceroute 以逐跳递增TTL的ICMP回显请求跟踪到ip的路径，每跳发送一个探测，最多maxHops跳
+// 收到目标的回显应答或不可达报文时结束；使用原始套接字，没有权限时返回ErrTracePermission
+func Traceroute(ctx context.Context, ip netip.Addr, maxHops int) ([]TraceHop, bool, error) {
+	ip = ip.Unmap()
+	network, listenAddr, proto := "ip4:icmp", "0.0.0.0", traceProtoICMPv4
+	var reqType icmp.Type = ipv4.ICMPTypeEcho
+	if ip.Is6() {
+		network, listenAddr, proto = "ip6:ipv6-icmp", "::", traceProtoICMPv6
+		reqType = ipv6.ICMPTypeEchoRequest
+	}
+
+	conn, err := icmp.ListenPacket(network, listenAddr)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, false, ErrTracePermission
+		}
+		return nil, false, err
+	}
+	defer conn.Close()
+
+	// ctx取消时解除阻塞的读取
+	stop := context.AfterFunc(ctx, func() { conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	id := os.Getpid() & 0xffff
+	dst := &net.IPAddr{IP: ip.AsSlice()}
+	buf := make([]byte, 1500)
+	var hops []TraceHop
+
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		if ctx.Err() != nil {
+			return hops, false, ctx.Err()
+		}
+
+		if ip.Is6() {
+			err = conn.IPv6PacketConn().SetHopLimit(ttl)
+		} else {
+			err = conn.IPv4PacketConn().SetTTL(ttl)
+		}
+		if err != nil {
+			return hops, false, err
+		}
+
+		seq := int(icmpSeq.Add(1) & 0xffff)
+		request, err := (&icmp.Message{
+			Type: reqType,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("GetRealityDomain")},
+		}).Marshal(nil)
+		if err != nil {
+			return hops, false, err
+		}
+
+		start := time.Now()
+		if _, err := conn.WriteTo(request, dst); err != nil {
+			return hops, false, err
+		}
+		conn.SetReadDeadline(start.Add(traceHopTimeout))
+
+		hop := TraceHop{TTL: ttl}
+		final := false
+		for {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				break // 超时，该跳无应答
+			}
+			from, ok := netip.AddrFromSlice(addrIP(peer))
+			if !ok {
+				continue
+			}
+			matched, last := matchTraceReply(proto, buf[:n], id, seq)
+			if !matched {
+				continue
+			}
+			hop.Addr, hop.RTT = from.Unmap(), time.Since(start)
+			final = last
+			break
+		}
+		hops = append(hops, hop)
+
+		if final {
+			return hops, hop.Addr == ip, nil
+		}
+	}
+
+	return hops, false, nil
+}
+
+// matchTraceReply 判断ICMP报文是否是对本次探测（id、seq）的应答，
+// last表示报文来自路径终点（回显应答或不可达），此时应结束跟踪
+func matchTraceReply(proto int, packet []byte, id, seq int) (matched, last bool) {
+	msg, err := icmp.ParseMessage(proto, packet)
+	if err != nil {
+		return false, false
+	}
+
+	switch body := msg.Body.(type) {
+	case *icmp.Echo:
+		reply := msg.Type == ipv4.ICMPTypeEchoReply || msg.Type == ipv6.ICMPTypeEchoReply
+		return reply && body.ID == id && body.Seq == seq, true
+	case *icmp.TimeExceeded:
+		return quotedEcho(proto, body.Data, id, seq), false
+	case *icmp.DstUnreach:
+		return quotedEcho(proto, body.Data, id, seq), true
+	default:
+		return false, false
+	}
+}
+
+// quotedEcho 检查ICMP差错报文中引用的原始报文是否是本次探测的回显请求
+func quotedEcho(proto int, data []byte, id, seq int) bool {
+	offset := ipv6HeaderLen
+	if proto == traceProtoICMPv4 {
+		if len(data) < ipv4HeaderMinLen {
+			return false
+		}
+		offset = int(data[0]&0x0f) * 4
+	}
+	if len(data) < offset+echoHeaderLen {
+		return false
+	}
+	echo := data[offset:]
+	return int(binary.BigEndian.Uint16(echo[4:6])) == id && int(binary.BigEndian.Uint16(echo[6:8])) == seq
+}
+
+// PathTracer 收集符合条件的目标，扫描结束后跟踪到这些目标的路由，
+// 用于判断候选目标是否与本机共用拥塞的上游线路
+type PathTracer struct {
+	MaxTargets int // 最多跟踪的目标数，0表示不限制
+	MaxHops    int // 最大跳数，为0时使用DefaultTraceHops
+
+	mu      sync.Mutex
+	targets []TracePath
+	seen    map[netip.Addr]bool
+}
+
+// Watch 转发扫描结果并从中收集跟踪目标，返回的通道在results关闭后关闭
+func (t *PathTracer) Watch(results <-chan Result) <-chan Result {
+	out := make(chan Result, cap(results))
+	go func() {
+		defer close(out)
+		for result := range results {
+			t.Observe(result)
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Observe 检查单个结果，符合条件的目标记录为跟踪目标（同一IP只记录一次）
+func (t *PathTracer) Observe(result Result) {
+	if result.Error != "" || !result.Feasible {
+		return
+	}
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.MaxTargets > 0 && len(t.targets) >= t.MaxTargets {
+		return
+	}
+	if t.seen == nil {
+		t.seen = make(map[netip.Addr]bool)
+	}
+	if !t.seen[ip] {
+		t.seen[ip] = true
+		t.targets = append(t.targets, TracePath{Target: ip, Domain: result.CertDomain})
+	}
+}
+
+// Targets 返回已收集的跟踪目标数
+func (t *PathTracer) Targets() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.targets)
+}
+
+// Trace 并发跟踪所有目标的路由，返回的结果与收集顺序一致；
+// 没有原始套接字权限时直接返回ErrTracePermission，ctx取消时未完成的目标记录取消错误
+func (t *PathTracer) Trace(ctx context.Context) ([]TracePath, error) {
+	t.mu.Lock()
+	paths := append([]TracePath(nil), t.targets...)
+	t.mu.Unlock()
+
+	maxHops := t.MaxHops
+	if maxHops <= 0 {
+		maxHops = DefaultTraceHops
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(traceWorkers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				traceCtx, cancel := context.WithTimeout(ctx, traceDeadline)
+				paths[i].Hops, paths[i].Reached, paths[i].Err = Traceroute(traceCtx, paths[i].Target, maxHops)
+				cancel()
+			}
+		}()
+	}
+
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, path := range paths {
+		if errors.Is(path.Err, ErrTracePermission) {
+			return nil, ErrTracePermission
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// ttlTimeout 等待ICMP回显应答的时间
+const ttlTimeout = 2 * time.Second
+
+// icmpSeq ICMP回显请求的序号，区分并发探测的应答
+var icmpSeq atomic.Uint32
+
+// EstimateHops 根据收到的TTL估算到目标的跳数：发送端初始TTL通常为64（Linux）、128（Windows）或255（网络设备），
+// 取不小于观测值的最小初始值；ttl为0（未探测）时返回0
+func EstimateHops(ttl int) int {
+	for _, initial := range []int{64, 128, 255} {
+		if ttl > 0 && ttl <= initial {
+			return initial - ttl
+		}
+	}
+	return 0
+}
+
+// probeTTL 向目标发送ICMP回显请求，返回应答报文的IP TTL（IPv6为跳数限制）
+// 优先使用无需特权的ICMP套接字（需要系统允许，如Linux的net.ipv4.ping_group_range），失败时尝试原始套接字；
+// 目标不响应ICMP或没有权限时返回0
+func probeTTL(ctx context.Context, ip netip.Addr) int {
+	ip = ip.Unmap()
+	networks := []string{"udp4", "ip4:icmp"}
+	if ip.Is6() {
+		networks = []string{"udp6", "ip6:ipv6-icmp"}
+	}
+
+	for _, network := range networks {
+		ttl, err := echoTTL(ctx, network, ip)
+		if err == nil {
+			return ttl
+		}
+		if !os.IsPermission(err) {
+			logger.Debug("ICMP探测失败", "ip", ip, "network", network, "error", err)
+			return 0
+		}
+	}
+	return 0
+}
+
+// echoTTL 通过指定类型的ICMP套接字发送一次回显请求并读取应答的TTL
+func echoTTL(ctx context.Context, network string, ip netip.Addr) (int, error) {
+	listenAddr := "0.0.0.0"
+	var reqType icmp.Type = ipv4.ICMPTypeEcho
+	proto := 1 // ICMPv4
+	if ip.Is6() {
+		listenAddr = "::"
+		reqType = ipv6.ICMPTypeEchoRequest
+		proto = 58 // ICMPv6
+	}
+
+	conn, err := icmp.ListenPacket(network, listenAddr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if ip.Is6() {
+		err = conn.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+	} else {
+		err = conn.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	seq := int(icmpSeq.Add(1) & 0xffff)
+	id := os.Getpid() & 0xffff
+	request, err := (&icmp.Message{
+		Type: reqType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("GetRealityDomain")},
+	}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// 无特权套接字使用UDP地址，原始套接字使用IP地址
+	var dst net.Addr = &net.IPAddr{IP: ip.AsSlice()}
+	if network == "udp4" || network == "udp6" {
+		dst = &net.UDPAddr{IP: ip.AsSlice()}
+	}
+	if _, err := conn.WriteTo(request, dst); err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(ttlTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+
+	buf := make([]byte, 1500)
+	for {
+		var n, ttl int
+		var peer net.Addr
+		if ip.Is6() {
+			var cm *ipv6.ControlMessage
+			n, cm, peer, err = conn.IPv6PacketConn().ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.HopLimit
+			}
+		} else {
+			var cm *ipv4.ControlMessage
+			n, cm, peer, err = conn.IPv4PacketConn().ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.TTL
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		// 原始套接字会收到所有ICMP报文，只接受来自目标、序号匹配的回显应答
+		if peerIP, ok := netip.AddrFromSlice(addrIP(peer)); !ok || peerIP.Unmap() != ip {
+			continue
+		}
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (reply.Type != ipv4.ICMPTypeEchoReply && reply.Type != ipv6.ICMPTypeEchoReply) {
+			continue
+		}
+		return ttl, nil
+	}
+}
+
+// addrIP 返回套接字地址中的IP
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	default:
+		return nil
+	}
+}
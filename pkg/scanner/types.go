@@ -0,0 +1,195 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// HostType 定义主机类型常量
+type HostType int
+
+const (
+	HostTypeIP     HostType = 1 // 单个IP地址
+	HostTypeCIDR   HostType = 2 // IP段(CIDR格式)
+	HostTypeDomain HostType = 3 // 域名
+	HostTypeRange  HostType = 4 // IP区间(起始IP-结束IP)
+)
+
+// Host 结构体表示一个扫描目标
+type Host struct {
+	IP     netip.Addr // IP地址
+	Origin string     // 原始输入(IP/域名/CIDR/IP区间)
+	Type   HostType   // 主机类型(IP/CIDR/域名/IP区间)
+	Source string     // 目标来源（如抓取域名的页面URL），设置后结果的Origin记录来源而不是原始输入
+	Port   int        // 目标指定的端口（如 example.com:8443），非0时只扫描该端口，忽略全局端口列表
+
+	SNI     string // 目标指定的SNI（如 sni=www.example.com），设置后IP目标也使用该SNI握手；逗号分隔的多个SNI中第一个用于握手，其余作为候选验证
+	Country string // 期望的地理位置代码（如 country=JP），查询结果不符时记为失败
+	Region  string // 返回该IP的地区解析器，多个时逗号分隔，只在按地区解析域名时设置
+}
+
+// Result 表示扫描结果
+type Result struct {
+	IP           string    `json:"ip"`                   // IP地址
+	Origin       string    `json:"origin"`               // 原始输入
+	Port         int       `json:"port"`                 // 端口
+	CertDomain   string    `json:"cert_domain"`          // 证书域名
+	CertIssuer   string    `json:"cert_issuer"`          // 证书颁发者
+	CertSHA256   string    `json:"cert_sha256"`          // 叶子证书的SHA-256指纹（十六进制）
+	TLSVersion   string    `json:"tls_version"`          // TLS版本
+	ALPN         string    `json:"alpn"`                 // ALPN协商结果
+	Curve        string    `json:"curve"`                // 椭圆曲线算法
+	GeoCode      string    `json:"geo_code"`             // 地理位置代码
+	City         string    `json:"city,omitempty"`       // 城市
+	SNI          string    `json:"sni,omitempty"`        // 握手时发送的SNI，IP目标为空
+	SNIMatch     string    `json:"sni_match,omitempty"`  // 服务器出示了匹配证书的SNI，多个时逗号分隔
+	Middlebox    string    `json:"middlebox,omitempty"`  // 疑似中间设备干扰的类型（rst、sni、tls-timeout），未检测到时为空
+	MTU          string    `json:"mtu,omitempty"`        // 大尺寸ClientHello探测结果（ok、blackhole、reset、alert、error），未探测时为空
+	ASN          uint      `json:"asn,omitempty"`        // 自治系统编号
+	ASOrg        string    `json:"as_org,omitempty"`     // 自治系统所属组织
+	Feasible     bool      `json:"feasible"`             // 是否符合Reality要求
+	ResponseTime int64     `json:"response_time_ms"`     // 响应时间(毫秒)，包括TCP连接和TLS握手
+	TCPRTT       int64     `json:"tcp_rtt_ms,omitempty"` // TCP连接延迟(毫秒)，不含服务器处理TLS握手的时间
+	TTL          int       `json:"ttl,omitempty"`        // ICMP回显应答的IP TTL（IPv6为跳数限制），未探测或无应答时为0
+	Hops         int       `json:"hops,omitempty"`       // 根据TTL估算的跳数
+	Region       string    `json:"region,omitempty"`     // 返回该IP的地区解析器，多个时逗号分隔，未按地区解析时为空
+	ErrorKind    ErrorKind `json:"error_kind,omitempty"` // 错误类别
+	Error        string    `json:"error,omitempty"`      // 错误详情
+
+	Vantages       []VantageResult `json:"vantages,omitempty"`         // 从各观测点重新握手的结果，未配置观测点时为空
+	PrevCertSHA256 string          `json:"prev_cert_sha256,omitempty"` // 证书自上次扫描后发生变化时，上次记录的指纹
+	Suspicious     string          `json:"suspicious,omitempty"`       // 疑似蜜罐或sinkhole的疑点，多个时逗号分隔
+	Confidence     string          `json:"confidence,omitempty"`       // 可信度等级（A/B/C），由通过的独立检查数决定，未评级时为空
+	HelloProfile   string          `json:"hello_profile,omitempty"`    // 自定义ClientHello探测结果（ok、hrr、tls1.2、alert:<描述>、reset、timeout、error），未探测时为空
+	Addr           netip.Addr      `json:"-"`                          // IP的解析形式，供统计按网段分组，不输出；没有IP的结果为零值
+}
+
+// String 返回HostType的字符串表示
+func (ht HostType) String() string {
+	switch ht {
+	case HostTypeIP:
+		return "IP"
+	case HostTypeCIDR:
+		return "CIDR"
+	case HostTypeDomain:
+		return "DOMAIN"
+	case HostTypeRange:
+		return "RANGE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// resultOrigin 返回结果中记录的来源
+func (h Host) resultOrigin() string {
+	if h.Source != "" {
+		return h.Source
+	}
+	return h.Origin
+}
+
+// serverName 返回握手时使用的SNI，优先使用目标指定的SNI，原始输入不是域名时返回空字符串
+func (h Host) serverName() string {
+	if h.SNI != "" {
+		sni, _, _ := strings.Cut(h.SNI, ",")
+		return sni
+	}
+	if _, err := netip.ParseAddr(h.Origin); err == nil {
+		return ""
+	}
+	if !ValidateDomainName(h.Origin) {
+		return ""
+	}
+	return h.Origin
+}
+
+// sniCandidates 返回目标指定的除握手SNI以外的候选SNI
+func (h Host) sniCandidates() []string {
+	_, rest, ok := strings.Cut(h.SNI, ",")
+	if !ok {
+		return nil
+	}
+	return strings.Split(rest, ",")
+}
+
+// String 返回Host的字符串表示
+func (h Host) String() string {
+	return h.Origin + " (" + h.Type.String() + ")"
+}
+
+// Config 扫描配置
+type Config struct {
+	Port    int   // 扫描端口，Ports为空时使用
+	Ports   []int // 每个目标依次扫描的端口列表，为空时只扫描Port
+	Thread  int   // 并发线程数
+	Timeout int   // 连接超时时间(秒)
+	IPv6    bool  // 是否支持IPv6
+
+	// DiscoverPorts 端口发现：扫描端口未完成TLS 1.3握手时依次尝试的备用端口，
+	// 结果记录第一个握手成功的端口，为空时不尝试
+	DiscoverPorts []int
+
+	// VerifySNI 多SNI验证：IP目标完成TLS 1.3握手后，以证书中的域名和PTR记录作为候选SNI重新握手，
+	// 记录服务器出示了匹配证书的SNI；目标自带多个SNI时总是验证
+	VerifySNI bool
+
+	// TCPPings 完成TLS 1.3握手后追加的TCP连接次数，大于0时TCPRTT记录所有连接延迟的中位数
+	TCPPings int
+
+	// ProbeMTU 完成TLS 1.3握手后再用约4KB的ClientHello握手一次，检测路径上的PMTU黑洞和破坏大报文的中间设备
+	ProbeMTU bool
+
+	// HelloProfile 完成TLS 1.3握手后以自定义的ClientHello（椭圆曲线、签名算法、扩展顺序等）再连接一次，
+	// 在Result.HelloProfile中记录服务器的响应，为nil时不探测
+	HelloProfile *HelloProfile
+
+	// DetectInterference 握手在连接建立后被重置、关闭或超时时重新探测，符合中间设备干扰特征的失败
+	// 记为ErrorInterference并在Middlebox中记录干扰类型，以区分被阻断的路径和不可用的主机
+	DetectInterference bool
+
+	// ProbeTTL 完成TLS 1.3握手后向目标发送ICMP回显请求，记录应答的TTL并估算跳数，
+	// 需要系统允许无特权ICMP套接字或以root运行，目标不响应ICMP时不记录
+	ProbeTTL bool
+
+	// ResolveWorkers 域名预解析的并发数：大于0时在握手前由独立的协程池提前解析域名目标，
+	// 扫描线程从缓存中取得解析结果，为0时由扫描线程在握手前各自解析
+	ResolveWorkers int
+
+	// Regions 按地区解析域名：设置后域名目标由每个地区的解析器分别解析（而不是Resolver），
+	// 扫描所有地区返回的IP的并集，结果的Region记录返回该IP的地区，用于发现GeoDNS在不同地区返回的节点
+	Regions []RegionResolver
+
+	// Stealth 低调扫描的限速规则，为nil时不限速
+	Stealth *Stealth
+
+	// Capture 握手抓包，为nil时不抓包
+	Capture *Capture
+
+	// Exclude 排除名单，域名解析得到的地址在其中时不扫描；IP目标应在握手前用Exclusions.Filter跳过
+	Exclude *Exclusions
+
+	// Judge 判断握手成功的结果是否符合Reality要求，为nil时所有结果均视为不符合
+	// 通常设置为 feasibility.Checker 的 Feasible 方法
+	Judge func(context.Context, Result) bool
+
+	Dialer     Dialer     // TCP拨号器，超时由Timeout通过ctx控制
+	Resolver   Resolver   // 域名解析器
+	HTTPClient HTTPClient // 辅助请求（如抓取域名列表）使用的HTTP客户端
+}
+
+// DefaultConfig 返回默认扫描配置
+func DefaultConfig() *Config {
+	return &Config{
+		Port:    443,
+		Thread:  20,
+		Timeout: 10,
+		IPv6:    false,
+
+		Dialer:     &net.Dialer{},
+		Resolver:   net.DefaultResolver,
+		HTTPClient: http.DefaultClient,
+	}
+}
@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// VantageResult 从某个观测点重新握手的结果
+type VantageResult struct {
+	Name         string `json:"name"`             // 观测点名称
+	Feasible     bool   `json:"feasible"`         // 从该观测点是否符合条件
+	ResponseTime int64  `json:"response_time_ms"` // 观测点到目标的响应时间(毫秒)，已扣除隧道本身的延迟
+	Error        string `json:"error,omitempty"`  // 错误详情
+}
+
+// Vantage 观测点：通过其拨号的连接从远程主机发出（如SSH动态转发），
+// 同一个目标从不同地点连接的延迟和可用性可能相差很大
+type Vantage struct {
+	Name     string
+	Scanner  *Scanner      // 通过观测点拨号的扫描器，判断条件与本地扫描一致
+	Overhead time.Duration // 隧道本身的往返延迟，握手经过两次往返（TCP连接和TLS握手），从响应时间中扣除两次
+}
+
+// VantageProber 对本地符合条件的结果逐一从各观测点重新握手，记录每个观测点的可行性和延迟；
+// 设置了共识规则时，不满足规则的结果改为不符合条件，不会写入结果文件。
+// 由多个协程并发进行，转发顺序与输入不完全一致
+type VantageProber struct {
+	Vantages []Vantage
+	Workers  int // 并发重新握手的协程数，0表示1
+
+	MinFeasible int   // 至少有多少个观测点符合条件（不含本机），0表示不要求
+	MaxSpread   int64 // 本机和符合条件的观测点之间响应时间的最大差值(毫秒)，0表示不限制
+
+	rejected atomic.Int64
+}
+
+// Probe 转发扫描结果，符合条件的结果附加各观测点的测试结果，返回的通道在results关闭后关闭
+func (p *VantageProber) Probe(ctx context.Context, results <-chan Result) <-chan Result {
+	out := make(chan Result, cap(results))
+	workers := max(p.Workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for result := range results {
+				if result.Error == "" && result.Feasible && ctx.Err() == nil {
+					result.Vantages = p.probeAll(ctx, result)
+					if reason := p.consensus(result); reason != "" {
+						result.Feasible = false
+						p.rejected.Add(1)
+						logger.Debug("未通过观测点共识", "ip", result.IP, "port", result.Port, "reason", reason)
+					}
+				}
+				out <- result
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Rejected 返回因未通过共识规则而改为不符合条件的结果数
+func (p *VantageProber) Rejected() int64 {
+	return p.rejected.Load()
+}
+
+// consensus 检查观测点结果是否满足共识规则，不满足时返回原因
+func (p *VantageProber) consensus(result Result) string {
+	feasible := 0
+	fastest, slowest := result.ResponseTime, result.ResponseTime
+	for _, vantage := range result.Vantages {
+		if !vantage.Feasible {
+			continue
+		}
+		feasible++
+		fastest = min(fastest, vantage.ResponseTime)
+		slowest = max(slowest, vantage.ResponseTime)
+	}
+	if feasible < p.MinFeasible {
+		return fmt.Sprintf("只有 %d/%d 个观测点符合条件", feasible, len(result.Vantages))
+	}
+	if p.MaxSpread > 0 && slowest-fastest > p.MaxSpread {
+		return fmt.Sprintf("各观测点响应时间相差 %dms", slowest-fastest)
+	}
+	return ""
+}
+
+// probeAll 从每个观测点测试结果对应的目标
+func (p *VantageProber) probeAll(ctx context.Context, result Result) []VantageResult {
+	vantages := make([]VantageResult, len(p.Vantages))
+	var wg sync.WaitGroup
+	for i, vantage := range p.Vantages {
+		wg.Add(1)
+		go func(i int, vantage Vantage) {
+			defer wg.Done()
+			vantages[i] = vantage.probe(ctx, result)
+		}(i, vantage)
+	}
+	wg.Wait()
+	return vantages
+}
+
+// probe 通过观测点重新握手，只测试结果中的端口和SNI
+func (v Vantage) probe(ctx context.Context, result Result) VantageResult {
+	vantage := VantageResult{Name: v.Name}
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		vantage.Error = fmt.Sprintf("无效的IP地址: %v", err)
+		return vantage
+	}
+
+	host := Host{IP: ip, Origin: result.Origin, Type: HostTypeIP, Port: result.Port, SNI: result.SNI}
+	results := make(chan Result, 1)
+	go func() {
+		defer close(results)
+		v.Scanner.ScanTLS(ctx, host, results)
+	}()
+	remote, ok := <-results
+	if !ok {
+		vantage.Error = "已取消"
+		return vantage
+	}
+	for range results {
+	}
+
+	vantage.Feasible = remote.Error == "" && remote.Feasible
+	vantage.Error = remote.Error
+	if remote.Error == "" {
+		vantage.ResponseTime = max(remote.ResponseTime-2*v.Overhead.Milliseconds(), 0)
+	}
+	return vantage
+}
+
+// FormatVantages 将观测点结果格式化为单列文本，如 "tokyo=85ms;la=失败"
+func FormatVantages(vantages []VantageResult) string {
+	parts := make([]string, len(vantages))
+	for i, vantage := range vantages {
+		status := "失败"
+		switch {
+		case vantage.Feasible:
+			status = strconv.FormatInt(vantage.ResponseTime, 10) + "ms"
+		case vantage.Error == "":
+			status = "不符合"
+		}
+		parts[i] = vantage.Name + "=" + status
+	}
+	return strings.Join(parts, ";")
+}